@@ -12,6 +12,7 @@ from intentc.build.agents import (
     PromptTemplates,
     ValidationResponse,
     create_from_profile,
+    register_provider,
 )
 from intentc.build.state import (
     BuildResult,
@@ -26,17 +27,22 @@ from intentc.build.storage import (
     StorageBackend,
 )
 from intentc.build.validations import (
+    AgentBudget,
     AgentValidationRunner,
+    FileCheckRunner,
+    FolderCheckRunner,
     ValidationContext,
     ValidationRunner,
     ValidationSuite,
     ValidationSuiteResult,
+    WebCheckRunner,
 )
 
 __all__ = [
     "Agent",
     "AgentError",
     "AgentProfile",
+    "AgentBudget",
     "BuildContext",
     "BuildResponse",
     "BuildResult",
@@ -52,10 +58,14 @@ __all__ = [
     "TargetStatus",
     "ValidationResponse",
     "AgentValidationRunner",
+    "FileCheckRunner",
+    "FolderCheckRunner",
     "ValidationContext",
     "ValidationRunner",
     "ValidationSuite",
     "ValidationSuiteResult",
     "VersionControl",
+    "WebCheckRunner",
     "create_from_profile",
+    "register_provider",
 ]
@@ -3,6 +3,8 @@
 from intentc.build.agents.agents import (
     Agent,
     AgentError,
+    AgentConstructor,
+    AgentFactory,
     AgentProfile,
     BuildContext,
     BuildResponse,
@@ -13,18 +15,27 @@ from intentc.build.agents.agents import (
     DimensionResult,
     LogFn,
     MockAgent,
+    NoCapableAgentError,
     PromptTemplates,
+    UnknownAgentError,
+    UnknownProfileError,
     ValidationResponse,
+    check_prompt_template,
     create_from_profile,
     load_default_prompts,
+    register_provider,
+    registered_providers,
     render_differencing_prompt,
     render_init_prompt,
     render_prompt,
 )
+from intentc.build.agents.ratelimit import RateLimiter
 
 __all__ = [
     "Agent",
     "AgentError",
+    "AgentConstructor",
+    "AgentFactory",
     "AgentProfile",
     "BuildContext",
     "BuildResponse",
@@ -35,10 +46,17 @@ __all__ = [
     "DimensionResult",
     "LogFn",
     "MockAgent",
+    "NoCapableAgentError",
     "PromptTemplates",
+    "RateLimiter",
+    "UnknownAgentError",
+    "UnknownProfileError",
     "ValidationResponse",
+    "check_prompt_template",
     "create_from_profile",
     "load_default_prompts",
+    "register_provider",
+    "registered_providers",
     "render_differencing_prompt",
     "render_init_prompt",
     "render_prompt",
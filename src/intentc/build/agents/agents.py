@@ -6,19 +6,26 @@ import abc
 import importlib.resources
 import json
 import os
+import shlex
+import string
 import subprocess
 import tempfile
+import threading
 from pathlib import Path
 from typing import Callable
 
-from pydantic import BaseModel, Field
+from pydantic import BaseModel, ConfigDict, Field, field_validator
 
+from intentc.build.agents.ratelimit import RateLimiter
+from intentc.build.redact import redact
+from intentc.build.writer import DiskWriter, FileWriter
 from intentc.core.models import (
     Implementation,
     IntentFile,
     ProjectIntent,
     ValidationFile,
 )
+from intentc.core.parser import remove_validations_section
 
 # ---------------------------------------------------------------------------
 # Type aliases
@@ -36,6 +43,39 @@ class AgentError(Exception):
     """Raised when an agent invocation fails."""
 
 
+class UnknownAgentError(AgentError):
+    """Raised when a profile names a provider with no agent implementation."""
+
+    def __init__(self, provider: str) -> None:
+        supported = ", ".join(repr(name) for name in registered_providers())
+        super().__init__(
+            f"Unknown agent provider: {provider!r}. "
+            f"Supported providers: {supported}"
+        )
+        self.provider = provider
+
+
+class NoCapableAgentError(AgentError):
+    """Raised when no configured profile has the capabilities a target requires."""
+
+    def __init__(self, target: str, capabilities: list[str]) -> None:
+        super().__init__(
+            f"No agent profile can build '{target}': it requires capabilities "
+            f"{', '.join(capabilities)}"
+        )
+        self.target = target
+        self.capabilities = capabilities
+
+
+class UnknownProfileError(AgentError):
+    """Raised when an intent names an agent profile that is not configured."""
+
+    def __init__(self, target: str, profile: str) -> None:
+        super().__init__(f"Target '{target}' names unknown agent profile {profile!r}")
+        self.target = target
+        self.profile = profile
+
+
 # ---------------------------------------------------------------------------
 # Prompt templates
 # ---------------------------------------------------------------------------
@@ -84,14 +124,93 @@ def load_default_prompts() -> PromptTemplates:
     return PromptTemplates(**templates)
 
 
+_BUILD_PLACEHOLDERS = frozenset({
+    "project", "implementation", "feature", "validations", "validation",
+    "response_file", "previous_errors", "seed_prompt", "context_files",
+})
+
+# Placeholders each PromptTemplates field is rendered with
+PROMPT_PLACEHOLDERS: dict[str, frozenset[str]] = {
+    "build": _BUILD_PLACEHOLDERS,
+    "validate_template": _BUILD_PLACEHOLDERS,
+    "plan": _BUILD_PLACEHOLDERS,
+    "difference": frozenset({
+        "project", "implementation", "output_dir_a", "output_dir_b", "response_file",
+    }),
+    "init": frozenset({"project_name", "specifications", "user_prompt"}),
+}
+
+
+def check_prompt_template(field: str, template: str) -> None:
+    """Raise ValueError if *template* would not render as the *field* prompt.
+
+    Catches malformed braces and placeholders the prompt is not rendered
+    with, which would otherwise only fail once an agent runs.
+    """
+    allowed = PROMPT_PLACEHOLDERS[field]
+    try:
+        parsed = list(string.Formatter().parse(template))
+    except ValueError as exc:
+        raise ValueError(f"malformed template: {exc}") from exc
+    for _text, name, _spec, _conversion in parsed:
+        if name is None:
+            continue
+        root = name.split(".")[0].split("[")[0]
+        if root not in allowed:
+            raise ValueError(
+                f"unknown placeholder {{{name}}}; expected one of "
+                f"{', '.join(sorted(allowed))}"
+            )
+
+
 def render_prompt(
     template: str,
     ctx: BuildContext,
+    max_chars: int = 0,
+    log: LogFn | None = None,
 ) -> str:
-    """Render a build/validate/plan prompt template with BuildContext values."""
+    """Render a build/validate/plan prompt template with BuildContext values.
+
+    With *max_chars* > 0, a prompt over budget is trimmed instead of being
+    sent as is: context files are dropped (last listed first), then the
+    validation details are reduced to their names. Each trim is logged as a
+    warning; a prompt that is still too long is sent anyway.
+    """
+    log = log or (lambda _msg: None)
     validations_text = "\n\n".join(
         v.model_dump_json(indent=2) for v in ctx.validations
     )
+    prompt = _format_prompt(template, ctx, ctx.context_files, validations_text)
+    if max_chars <= 0 or len(prompt) <= max_chars:
+        return prompt
+
+    context_files = dict(ctx.context_files)
+    while context_files and len(prompt) > max_chars:
+        path = list(context_files)[-1]
+        del context_files[path]
+        log(f"    agent: warning: prompt over {max_chars} chars, dropped context file '{path}'")
+        prompt = _format_prompt(template, ctx, context_files, validations_text)
+
+    if len(prompt) > max_chars and ctx.validations:
+        validations_text = "\n".join(
+            f"- {v.name} ({v.type.value}, {v.severity.value})"
+            for vf in ctx.validations
+            for v in vf.validations
+        )
+        log(f"    agent: warning: prompt over {max_chars} chars, listing validations by name only")
+        prompt = _format_prompt(template, ctx, context_files, validations_text)
+
+    if len(prompt) > max_chars:
+        log(f"    agent: warning: prompt is {len(prompt)} chars after trimming, over the {max_chars} budget")
+    return prompt
+
+
+def _format_prompt(
+    template: str,
+    ctx: BuildContext,
+    context_files: dict[str, str],
+    validations_text: str,
+) -> str:
     previous_errors_text = ""
     if ctx.previous_errors:
         bullets = "\n".join(f"- {e}" for e in ctx.previous_errors)
@@ -99,16 +218,29 @@ def render_prompt(
             f"\n### Previous Errors\nThe following errors occurred in prior attempts. "
             f"Fix these issues:\n{bullets}\n"
         )
+    context_files_text = ""
+    if context_files:
+        sections = "\n\n".join(
+            f"#### {path}\n```\n{content}\n```"
+            for path, content in context_files.items()
+        )
+        context_files_text = (
+            f"\n### Reference Material\nThe intent references these files. "
+            f"Use them as context; do not copy them into the output unless asked.\n\n"
+            f"{sections}\n"
+        )
 
     return template.format(
         project=ctx.project_intent.body if ctx.project_intent else "",
         implementation=ctx.implementation.body if ctx.implementation else "",
-        feature=ctx.intent.body if ctx.intent else "",
+        # Inline validations reach the agent through {validations} instead
+        feature=remove_validations_section(ctx.intent.body) if ctx.intent else "",
         validations=validations_text,
         validation=validations_text,
         response_file=ctx.response_file_path,
         previous_errors=previous_errors_text,
         seed_prompt=ctx.seed_prompt,
+        context_files=context_files_text,
     )
 
 
@@ -161,6 +293,7 @@ Optional fields:
 - `depends_on` — list of feature directory paths this feature depends on
 - `tags` — list of string tags
 - `authors` — list of author identifiers
+- `context` — list of reference files (relative to the .ic file) whose contents are given to the agent
 
 For `project.ic`, there is no `depends_on` field. For `implementations/*.ic`, `depends_on` is optional.
 
@@ -226,13 +359,34 @@ class AgentProfile(BaseModel):
     provider: str  # "claude", "codex", or "cli"
     command: str = ""
     cli_args: list[str] = Field(default_factory=list)
+    # Run the cli provider's command through /bin/sh (for pipes and
+    # redirects) instead of executing it directly
+    shell: bool = False
     timeout: float = 3600.0
     retries: int = 3
+    # Wait before a retry: retry_delay seconds, multiplied by backoff_multiplier
+    # for each further retry, never more than max_backoff (0 means no cap)
+    retry_delay: float = 0.0
+    backoff_multiplier: float = 1.0
+    max_backoff: float = 300.0
     model_id: str | None = None
     effort: str | None = None  # Claude-specific: "low", "medium", "high", "max"
     prompt_templates: PromptTemplates | None = None
     sandbox_write_paths: list[str] = Field(default_factory=list)
     sandbox_read_paths: list[str] = Field(default_factory=list)
+    max_prompt_chars: int = 0  # Trim prompts longer than this; 0 disables the budget
+    # What the agent can do beyond writing code (web, decompile, ...); intents
+    # list what they need in ``requires_capabilities``
+    capabilities: list[str] = Field(default_factory=list)
+
+    def backoff(self, retry: int) -> float:
+        """Seconds to wait before the *retry*-th retry (1 for the first)."""
+        delay = self.retry_delay * self.backoff_multiplier ** max(retry - 1, 0)
+        return min(delay, self.max_backoff) if self.max_backoff > 0 else delay
+
+    def has_capabilities(self, required: list[str]) -> bool:
+        """True if the profile declares every capability in *required*."""
+        return set(required) <= set(self.capabilities)
 
 
 # ---------------------------------------------------------------------------
@@ -243,6 +397,8 @@ class AgentProfile(BaseModel):
 class BuildContext(BaseModel):
     """Everything the agent needs to act on a target."""
 
+    model_config = ConfigDict(arbitrary_types_allowed=True)
+
     intent: IntentFile
     validations: list[ValidationFile] = Field(default_factory=list)
     output_dir: str
@@ -253,6 +409,12 @@ class BuildContext(BaseModel):
     response_file_path: str
     previous_errors: list[str] = Field(default_factory=list)
     seed_prompt: str = ""
+    # Reference material from the intent's ``context`` list, path -> content
+    context_files: dict[str, str] = Field(default_factory=dict)
+    # Where to append the agent's raw output for auditing ("" disables)
+    output_file_path: str = ""
+    # Where generated files go, for agents that write through it (see Agent.uses_file_writer)
+    file_writer: FileWriter | None = Field(default=None, exclude=True)
 
 
 class DifferencingContext(BaseModel):
@@ -277,6 +439,16 @@ class BuildResponse(BaseModel):
     summary: str
     files_created: list[str] = Field(default_factory=list)
     files_modified: list[str] = Field(default_factory=list)
+    # Values dependents interpolate as {{dep.<feature>.outputs.<key>}}
+    outputs: dict[str, str] = Field(default_factory=dict)
+
+    @field_validator("outputs", mode="before")
+    @classmethod
+    def _outputs_as_text(cls, value: object) -> object:
+        """Agents often write numbers or booleans; keep them as their JSON text."""
+        if not isinstance(value, dict):
+            return value
+        return {k: v if isinstance(v, str) else json.dumps(v) for k, v in value.items()}
 
 
 class ValidationResponse(BaseModel):
@@ -285,6 +457,8 @@ class ValidationResponse(BaseModel):
     name: str
     status: str  # "pass" or "fail"
     reason: str
+    confidence: float | None = None  # 0-1, as reported by the agent
+    duration_secs: float | None = None  # Filled in by the validation suite
 
 
 class DimensionResult(BaseModel):
@@ -311,6 +485,10 @@ class DifferencingResponse(BaseModel):
 class Agent(abc.ABC):
     """Abstract agent interface. All agents implement these methods."""
 
+    # True if build() writes only through ctx.file_writer. Other agents write to
+    # disk themselves, and the Builder detects their changes from snapshots.
+    uses_file_writer: bool = False
+
     @abc.abstractmethod
     def build(self, ctx: BuildContext) -> BuildResponse: ...
 
@@ -333,6 +511,27 @@ class Agent(abc.ABC):
     def get_type(self) -> str: ...
 
 
+def _wait_for_turn(limiter: RateLimiter | None, log: LogFn) -> None:
+    """Block until *limiter* (if any) allows another agent request."""
+    if limiter is None:
+        return
+    waited = limiter.acquire()
+    if waited > 0:
+        log(f"    agent: rate limited, waited {waited:.1f}s")
+
+
+def _append_output(path: str, text: str) -> None:
+    """Append agent output to *path*, secrets redacted; retries accumulate in one file."""
+    if not path:
+        return
+    text = redact(text)
+    Path(path).parent.mkdir(parents=True, exist_ok=True)
+    with open(path, "a", encoding="utf-8") as f:
+        f.write(text)
+        if text and not text.endswith("\n"):
+            f.write("\n")
+
+
 # ---------------------------------------------------------------------------
 # CLIAgent
 # ---------------------------------------------------------------------------
@@ -345,10 +544,12 @@ class CLIAgent(Agent):
         self,
         profile: AgentProfile,
         log: LogFn | None = None,
+        limiter: RateLimiter | None = None,
     ) -> None:
         self._profile = profile
         self._log = log or (lambda _msg: None)
         self._templates = profile.prompt_templates or load_default_prompts()
+        self._limiter = limiter
 
     def get_name(self) -> str:
         return self._profile.name
@@ -357,12 +558,17 @@ class CLIAgent(Agent):
         return "cli"
 
     def build(self, ctx: BuildContext) -> BuildResponse:
-        prompt = render_prompt(self._templates.build, ctx)
-        self._run_command(prompt, ctx.response_file_path, timeout=self._profile.timeout)
+        prompt = self._render(self._templates.build, ctx)
+        self._run_command(
+            prompt,
+            ctx.response_file_path,
+            timeout=self._profile.timeout,
+            output_file_path=ctx.output_file_path,
+        )
         return self._read_build_response(ctx.response_file_path)
 
     def validate(self, ctx: BuildContext, validation: ValidationFile) -> ValidationResponse:
-        prompt = render_prompt(self._templates.validate_template, ctx)
+        prompt = self._render(self._templates.validate_template, ctx)
         self._run_command(prompt, ctx.response_file_path, timeout=self._profile.timeout)
         return self._read_validation_response(ctx.response_file_path)
 
@@ -372,29 +578,49 @@ class CLIAgent(Agent):
         return self._read_differencing_response(ctx.response_file_path)
 
     def plan(self, ctx: BuildContext) -> None:
-        prompt = render_prompt(self._templates.plan, ctx)
+        prompt = self._render(self._templates.plan, ctx)
         self._run_command(prompt, ctx.response_file_path, timeout=self._profile.timeout)
 
     def init(self, project_name: str, intent_dir: str, prompt: str | None = None) -> None:
         rendered = render_init_prompt(self._templates.init, project_name, prompt)
         self._run_command(rendered, "", timeout=self._profile.timeout)
 
+    def _render(self, template: str, ctx: BuildContext) -> str:
+        return render_prompt(
+            template, ctx, max_chars=self._profile.max_prompt_chars, log=self._log
+        )
+
     def _run_command(
         self,
         prompt: str,
         response_file_path: str,
         timeout: float,
+        output_file_path: str = "",
     ) -> None:
         command = self._profile.command
-        if not command:
+        if not command.strip():
             raise AgentError("CLIAgent requires a command in the profile")
 
-        cmd = command.split() + self._profile.cli_args
-        self._log(f"    agent: running {cmd[0]}")
+        # The prompt always goes over stdin, never into the command line. By
+        # default the command is split like a shell would, so quoted arguments
+        # keep their spaces, but no shell runs; ``shell`` opts into one.
+        cmd: str | list[str]
+        if self._profile.shell:
+            cmd = " ".join([command, *map(shlex.quote, self._profile.cli_args)])
+            program = "sh"
+        else:
+            try:
+                cmd = shlex.split(command) + self._profile.cli_args
+            except ValueError as exc:
+                raise AgentError(f"Cannot parse agent command: {command}: {exc}") from exc
+            program = cmd[0]
+        _wait_for_turn(self._limiter, self._log)
+        self._log(f"    agent: running {program} with {len(prompt)} char prompt")
 
         try:
             result = subprocess.run(
                 cmd,
+                shell=self._profile.shell,
                 input=prompt,
                 capture_output=True,
                 text=True,
@@ -407,6 +633,8 @@ class CLIAgent(Agent):
         except OSError as exc:
             raise AgentError(f"Failed to run agent command: {command}: {exc}") from exc
 
+        _append_output(output_file_path, result.stdout + result.stderr)
+
         if result.returncode != 0:
             raise AgentError(
                 f"Agent command failed (exit {result.returncode}): {result.stderr or result.stdout}"
@@ -445,10 +673,12 @@ class ClaudeAgent(Agent):
         self,
         profile: AgentProfile,
         log: LogFn | None = None,
+        limiter: RateLimiter | None = None,
     ) -> None:
         self._profile = profile
         self._log = log or (lambda _msg: None)
         self._templates = profile.prompt_templates or load_default_prompts()
+        self._limiter = limiter
 
     def get_name(self) -> str:
         return self._profile.name
@@ -457,12 +687,14 @@ class ClaudeAgent(Agent):
         return "claude"
 
     def build(self, ctx: BuildContext) -> BuildResponse:
-        prompt = render_prompt(self._templates.build, ctx)
-        self._run_non_interactive(prompt, ctx.output_dir, ctx.response_file_path)
+        prompt = self._render(self._templates.build, ctx)
+        self._run_non_interactive(
+            prompt, ctx.output_dir, ctx.response_file_path, ctx.output_file_path
+        )
         return self._read_build_response(ctx.response_file_path, ctx.output_dir)
 
     def validate(self, ctx: BuildContext, validation: ValidationFile) -> ValidationResponse:
-        prompt = render_prompt(self._templates.validate_template, ctx)
+        prompt = self._render(self._templates.validate_template, ctx)
         self._run_non_interactive(prompt, ctx.output_dir, ctx.response_file_path)
         return self._read_validation_response(ctx.response_file_path)
 
@@ -472,7 +704,7 @@ class ClaudeAgent(Agent):
         return self._read_differencing_response(ctx.response_file_path)
 
     def plan(self, ctx: BuildContext) -> None:
-        prompt = render_prompt(self._templates.plan, ctx)
+        prompt = self._render(self._templates.plan, ctx)
         self._run_interactive(prompt, ctx.output_dir)
 
     def init(self, project_name: str, intent_dir: str, prompt: str | None = None) -> None:
@@ -488,15 +720,24 @@ class ClaudeAgent(Agent):
 
     # ---- internal helpers ----
 
+    def _render(self, template: str, ctx: BuildContext) -> str:
+        return render_prompt(
+            template, ctx, max_chars=self._profile.max_prompt_chars, log=self._log
+        )
+
     def _run_non_interactive(
         self,
         prompt: str,
         cwd: str,
         response_file_path: str,
+        output_file_path: str = "",
     ) -> None:
+        _wait_for_turn(self._limiter, self._log)
         self._log("    agent: starting claude")
 
         settings_path = self._write_sandbox_settings(cwd)
+        raw_lines: list[str] = []
+        process: subprocess.Popen | None = None
 
         try:
             cmd = self._build_cmd(prompt)
@@ -512,6 +753,7 @@ class ClaudeAgent(Agent):
 
             assert process.stdout is not None
             for line in process.stdout:
+                raw_lines.append(line)
                 line = line.strip()
                 if not line:
                     continue
@@ -534,7 +776,14 @@ class ClaudeAgent(Agent):
             if returncode != 0:
                 raise AgentError(f"Claude process exited with code {returncode}")
 
+        except KeyboardInterrupt:
+            # Do not leave claude running (and writing files) after Ctrl-C
+            if process is not None:
+                process.kill()
+                process.wait()
+            raise
         finally:
+            _append_output(output_file_path, "".join(raw_lines))
             if settings_path and os.path.exists(settings_path):
                 os.remove(settings_path)
 
@@ -644,7 +893,13 @@ class ClaudeAgent(Agent):
 
 
 class MockAgent(Agent):
-    """Mock agent for testing. Records calls and returns configurable responses."""
+    """Mock agent for testing. Records calls and returns configurable responses.
+
+    *files* (path -> content) are written through the context's file writer
+    on every build.
+    """
+
+    uses_file_writer = True
 
     def __init__(
         self,
@@ -652,8 +907,10 @@ class MockAgent(Agent):
         build_response: BuildResponse | None = None,
         validation_response: ValidationResponse | None = None,
         differencing_response: DifferencingResponse | None = None,
+        files: dict[str, str] | None = None,
     ) -> None:
         self._name = name
+        self._files = files or {}
         self._build_response = build_response or BuildResponse(
             status="success",
             summary="Mock build completed",
@@ -681,6 +938,10 @@ class MockAgent(Agent):
 
     def build(self, ctx: BuildContext) -> BuildResponse:
         self.build_calls.append(ctx)
+        if self._files:
+            writer = ctx.file_writer or DiskWriter(Path(ctx.output_dir))
+            for path, content in self._files.items():
+                writer.write(path, content)
         return self._build_response
 
     def validate(self, ctx: BuildContext, validation: ValidationFile) -> ValidationResponse:
@@ -703,28 +964,111 @@ class MockAgent(Agent):
 # ---------------------------------------------------------------------------
 
 
+# Called as constructor(profile, log=..., limiter=...)
+AgentConstructor = Callable[..., Agent]
+
+# Lower-cased provider name -> constructor of its agents
+_PROVIDERS: dict[str, AgentConstructor] = {}
+
+
+def register_provider(name: str, constructor: AgentConstructor) -> None:
+    """Build the agents of profiles with ``provider: <name>`` with *constructor*.
+
+    Names are case-insensitive, and registering a taken name replaces it.
+    Third-party agents are registered before the CLI runs, e.g. from a
+    script that registers them and then calls ``intentc.cli.main.app()``.
+    """
+    _PROVIDERS[name.lower()] = constructor
+
+
+def registered_providers() -> list[str]:
+    """Names of the providers agents can be created for, sorted."""
+    return sorted(_PROVIDERS)
+
+
+register_provider("claude", ClaudeAgent)
+register_provider("cli", CLIAgent)
+
+
 def create_from_profile(
     profile: AgentProfile,
     log: LogFn | None = None,
+    limiter: RateLimiter | None = None,
 ) -> Agent:
     """Create an agent from an AgentProfile.
 
     Args:
         profile: The agent profile to create from.
         log: Optional logging callback.
+        limiter: Optional rate limiter, usually shared with other agents.
 
     Returns:
         An Agent implementation.
 
     Raises:
-        AgentError: If the provider is unknown.
+        UnknownAgentError: If no provider is registered under the profile's.
     """
-    provider = profile.provider.lower()
-    if provider == "claude":
-        return ClaudeAgent(profile, log=log)
-    if provider == "cli":
-        return CLIAgent(profile, log=log)
-    raise AgentError(
-        f"Unknown agent provider: {profile.provider!r}. "
-        f"Supported providers: 'claude', 'cli'"
-    )
+    constructor = _PROVIDERS.get(profile.provider.lower())
+    if constructor is None:
+        raise UnknownAgentError(profile.provider)
+    return constructor(profile, log=log, limiter=limiter)
+
+
+class AgentFactory:
+    """create_from_profile with one RateLimiter shared per provider.
+
+    *rate_limits* maps a provider to its requests per minute; providers
+    without a positive rate are not limited. Every agent the factory creates
+    for a limited provider draws from the same bucket, so agents running in
+    parallel collectively respect the provider's rate. *max_concurrency*
+    maps a provider to how many requests it accepts at once (default one);
+    for a listed provider, callers hold slots_for(provider) around each
+    build or validation call so the cap covers them all.
+    """
+
+    def __init__(
+        self,
+        rate_limits: dict[str, float] | None = None,
+        log: LogFn | None = None,
+        max_concurrency: dict[str, int] | None = None,
+    ) -> None:
+        self._rates = {p.lower(): r for p, r in (rate_limits or {}).items() if r > 0}
+        self._concurrency = {
+            p.lower(): n for p, n in (max_concurrency or {}).items() if n > 0
+        }
+        self._log = log
+        self._limiters: dict[str, RateLimiter] = {}
+        self._slots: dict[str, threading.BoundedSemaphore] = {}
+        self._lock = threading.Lock()
+
+    def limiter_for(self, provider: str) -> RateLimiter | None:
+        """The shared limiter for *provider*, or None if it is unlimited."""
+        provider = provider.lower()
+        if provider not in self._rates:
+            return None
+        with self._lock:
+            if provider not in self._limiters:
+                self._limiters[provider] = RateLimiter(self._rates[provider])
+            return self._limiters[provider]
+
+    def concurrency_for(self, provider: str) -> int:
+        """How many requests *provider* may have in flight at once."""
+        return self._concurrency.get(provider.lower(), 1)
+
+    def slots_for(self, provider: str) -> threading.BoundedSemaphore | None:
+        """The semaphore every request to *provider* holds while in flight,
+        or None if max_concurrency does not list it."""
+        provider = provider.lower()
+        if provider not in self._concurrency:
+            return None
+        with self._lock:
+            if provider not in self._slots:
+                self._slots[provider] = threading.BoundedSemaphore(
+                    self.concurrency_for(provider)
+                )
+            return self._slots[provider]
+
+    def __call__(self, profile: AgentProfile) -> Agent:
+        return create_from_profile(
+            profile, log=self._log, limiter=self.limiter_for(profile.provider)
+        )
@@ -0,0 +1,54 @@
+"""Token-bucket rate limiting shared by the agents of one provider."""
+
+from __future__ import annotations
+
+import threading
+import time
+from typing import Callable
+
+
+class RateLimiter:
+    """Token bucket allowing *rate* agent requests per minute.
+
+    Up to *burst* requests may start back to back; after that each caller
+    waits for its turn. The limiter is thread-safe, so agents building in
+    parallel can share one and collectively stay under the rate. *clock*
+    and *sleep* are injectable for tests.
+    """
+
+    def __init__(
+        self,
+        rate: float,
+        burst: int = 1,
+        clock: Callable[[], float] = time.monotonic,
+        sleep: Callable[[float], None] = time.sleep,
+    ) -> None:
+        if rate <= 0:
+            raise ValueError(f"rate must be positive, got {rate}")
+        self._per_sec = rate / 60.0
+        self._burst = float(max(burst, 1))
+        self._clock = clock
+        self._sleep = sleep
+        self._tokens = self._burst
+        self._updated = clock()
+        self._lock = threading.Lock()
+
+    @property
+    def rate(self) -> float:
+        """Requests per minute."""
+        return self._per_sec * 60.0
+
+    def acquire(self) -> float:
+        """Take one token, waiting if none is left. Returns the seconds waited."""
+        with self._lock:
+            now = self._clock()
+            self._tokens = min(
+                self._burst, self._tokens + (now - self._updated) * self._per_sec
+            )
+            self._updated = now
+            # Reserve the token now; a negative balance queues later callers
+            self._tokens -= 1
+            wait = -self._tokens / self._per_sec if self._tokens < 0 else 0.0
+        if wait > 0:
+            self._sleep(wait)
+        return wait
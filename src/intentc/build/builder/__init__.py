@@ -1,8 +1,23 @@
 """Builder package for intentc."""
 
-from intentc.build.builder.builder import Builder, BuildOptions
+from intentc.build.builder.builder import (
+    Builder,
+    BuildOptions,
+    BuildReport,
+    BuildStats,
+    PlannedTarget,
+    TargetReport,
+    TargetStats,
+    free_disk_mb,
+)
 
 __all__ = [
     "Builder",
     "BuildOptions",
+    "BuildReport",
+    "BuildStats",
+    "PlannedTarget",
+    "TargetReport",
+    "TargetStats",
+    "free_disk_mb",
 ]
@@ -2,9 +2,18 @@
 
 from __future__ import annotations
 
+import contextlib
+import fnmatch
+import hashlib
 import json
 import os
+import re
+import shutil
+import tempfile
+import threading
+import time
 import uuid
+from concurrent.futures import FIRST_COMPLETED, Future, ThreadPoolExecutor, wait
 from datetime import datetime
 from pathlib import Path
 from typing import Callable
@@ -14,22 +23,48 @@ from pydantic import BaseModel, Field
 from intentc.build.agents import (
     Agent,
     AgentError,
+    AgentFactory,
     AgentProfile,
     BuildContext,
     BuildResponse,
-    create_from_profile,
+    NoCapableAgentError,
+    UnknownProfileError,
 )
+from intentc.build.builder.schedule import weighted_order
+from intentc.build.events import EventLog
 from intentc.build.state import (
     BuildResult,
     BuildStep,
     StateManager,
     TargetStatus,
     VersionControl,
+    recorded_output_dirs,
+    target_slug,
+    worktree_path,
 )
 from intentc.build.storage import StorageBackend
 from intentc.build.storage.backend import GenerationStatus
-from intentc.build.validations import ValidationSuite, ValidationSuiteResult
-from intentc.core.models import IntentFile, ValidationFile
+from intentc.build.validations import (
+    AgentBudget,
+    ValidationSuite,
+    ValidationSuiteResult,
+)
+from intentc.build.writer import (
+    DiskWriter,
+    FileChange,
+    FileWriter,
+    MemoryWriter,
+    SnapshotWriter,
+    is_skipped,
+)
+from intentc.core.models import (
+    DependencyCycleError,
+    Implementation,
+    IntentFile,
+    TargetNotFoundError,
+    UnbuiltDependencyError,
+    ValidationFile,
+)
 from intentc.core.project import Project
 
 # ---------------------------------------------------------------------------
@@ -39,6 +74,87 @@ from intentc.core.project import Project
 LogFn = Callable[[str], None]
 _NOOP_LOG: LogFn = lambda _msg: None
 
+
+def free_disk_mb(path: str | Path) -> float:
+    """Free space in MB on the filesystem holding path.
+
+    The path need not exist yet; the nearest existing parent is checked.
+    """
+    probe = Path(path).resolve()
+    while not probe.exists() and probe != probe.parent:
+        probe = probe.parent
+    return shutil.disk_usage(probe).free / (1024 * 1024)
+
+
+# Total size cap for context files injected into a single prompt
+MAX_CONTEXT_CHARS = 100_000
+
+
+def load_context_files(
+    paths: list[str],
+    base_dir: Path,
+    max_chars: int = MAX_CONTEXT_CHARS,
+    log: LogFn = _NOOP_LOG,
+) -> dict[str, str]:
+    """Read an intent's context files, relative to *base_dir*, in order.
+
+    Once *max_chars* is reached the current file is truncated with a note and
+    any remaining files are dropped. Unreadable files are skipped with a warning.
+    """
+    loaded: dict[str, str] = {}
+    remaining = max_chars
+    for rel in paths:
+        if remaining <= 0:
+            log(f"  Warning: context file '{rel}' omitted (context size limit reached)")
+            continue
+        try:
+            content = (base_dir / rel).read_text(encoding="utf-8")
+        except (OSError, UnicodeDecodeError) as exc:
+            log(f"  Warning: context file '{rel}' could not be read: {exc}")
+            continue
+        if len(content) > remaining:
+            content = (
+                content[:remaining]
+                + f"\n[... truncated: context size limit of {max_chars} characters reached]"
+            )
+            remaining = 0
+        else:
+            remaining -= len(content)
+        loaded[rel] = content
+    return loaded
+
+
+_OUTPUT_REF_RE = re.compile(
+    r"\{\{\s*dep\.(?P<feature>[^\s{}]+?)\.outputs\.(?P<key>[\w.-]+?)\s*\}\}"
+)
+
+
+def resolve_dependency_outputs(
+    body: str,
+    outputs_for: Callable[[str], dict[str, str] | None],
+    log: LogFn = _NOOP_LOG,
+) -> str:
+    """Replace ``{{dep.<feature>.outputs.<key>}}`` in *body* with recorded values.
+
+    *outputs_for* returns the outputs of a feature's latest build, or None if
+    it may not be referenced. A reference that cannot be resolved is left in
+    place with a warning, so the agent sees what was meant.
+    """
+
+    def _replace(match: re.Match[str]) -> str:
+        feature, key = match.group("feature"), match.group("key")
+        outputs = outputs_for(feature)
+        if outputs is None:
+            log(f"  Warning: '{match.group(0)}' does not name a dependency")
+        elif key not in outputs:
+            log(f"  Warning: '{match.group(0)}' has no recorded value")
+        else:
+            return outputs[key]
+        return match.group(0)
+
+    return _OUTPUT_REF_RE.sub(_replace, body)
+
+
 # ---------------------------------------------------------------------------
 # BuildOptions
 # ---------------------------------------------------------------------------
@@ -48,11 +164,76 @@ class BuildOptions(BaseModel):
     """Options controlling a build invocation."""
 
     target: str = ""
+    tag: str = ""  # Build every feature with this tag (and its dependencies)
     force: bool = False
     dry_run: bool = False
+    preview: bool = False  # With dry_run, run the agents and report the files they would change
     output_dir: str = ""
     profile_override: str = ""
     implementation: str = ""
+    min_free_mb: int = 0  # 0 disables the disk space check
+    ignore_disk_space: bool = False  # Only warn when free space is below min_free_mb
+    resume: bool = False  # Continue the target set of an interrupted build
+    run_validations: bool = True  # Run each target's validations right after it builds
+    worktree: str = ""  # Build into .intentc/worktrees/<name> on branch intentc/<name>
+    retry_failed: bool = False  # Only build targets whose last build failed
+    cascade: bool = True  # Mark built dependents of each rebuilt target outdated
+    select_deps: str = "transitive"  # With a target or tag: "transitive", "direct" or "none"
+    force_agent: bool = False  # Call the agent even if the intent and its dependencies are unchanged
+    parallelism: int = 1  # Targets built at once; 1 or less builds them one after another
+    report_path: str = ""  # Write a JSON BuildReport here when the build finishes
+
+
+class PlannedTarget(BaseModel):
+    """One entry of a build plan, in build order."""
+
+    target: str
+    reason: str  # "pending", "failed", "outdated", "changed", "forced" or "dependency"
+    dependencies: list[str] = Field(default_factory=list)
+
+
+class TargetStats(BaseModel):
+    """How one target fared in a build."""
+
+    target: str
+    status: str
+    duration_secs: float = 0.0
+    attempts: int = 0  # Agent runs, retries included
+
+
+class BuildStats(BaseModel):
+    """Aggregate metrics of one build, for judging its performance."""
+
+    total_targets: int = 0
+    built: int = 0
+    skipped: int = 0
+    failed: int = 0
+    total_duration_secs: float = 0.0
+    attempts: int = 0
+    retries: int = 0
+    targets: list[TargetStats] = Field(default_factory=list)
+
+
+class TargetReport(BaseModel):
+    """One target's entry in a BuildReport."""
+
+    target: str
+    status: str  # "built", "failed" or "skipped"
+    forced: bool = False  # Rebuilt although it was already built
+    generation_id: str = ""
+    output_dir: str = ""
+    files: list[str] = Field(default_factory=list)  # Relative to output_dir
+    duration_secs: float = 0.0
+
+
+class BuildReport(BaseModel):
+    """Machine-readable summary of one build, written to ``report_path``."""
+
+    generation_id: str
+    status: str  # The generation's status: "completed" or "failed"
+    output_dir: str
+    targets: list[TargetReport] = Field(default_factory=list)
+    error: str = ""  # Why the build stopped before building anything
 
 
 # ---------------------------------------------------------------------------
@@ -71,24 +252,74 @@ class Builder:
         agent_profile: AgentProfile,
         log: LogFn | None = None,
         create_agent: Callable[[AgentProfile], Agent] | None = None,
+        events: EventLog | None = None,
+        validation_parallelism: int = 0,
+        deadline: float | None = None,
+        rate_limits: dict[str, float] | None = None,
+        agent_profiles: list[AgentProfile] | None = None,
+        max_concurrency: dict[str, int] | None = None,
     ) -> None:
         self._project = project
         self._state_manager = state_manager
         self._version_control = version_control
         self._agent_profile = agent_profile
+        # Further profiles for targets whose intents require capabilities
+        # *agent_profile* lacks
+        self._agent_profiles = list(agent_profiles or [])
         self._log = log or _NOOP_LOG
+        self._events = events
+        self._validation_parallelism = validation_parallelism
+        # time.monotonic() value after which no more agent work is started
+        self._deadline = deadline
         self._storage: StorageBackend = state_manager.backend
 
-        if create_agent is not None:
-            self._create_agent = create_agent
-        else:
-            # Wrap the default factory to pass our log callback
-            _log = self._log
-
-            def _default_factory(profile: AgentProfile) -> Agent:
-                return create_from_profile(profile, log=_log)
-
-            self._create_agent = _default_factory
+        # Agents share one rate limiter per provider, validation agents included
+        self._agents = AgentFactory(
+            rate_limits, log=self._log, max_concurrency=max_concurrency
+        )
+        self._create_agent = create_agent or self._agents
+        # Targets the last build() left alone because they were up to date
+        self._skipped: list[str] = []
+        # Agent runs per target in the last build(), retries included
+        self._attempts: dict[str, int] = {}
+        # Set when a target fails, so targets building alongside it stop
+        self._cancelled = threading.Event()
+        # Serializes checkpoints: parallel targets share one git index
+        self._checkpoint_lock = threading.Lock()
+        # Serializes ownership claims, which parallel targets make as they finish
+        self._ownership_lock = threading.Lock()
+        # Whether the current build runs targets in parallel
+        self._parallel = False
+        # Waits out a retry backoff; returns early once the build is cancelled
+        self._sleep: Callable[[float], object] = self._cancelled.wait
+
+    @property
+    def skipped(self) -> list[str]:
+        """Targets the last build skipped as up to date (built or unchanged)."""
+        return list(self._skipped)
+
+    def stats(self, results: list[BuildResult]) -> BuildStats:
+        """Summarize the last build, given the *results* it returned."""
+        targets = [
+            TargetStats(
+                target=r.target,
+                status=r.status,
+                duration_secs=r.total_duration_secs,
+                attempts=self._attempts.get(r.target, 0),
+            )
+            for r in results
+        ]
+        attempts = sum(t.attempts for t in targets)
+        return BuildStats(
+            total_targets=len(results) + len(self._skipped),
+            built=sum(1 for r in results if r.status == TargetStatus.BUILT.value),
+            skipped=len(self._skipped),
+            failed=sum(1 for r in results if r.status == TargetStatus.FAILED.value),
+            total_duration_secs=sum(t.duration_secs for t in targets),
+            attempts=attempts,
+            retries=sum(max(t.attempts - 1, 0) for t in targets),
+            targets=targets,
+        )
 
     # ------------------------------------------------------------------
     # Build
@@ -96,14 +327,70 @@ class Builder:
 
     def build(
         self, opts: BuildOptions
-    ) -> tuple[list[BuildResult], RuntimeError | None]:
+    ) -> tuple[list[BuildResult], Exception | None]:
         """Execute the build pipeline.
 
         Returns (results, error). Error is non-null if any target failed.
         """
+        self._skipped = []
+        self._attempts = {}
+        self._cancelled.clear()
+
+        # 0. Detect a concurrent or interrupted build
+        lock = self._state_manager.build_lock
+        held = lock.read()
+        if held is not None and lock.is_active(held):
+            message = (
+                f"Another build (pid {held['pid']}) is running for this output directory"
+            )
+            self._log(f"Build aborted: {message}")
+            return self._aborted(opts, RuntimeError(message))
+        if not opts.dry_run:
+            self._recover_interrupted(held)
+        elif held is not None:
+            self._log("A previous build was interrupted; the next build will recover it.")
+        if opts.resume:
+            if held is None:
+                self._log("No interrupted build to resume.")
+                return ([], None)
+            opts = opts.model_copy(
+                update={"target": held.get("target", ""), "tag": held.get("tag", "")}
+            )
+            what = f"tag '{opts.tag}'" if opts.tag else opts.target or "all targets"
+            self._log(f"Resuming interrupted build of {what}")
+
         # 1. Determine build set
-        build_set = self._determine_build_set(opts)
+        try:
+            build_set = self._determine_build_set(opts)
+            if not opts.force and not opts.retry_failed:
+                # Whatever a forced build would add is already built
+                considered = self._determine_build_set(opts.model_copy(update={"force": True}))
+                self._skipped = [t for t in considered if t not in build_set]
+            profile = self._resolve_profile(opts.profile_override)
+            for target in build_set:
+                self._profile_for(target, profile, opts.profile_override)
+                named = self._project.features[self._project.split_target(target)[0]].agent
+                if named and opts.profile_override:
+                    self._log(
+                        f"Target '{target}' names agent profile '{named}'; "
+                        f"using --profile '{opts.profile_override}' instead"
+                    )
+        except (
+            DependencyCycleError,
+            UnbuiltDependencyError,
+            NoCapableAgentError,
+            UnknownProfileError,
+        ) as exc:
+            self._log(f"Build aborted: {exc}")
+            return self._aborted(opts, exc)
         if not build_set:
+            if opts.report_path and not opts.dry_run:
+                self._write_report(
+                    opts.report_path,
+                    self._build_report(
+                        "", GenerationStatus.COMPLETED, opts.output_dir, [], [], set()
+                    ),
+                )
             return ([], None)
 
         self._log(
@@ -111,6 +398,8 @@ class Builder:
         )
 
         # 2. Dry run check
+        if opts.dry_run and opts.preview:
+            return (self._preview(build_set, opts), None)
         if opts.dry_run:
             results = [
                 BuildResult(
@@ -121,12 +410,65 @@ class Builder:
             ]
             return (results, None)
 
-        # 3. Resolve implementation
+        # 3. Disk space check — refuse to start a build that could fill the disk
+        if opts.min_free_mb > 0:
+            free_mb = free_disk_mb(opts.output_dir or ".")
+            if free_mb < opts.min_free_mb:
+                message = (
+                    f"Low disk space: {free_mb:.0f} MB free for "
+                    f"'{opts.output_dir or '.'}', below the {opts.min_free_mb} MB minimum"
+                )
+                if not opts.ignore_disk_space:
+                    self._log(f"{message}. Aborting (use --ignore-disk-space to build anyway).")
+                    return self._aborted(
+                        opts, RuntimeError(f"{message} (use --ignore-disk-space to build anyway)")
+                    )
+                self._log(f"Warning: {message}. Continuing because of --ignore-disk-space.")
+
+        # 4. Set up the build worktree, so output is committed on its own branch
+        output_dir = opts.output_dir
+        version_control = self._version_control
+        if opts.worktree:
+            path = worktree_path(self._state_manager.base_dir, opts.worktree)
+            branch = f"intentc/{opts.worktree}"
+            try:
+                version_control = self._version_control.worktree(path, branch)
+            except Exception as exc:
+                message = f"Could not set up worktree '{opts.worktree}': {exc}"
+                self._log(f"Build aborted: {message}")
+                return self._aborted(opts, RuntimeError(message))
+            output_dir = str(path / opts.output_dir) if opts.output_dir else str(path)
+            self._log(f"Building in worktree '{path}' on branch '{branch}'")
+
+        # 5. Resolve implementation
         impl_name = opts.implementation or None
         implementation = self._project.resolve_implementation(impl_name)
 
-        # 4. Generate generation ID
+        # 6. Take the build lock and open a generation. The lock stays behind
+        # if the process dies so the next run can tell the build was
+        # interrupted; every other way out of the build releases it.
         generation_id = str(uuid.uuid4())
+        if not lock.acquire(generation_id, opts.target, tag=opts.tag):
+            message = "Another build is running for this output directory"
+            self._log(f"Build aborted: {message}")
+            return self._aborted(opts, RuntimeError(message))
+        try:
+            return self._build_generation(
+                generation_id, build_set, opts, output_dir, implementation, version_control
+            )
+        finally:
+            lock.release()
+
+    def _build_generation(
+        self,
+        generation_id: str,
+        build_set: list[str],
+        opts: BuildOptions,
+        output_dir: str,
+        implementation: object | None,
+        version_control: VersionControl,
+    ) -> tuple[list[BuildResult], Exception | None]:
+        """Build *build_set* as generation *generation_id* under the build lock."""
         profile = self._resolve_profile(opts.profile_override)
         opts_dict = opts.model_dump()
         self._storage.create_generation(
@@ -140,80 +482,554 @@ class Builder:
             generation_id,
             f"Build started: {len(build_set)} target(s) in topological order",
         )
+        self._emit(
+            "build_started",
+            generation_id=generation_id,
+            targets=build_set,
+            output_dir=opts.output_dir,
+        )
 
-        # 5. Resolve output directory
-        output_dir = opts.output_dir
+        # 7. Resolve output directory
         if output_dir:
             os.makedirs(output_dir, exist_ok=True)
 
-        # 6. Build each target
+        # 8. Build each target
         results: list[BuildResult] = []
         error: RuntimeError | None = None
+        forced = {
+            t
+            for t in build_set
+            if opts.force and self._state_manager.get_status(t) == TargetStatus.BUILT
+        }
 
-        for idx, target in enumerate(build_set):
-            self._log(
-                f"[{idx + 1}/{len(build_set)}] Building target '{target}'..."
+        def run(target: str) -> tuple[BuildResult | None, RuntimeError | None]:
+            return self._run_target(
+                target, generation_id, output_dir, opts, implementation, version_control
             )
 
-            # Skip check
-            status = self._state_manager.get_status(target)
-            if status == TargetStatus.BUILT and not opts.force:
-                self._log(f"  Skipping '{target}' (already built)")
-                self._storage.log_generation_event(
-                    generation_id, f"Skipped '{target}': already built"
+        self._parallel = opts.parallelism > 1
+        try:
+            if self._parallel:
+                results, error = self._build_parallel(build_set, opts.parallelism, run)
+            else:
+                for idx, target in enumerate(build_set):
+                    self._log(
+                        f"[{idx + 1}/{len(build_set)}] Building target '{target}'..."
+                    )
+                    result, error = run(target)
+                    if result is not None:
+                        results.append(result)
+                    if error is not None:
+                        break
+        except KeyboardInterrupt:
+            self._cancelled.set()
+            self._abort_interrupted(generation_id)
+            if opts.report_path:
+                self._write_report(
+                    opts.report_path,
+                    self._build_report(
+                        generation_id, GenerationStatus.FAILED, output_dir, build_set,
+                        results, forced,
+                    ),
                 )
-                continue
+            raise
 
-            result, target_error = self._build_target(
-                target=target,
-                generation_id=generation_id,
-                output_dir=output_dir,
-                profile_override=opts.profile_override,
-                implementation=implementation,
+        # 9. Complete generation
+        gen_status = (
+            GenerationStatus.FAILED if error else GenerationStatus.COMPLETED
+        )
+        self._storage.complete_generation(generation_id, gen_status)
+        self._emit(
+            "build_finished",
+            generation_id=generation_id,
+            status=gen_status.value,
+            built=sum(1 for r in results if r.status == "built"),
+            failed=sum(1 for r in results if r.status == "failed"),
+        )
+        if opts.report_path:
+            self._write_report(
+                opts.report_path,
+                self._build_report(
+                    generation_id, gen_status, output_dir, build_set, results, forced
+                ),
             )
-            results.append(result)
 
-            # Save result
-            self._state_manager.save_build_result(target, result)
+        return (results, error)
 
-            # Read and store agent response, then delete from disk
-            self._save_and_cleanup_response(target, result, generation_id)
+    def _aborted(
+        self, opts: BuildOptions, error: Exception
+    ) -> tuple[list[BuildResult], Exception]:
+        """Report a build that stopped before building anything as failed."""
+        if opts.report_path and not opts.dry_run:
+            self._write_report(
+                opts.report_path,
+                BuildReport(
+                    generation_id="",
+                    status=GenerationStatus.FAILED.value,
+                    output_dir=opts.output_dir,
+                    error=str(error),
+                ),
+            )
+        return ([], error)
 
-            if target_error is not None:
-                self._storage.log_generation_event(
-                    generation_id,
-                    f"Build failed for target '{target}': {target_error}",
+    @staticmethod
+    def _write_report(path: str, report: BuildReport) -> None:
+        report_path = Path(path)
+        report_path.parent.mkdir(parents=True, exist_ok=True)
+        report_path.write_text(report.model_dump_json(indent=2) + "\n", encoding="utf-8")
+
+    def _build_report(
+        self,
+        generation_id: str,
+        status: GenerationStatus,
+        output_dir: str,
+        build_set: list[str],
+        results: list[BuildResult],
+        forced: set[str],
+    ) -> BuildReport:
+        """Report *results* in *build_set* order, then the skipped targets.
+
+        Targets that never started because an earlier one failed are left out.
+        """
+        by_target = {r.target: r for r in results}
+        skipped = set(self._skipped)
+        order = build_set + [t for t in self._skipped if t not in build_set]
+        targets: list[TargetReport] = []
+        for target in order:
+            result = by_target.get(target)
+            if result is None:
+                if target in skipped:
+                    targets.append(
+                        TargetReport(target=target, status="skipped", output_dir=output_dir)
+                    )
+                continue  # Never started: an earlier target failed
+            created, modified = self._generated_files(result)
+            targets.append(
+                TargetReport(
+                    target=target,
+                    status=result.status,
+                    forced=target in forced,
+                    generation_id=generation_id,
+                    output_dir=output_dir,
+                    files=created + modified,
+                    duration_secs=result.total_duration_secs,
                 )
-                error = target_error
-                break
+            )
+        return BuildReport(
+            generation_id=generation_id,
+            status=status.value,
+            output_dir=output_dir,
+            targets=targets,
+        )
 
-            self._log(f"  Target '{target}' completed successfully.")
+    def _run_target(
+        self,
+        target: str,
+        generation_id: str,
+        output_dir: str,
+        opts: BuildOptions,
+        implementation: object | None,
+        version_control: VersionControl,
+    ) -> tuple[BuildResult | None, RuntimeError | None]:
+        """Build *target* unless it is up to date, and record the outcome.
 
-        # 6. Complete generation
-        gen_status = (
-            GenerationStatus.FAILED if error else GenerationStatus.COMPLETED
+        Returns (result, error); the result is None when the target was
+        skipped.
+        """
+        # Skip check
+        status = self._state_manager.get_status(target)
+        changed = status == TargetStatus.BUILT and self._inputs_changed(target)
+        if changed and not opts.force:
+            self._log(f"  Rebuilding '{target}' (its intent or validations changed)")
+        elif status == TargetStatus.BUILT and not opts.force:
+            self._log(f"  Skipping '{target}' (already built)")
+            self._storage.log_generation_event(
+                generation_id, f"Skipped '{target}': already built"
+            )
+            self._emit("target_skipped", target=target, reason="already built")
+            self._skipped.append(target)
+            return None, None
+
+        input_hash = self._input_hash(target, implementation)
+        if not opts.force_agent and self._unchanged(target, input_hash, output_dir):
+            self._log(
+                f"  Skipping '{target}' (unchanged since its last build; "
+                f"use --force-agent to rebuild)"
+            )
+            self._storage.log_generation_event(
+                generation_id, f"Skipped '{target}': unchanged"
+            )
+            self._emit("target_skipped", target=target, reason="unchanged")
+            self._state_manager.set_status(target, TargetStatus.BUILT)
+            self._skipped.append(target)
+            return None, None
+
+        self._emit("target_started", target=target)
+        self._state_manager.set_status(target, TargetStatus.BUILDING)
+
+        result, target_error = self._build_target(
+            target=target,
+            generation_id=generation_id,
+            output_dir=output_dir,
+            profile_override=opts.profile_override,
+            implementation=implementation,
+            run_validations=opts.run_validations,
+            version_control=version_control,
         )
-        self._storage.complete_generation(generation_id, gen_status)
+        result.input_hash = input_hash
+        result.intent_hash = self._intent_hash(target)
+        result.file_hashes = self._file_hashes(result, output_dir)
+        result.file_sources = self._file_sources(result)
 
-        return (results, error)
+        # Save result
+        self._state_manager.save_build_result(target, result)
+
+        # Read and store agent response, then delete from disk
+        self._save_and_cleanup_response(target, result, generation_id)
+
+        # Record which files this target generated
+        self._record_ownership(target, result, generation_id, output_dir)
+
+        if target_error is not None:
+            self._storage.log_generation_event(
+                generation_id,
+                f"Build failed for target '{target}': {target_error}",
+            )
+            self._emit("target_failed", target=target, error=str(target_error))
+            return result, target_error
+
+        self._emit_generated_files(target, result)
+        if opts.cascade:
+            self._cascade_outdated(target)
+        self._emit(
+            "target_built",
+            target=target,
+            commit_id=result.commit_id,
+            duration_secs=result.total_duration_secs,
+        )
+        self._log(f"  Target '{target}' completed successfully.")
+        return result, None
+
+    def _build_parallel(
+        self,
+        build_set: list[str],
+        parallelism: int,
+        run: Callable[[str], tuple[BuildResult | None, RuntimeError | None]],
+    ) -> tuple[list[BuildResult], RuntimeError | None]:
+        """Run up to *parallelism* targets of *build_set* at once.
+
+        A target starts once the targets it depends on in *build_set* are
+        done, earlier targets in *build_set* first. After a failure no
+        further targets start, and the ones still building stop before their
+        next attempt. Results are in the order the targets finished.
+        """
+        feature_targets = {t.partition(":")[0]: t for t in build_set}
+        deps = {
+            t: {
+                feature_targets[d]
+                for d in self._project.features[t.partition(":")[0]].depends_on
+                if d in feature_targets
+            }
+            for t in build_set
+        }
+        pending = list(build_set)
+        done: set[str] = set()
+        running: dict[Future, str] = {}
+        results: list[BuildResult] = []
+        error: RuntimeError | None = None
+        started = 0
+
+        pool = ThreadPoolExecutor(max_workers=parallelism)
+        try:
+            while pending or running:
+                if error is None:
+                    for target in list(pending):
+                        if len(running) >= parallelism:
+                            break
+                        if deps[target] <= done:
+                            pending.remove(target)
+                            started += 1
+                            self._log(
+                                f"[{started}/{len(build_set)}] Building target '{target}'..."
+                            )
+                            running[pool.submit(run, target)] = target
+                if not running:
+                    break
+                finished, _ = wait(running, return_when=FIRST_COMPLETED)
+                for future in finished:
+                    target = running.pop(future)
+                    result, target_error = future.result()
+                    done.add(target)
+                    if result is not None:
+                        results.append(result)
+                    if target_error is not None and error is None:
+                        error = target_error
+                        self._cancelled.set()
+        except BaseException:
+            # Ctrl-C: drop the targets not yet started instead of waiting for them
+            self._cancelled.set()
+            pool.shutdown(wait=False, cancel_futures=True)
+            raise
+        pool.shutdown()
+        return results, error
+
+    def _intent_hash(self, target: str) -> str:
+        """Hash the content of *target*'s intents and validation files."""
+        feature = target.partition(":")[0]
+        node = self._project.features.get(feature)
+        if node is None:
+            return ""
+        digest = hashlib.sha256(target.encode("utf-8"))
+        for intent in node.intents:
+            digest.update(intent.model_dump_json(exclude={"source_path"}).encode("utf-8"))
+        for vf in node.validations:
+            digest.update(vf.model_dump_json(exclude={"source_path"}).encode("utf-8"))
+        return digest.hexdigest()
+
+    def _input_hash(self, target: str, implementation: object | None = None) -> str:
+        """Hash what building *target* starts from.
+
+        That is the content of the feature's intents and validation files,
+        the project intent, the selected *implementation*, the intents'
+        context files and the generation each dependency was last built in,
+        so rebuilding a dependency changes it.
+        """
+        feature = target.partition(":")[0]
+        node = self._project.features.get(feature)
+        if node is None:
+            return ""
+        digest = hashlib.sha256(self._intent_hash(target).encode("utf-8"))
+        digest.update(
+            self._project.project_intent.model_dump_json(exclude={"source_path"}).encode("utf-8")
+        )
+        if isinstance(implementation, Implementation):
+            digest.update(implementation.model_dump_json(exclude={"source_path"}).encode("utf-8"))
+        for intent in node.intents:
+            base_dir = self._context_dir(intent, feature)
+            for rel in intent.context:
+                try:
+                    content = (base_dir / rel).read_bytes()
+                except OSError:
+                    content = b""
+                digest.update(f"\0{rel}=".encode("utf-8") + content)
+        for dep in node.depends_on:
+            result = self._state_manager.get_build_result(dep)
+            generation = result.generation_id if result is not None else ""
+            digest.update(f"\0{dep}={generation or ''}".encode("utf-8"))
+        return digest.hexdigest()
+
+    def _inputs_changed(self, target: str) -> bool:
+        """True if *target*'s intents or validations changed since its last build.
+
+        Rebuilt dependencies do not count; cascading marks their dependents
+        outdated. Builds recorded before intent hashes were stored count as
+        unchanged.
+        """
+        latest = self._state_manager.get_build_result(target)
+        if latest is None or not latest.intent_hash:
+            return False
+        return latest.intent_hash != self._intent_hash(target)
+
+    def _unchanged(self, target: str, input_hash: str, output_dir: str) -> bool:
+        """True if *target*'s last build succeeded from the same *input_hash*
+        and every file it generated is still there."""
+        latest = self._state_manager.get_build_result(target)
+        if latest is None or latest.status != "built" or latest.input_hash != input_hash:
+            return False
+        owned = self._state_manager.ownership.files_for(target, output_dir)
+        return all((self._state_manager.base_dir / path).exists() for path in owned)
+
+    def _cascade_outdated(self, target: str) -> None:
+        """Mark built targets downstream of a freshly built *target* outdated.
+
+        Their inputs just changed, so the next build picks them up. Targets
+        that are pending or failed are left as they are.
+        """
+        feature = target.partition(":")[0]
+        if feature not in self._project.features:
+            return
+        downstream = self._project.descendants(feature)
+        stale = [
+            name
+            for name, status in self._state_manager.list_targets()
+            if status == TargetStatus.BUILT and name.partition(":")[0] in downstream
+        ]
+        for name in sorted(stale):
+            self._state_manager.set_status(name, TargetStatus.OUTDATED)
+        if stale:
+            self._log(f"  Marked {len(stale)} dependent(s) outdated: {', '.join(sorted(stale))}")
+
+    def _abort_interrupted(self, generation_id: str) -> None:
+        """Leave consistent state behind when the user interrupts a build.
+
+        The target being built is marked failed so the next build retries
+        it, the generation is closed out and the lock is released, so the
+        next run does not have to recover.
+        """
+        for name, status in self._state_manager.list_targets():
+            if status == TargetStatus.BUILDING:
+                self._log(f"Target '{name}' was interrupted; marking it failed.")
+                self._state_manager.set_status(name, TargetStatus.FAILED)
+        self._storage.log_generation_event(generation_id, "Build interrupted")
+        self._storage.complete_generation(generation_id, GenerationStatus.FAILED)
+        self._state_manager.build_lock.release()
+        self._emit("build_finished", generation_id=generation_id, status="interrupted")
+
+    def _recover_interrupted(self, held: dict | None) -> None:
+        """Reset state left behind by a build that did not finish.
+
+        Targets stuck in ``building`` are marked failed so they are rebuilt
+        instead of skipped, and the interrupted generation is closed out.
+        """
+        for name, status in self._state_manager.list_targets():
+            if status == TargetStatus.BUILDING:
+                self._log(
+                    f"Target '{name}' was interrupted by a previous build; "
+                    f"marking it failed so it is rebuilt."
+                )
+                self._state_manager.set_status(name, TargetStatus.FAILED)
+
+        if held is None:
+            return
+        generation_id = held.get("generation_id")
+        if generation_id:
+            self._storage.log_generation_event(generation_id, "Build interrupted")
+            self._storage.complete_generation(generation_id, GenerationStatus.FAILED)
+        self._state_manager.build_lock.release()
+
+    # ------------------------------------------------------------------
+    # Replay
+    # ------------------------------------------------------------------
+
+    def replay(self, result: BuildResult, output_dir: str, force: bool = False) -> list[str]:
+        """Write the files of a recorded build back into *output_dir*.
+
+        The content comes from the build's checkpoint, so no agent runs and
+        the build state is left alone. Returns the paths whose checkpointed
+        content does not match the hash recorded at build time; unless
+        *force* is set, nothing is written when there are any. Raises
+        ValueError when the build has no checkpoint or no recorded files.
+        """
+        label = f"Generation '{result.generation_id}' of '{result.target}'"
+        if not result.commit_id:
+            raise ValueError(f"{label} has no checkpoint commit")
+        if not result.file_hashes:
+            raise ValueError(f"{label} has no recorded files")
+
+        # Read everything first so mismatches are known before any file changes
+        contents: dict[str, bytes] = {}
+        mismatched: list[str] = []
+        for rel, recorded in sorted(result.file_hashes.items()):
+            repo_path = (Path(output_dir) / rel).as_posix() if output_dir else rel
+            contents[repo_path] = self._version_control.read_file(result.commit_id, repo_path)
+            if hashlib.sha256(contents[repo_path]).hexdigest() != recorded:
+                mismatched.append(rel)
+        if mismatched and not force:
+            return mismatched
+
+        for repo_path, content in contents.items():
+            full = Path(repo_path)
+            if not full.is_absolute():
+                full = self._state_manager.base_dir / full
+            full.parent.mkdir(parents=True, exist_ok=True)
+            full.write_bytes(content)
+        self._log(
+            f"Replayed {len(result.file_hashes)} file(s) of generation "
+            f"'{result.generation_id}' for '{result.target}'"
+        )
+        return mismatched
 
     # ------------------------------------------------------------------
     # Clean
     # ------------------------------------------------------------------
 
-    def clean(self, target: str, output_dir: str) -> None:
-        """Revert a target's generated code and reset its state."""
+    def clean_targets(self, target: str, cascade: bool = False) -> list[str]:
+        """*target*, then with *cascade* every feature depending on it in build order."""
+        feature = target.partition(":")[0]
+        if not cascade or feature not in self._project.features:
+            return [target]
+        dependents = self._project.descendants(feature)
+        return [target] + [
+            t for t in self._project.topological_order() if t in dependents
+        ]
+
+    def clean(self, target: str, output_dir: str, cascade: bool = False) -> None:
+        """Revert a target's generated code and reset its state.
+
+        With *cascade*, every feature that depends on the target is cleaned
+        the same way; otherwise dependents are only marked outdated.
+
+        When the ownership index knows which files the target generated in
+        *output_dir*, only those files are removed so other targets' output,
+        and this target's output in other directories, is left alone.
+        Otherwise the output is restored from the target's checkpoint. The
+        target's recorded build results for this output directory are
+        deleted with its state, so nothing stale outlives the clean.
+        """
+        for name in self.clean_targets(target, cascade):
+            self._clean_one(name, output_dir)
+
+    def _clean_one(self, target: str, output_dir: str) -> None:
         result = self._state_manager.get_build_result(target)
         if result is None:
+            # Nothing to clean for a known target; an unknown one is likely a
+            # typo, so surface it (with suggestions) instead of doing nothing.
+            self._project.split_target(target)
             return
 
-        if result.commit_id:
+        ownership = self._state_manager.ownership
+        owned = ownership.files_for(target, output_dir)
+        if owned:
+            for path in owned:
+                full = Path(path)
+                if not full.is_absolute():
+                    full = self._state_manager.base_dir / full
+                try:
+                    full.unlink()
+                except FileNotFoundError:
+                    pass
+            ownership.release(target, output_dir)
+            ownership.save()
+            self._log(f"Removed {len(owned)} file(s) owned by '{target}'")
+        elif result.commit_id:
             self._version_control.restore(result.commit_id)
             # Do NOT checkpoint — restored files are left unstaged
 
         self._state_manager.reset(target)
+        self._state_manager.delete_build_results(target)
         self._state_manager.mark_dependents_outdated(target, self._project)
 
+    def resolve_targets(self, patterns: list[str]) -> list[str]:
+        """Expand target names and wildcard patterns into concrete targets.
+
+        Patterns are matched against project features and any targets with
+        recorded state (e.g. built sub-targets). The result is de-duplicated
+        and in topological order. Raises TargetNotFoundError (a KeyError) when
+        a name is unknown or a pattern matches nothing.
+        """
+        known = set(self._project.features) | {
+            name for name, _ in self._state_manager.list_targets()
+        }
+        matched: set[str] = set()
+        for pattern in patterns:
+            if "*" in pattern or "?" in pattern:
+                hits = {t for t in known if fnmatch.fnmatch(t, pattern)}
+                if not hits:
+                    raise TargetNotFoundError(
+                        f"Pattern '{pattern}' matched no targets.", pattern
+                    )
+                matched |= hits
+            else:
+                if pattern not in known:
+                    self._project.split_target(pattern)
+                matched.add(pattern)
+
+        topo_index = {t: i for i, t in enumerate(self._project.topological_order())}
+        return sorted(
+            matched,
+            key=lambda t: (topo_index.get(t.partition(":")[0], len(topo_index)), t),
+        )
+
     def clean_all(self, output_dir: str) -> None:
         """Reset all state. Does not modify files."""
         self._state_manager.reset_all()
@@ -223,21 +1039,38 @@ class Builder:
     # ------------------------------------------------------------------
 
     def validate(
-        self, target: str | None, output_dir: str
+        self,
+        target: str | list[str] | None,
+        output_dir: str,
+        agent_budget: AgentBudget | None = None,
     ) -> ValidationSuiteResult | list[ValidationSuiteResult]:
-        """Run validations independently of the build pipeline."""
+        """Run validations independently of the build pipeline.
+
+        *target* is one target, a list of features (validated together, one
+        result each) or None for the whole project. *agent_budget* caps the
+        agent-backed validations; pass the same budget to several calls to
+        cap them together.
+        """
         profile = self._resolve_profile("")
         suite = ValidationSuite(
             project=self._project,
-            agent_profile=profile,
+            agent_profile=self._within_deadline(profile),
             output_dir=output_dir,
             val_response_dir=self._state_manager.val_response_dir,
             storage_backend=self._storage,
             log=self._log,
+            parallelism=self._validation_parallelism,
+            limiter=self._agents.limiter_for(profile.provider),
+            max_agent_concurrency=self._agents.concurrency_for(profile.provider),
+            agent_slots=self._agents.slots_for(profile.provider),
+            agent_budget=agent_budget,
         )
 
+        if isinstance(target, list):
+            return suite.validate_features(target)
         if target:
-            return suite.validate_feature(target)
+            feature, _ = self._project.split_target(target)
+            return suite.validate_feature(feature)
         return suite.validate_project()
 
     # ------------------------------------------------------------------
@@ -245,7 +1078,12 @@ class Builder:
     # ------------------------------------------------------------------
 
     def detect_outdated(self) -> list[str]:
-        """Walk all built targets and check if source files are newer."""
+        """Walk all built targets and check if their source files changed.
+
+        A target is outdated when its intents or validations hash differently
+        than at its last build or, for builds without a recorded hash, when a
+        source file is newer than the build.
+        """
         outdated: list[str] = []
 
         for target_name, status in self._state_manager.list_targets():
@@ -262,6 +1100,10 @@ class Builder:
                 continue
 
             node = self._project.features[target_name]
+            if result.intent_hash:
+                if self._inputs_changed(target_name):
+                    outdated.append(target_name)
+                continue
             is_outdated = False
 
             # Check .ic files
@@ -290,41 +1132,176 @@ class Builder:
 
         return outdated
 
+    # ------------------------------------------------------------------
+    # Plan
+    # ------------------------------------------------------------------
+
+    def build_plan(self, opts: BuildOptions) -> list[PlannedTarget]:
+        """Return what a build with *opts* would do, without building anything.
+
+        Each target says why it is in the plan: ``forced`` (already built,
+        rebuilt because of force), ``dependency`` (needed by a requested
+        target or tag), ``outdated``, ``changed`` (built, but its intents or
+        validations changed since), ``failed`` (its last build failed) or
+        ``pending`` (never built).
+        Raises TargetNotFoundError for an unknown target or tag,
+        DependencyCycleError for a dependency cycle and UnbuiltDependencyError
+        when ``select_deps`` leaves out a dependency that is not built.
+        """
+        build_set = self._determine_build_set(opts)
+        if opts.tag:
+            roots = set(self._project.features_with_tag(opts.tag))
+        elif opts.target:
+            roots = {self._project.split_target(opts.target)[0]}
+        else:
+            roots = None  # Everything was requested
+
+        plan: list[PlannedTarget] = []
+        for target in build_set:
+            feature = target.partition(":")[0]
+            status = self._state_manager.get_status(target)
+            if opts.force and status == TargetStatus.BUILT:
+                reason = "forced"
+            elif roots is not None and feature not in roots:
+                reason = "dependency"
+            elif status == TargetStatus.OUTDATED:
+                reason = "outdated"
+            elif status == TargetStatus.BUILT:
+                reason = "changed"
+            elif status == TargetStatus.FAILED:
+                reason = "failed"
+            else:
+                reason = "pending"
+            plan.append(
+                PlannedTarget(
+                    target=target,
+                    reason=reason,
+                    dependencies=self._project.parents(feature),
+                )
+            )
+        return plan
+
     # ------------------------------------------------------------------
     # Internal helpers
     # ------------------------------------------------------------------
 
     def _determine_build_set(self, opts: BuildOptions) -> list[str]:
         """Determine which targets to build, in topological order."""
-        topo = self._project.topological_order()
+        if opts.retry_failed:
+            selected = self._determine_build_set(
+                opts.model_copy(update={"retry_failed": False, "force": True})
+            )
+            return [
+                t
+                for t in selected
+                if self._state_manager.get_status(t) == TargetStatus.FAILED
+            ]
+
+        topo = self._weighted_order(self._project.topological_order())
         buildable_statuses = {
             TargetStatus.PENDING,
             TargetStatus.OUTDATED,
             TargetStatus.FAILED,
         }
 
-        if opts.target:
-            # Specific target: collect it and its ancestors
-            ancestors = self._project.ancestors(opts.target)
-            candidates = ancestors | {opts.target}
+        def needs_build(target: str) -> bool:
+            # Built targets whose intents or validations changed count too
+            status = self._state_manager.get_status(target)
+            if status == TargetStatus.BUILT:
+                return self._inputs_changed(target)
+            return status in buildable_statuses
+
+        if opts.target or opts.tag:
+            # Specific target, or every feature carrying a tag: collect them
+            # and the dependencies select_deps asks for. A sub-target
+            # ("feature:subtarget") stands in for its feature in the order.
+            if opts.tag:
+                roots = self._project.features_with_tag(opts.tag)
+                if not roots:
+                    raise TargetNotFoundError(
+                        f"No features are tagged '{opts.tag}'.", opts.tag
+                    )
+                feature = None
+            else:
+                feature, _ = self._project.split_target(opts.target)
+                roots = [feature]
+            candidates = set(roots)
+            for root in roots:
+                if opts.select_deps == "transitive":
+                    candidates |= self._project.ancestors(root)
+                elif opts.select_deps == "direct":
+                    candidates |= set(self._project.parents(root))
+                elif opts.select_deps != "none":
+                    raise ValueError(
+                        f"Unknown select_deps '{opts.select_deps}'. "
+                        f"Use 'transitive', 'direct' or 'none'."
+                    )
+            self._require_built_dependencies(topo, candidates)
 
-            if not opts.force:
-                candidates = {
-                    t
-                    for t in candidates
-                    if self._state_manager.get_status(t) in buildable_statuses
-                }
             # Maintain topological order
-            return [t for t in topo if t in candidates]
+            ordered = [
+                opts.target if t == feature else t
+                for t in topo
+                if t in candidates
+            ]
+            if not opts.force:
+                ordered = [t for t in ordered if needs_build(t)]
+            return ordered
         else:
-            # All targets
+            # All targets, except manual ones nothing else depends on
+            automatic = self._automatic_targets()
+            topo = [t for t in topo if t in automatic]
             if opts.force:
                 return topo
-            return [
-                t
-                for t in topo
-                if self._state_manager.get_status(t) in buildable_statuses
-            ]
+            return [t for t in topo if needs_build(t)]
+
+    def _automatic_targets(self) -> set[str]:
+        """Features a build of all targets includes.
+
+        Manual features are left out unless a feature that is not manual
+        depends on them.
+        """
+        features = self._project.features
+        selected = {f for f, node in features.items() if not node.manual}
+        for feature in list(selected):
+            selected |= self._project.ancestors(feature)
+        return selected
+
+    def _weighted_order(self, topo: list[str]) -> list[str]:
+        """Put the longest-running ready feature first, by recorded duration.
+
+        Durations are compared in whole seconds, so timing noise does not
+        reshuffle quick targets; equal weights keep the topological order.
+        """
+        durations: dict[str, float] = {}
+        for feature in topo:
+            result = self._state_manager.get_build_result(feature)
+            if result is not None and round(result.total_duration_secs) > 0:
+                durations[feature] = round(result.total_duration_secs)
+        if not durations:
+            return topo
+        deps = {feature: self._project.parents(feature) for feature in topo}
+        return weighted_order(topo, deps, durations)
+
+    def _require_built_dependencies(
+        self, topo: list[str], selected: set[str]
+    ) -> None:
+        """Raise UnbuiltDependencyError if a selected feature depends on an
+        unselected one that has never been built.
+
+        Outdated dependencies count as built: their output is still there.
+        """
+        for feature in topo:
+            if feature not in selected:
+                continue
+            for dep in self._project.parents(feature):
+                if dep in selected:
+                    continue
+                if self._state_manager.get_status(dep) not in (
+                    TargetStatus.BUILT,
+                    TargetStatus.OUTDATED,
+                ):
+                    raise UnbuiltDependencyError(feature, dep)
 
     def _resolve_profile(self, override: str) -> AgentProfile:
         """Resolve agent profile: override > builder's profile."""
@@ -332,6 +1309,55 @@ class Builder:
             return AgentProfile(name=override, provider=self._agent_profile.provider)
         return self._agent_profile
 
+    def _profile_for(
+        self, target: str, profile: AgentProfile, override: str = ""
+    ) -> AgentProfile:
+        """Return the profile to build *target* with.
+
+        The profile the target's intents name with ``agent:``, if any
+        (UnknownProfileError if it is not configured), unless *override*
+        names a profile from the command line. Otherwise *profile* unless
+        the target's intents require capabilities it lacks; then the first
+        configured profile that has them all. Raises NoCapableAgentError if
+        none does.
+        """
+        feature, _ = self._project.split_target(target)
+        node = self._project.features[feature]
+        required = node.required_capabilities
+        if node.agent and not override:
+            named = next(
+                (
+                    p
+                    for p in [self._agent_profile, *self._agent_profiles]
+                    if p.name == node.agent
+                ),
+                None,
+            )
+            if named is None:
+                raise UnknownProfileError(target, node.agent)
+            if not named.has_capabilities(required):
+                raise NoCapableAgentError(target, required)
+            return named
+        if profile.has_capabilities(required):
+            return profile
+        for candidate in self._agent_profiles:
+            if candidate.has_capabilities(required):
+                return candidate
+        raise NoCapableAgentError(target, required)
+
+    def _time_left(self) -> float | None:
+        """Seconds until the deadline, or None when there is no deadline."""
+        if self._deadline is None:
+            return None
+        return self._deadline - time.monotonic()
+
+    def _within_deadline(self, profile: AgentProfile) -> AgentProfile:
+        """Cap the profile's agent timeout at the time left before the deadline."""
+        remaining = self._time_left()
+        if remaining is None or remaining >= profile.timeout:
+            return profile
+        return profile.model_copy(update={"timeout": max(remaining, 0.0)})
+
     def _apply_sandbox_paths(
         self,
         profile: AgentProfile,
@@ -399,22 +1425,26 @@ class Builder:
         output_dir: str,
         profile_override: str,
         implementation: object | None,
+        run_validations: bool = True,
+        version_control: VersionControl | None = None,
     ) -> tuple[BuildResult, RuntimeError | None]:
-        """Build a single target through the step pipeline."""
+        """Build a single target through the step pipeline.
+
+        With *run_validations*, the target's validations run after the agent
+        builds it and an error-severity failure fails the target. The
+        checkpoint goes to *version_control* (default: the builder's own).
+        """
         steps: list[BuildStep] = []
         commit_id = ""
         git_diff = ""
-        previous_errors: list[str] = []
+        previous_errors = self._carried_over_errors(target)
         build_response: BuildResponse | None = None
 
-        profile = self._resolve_profile(profile_override)
-        node = self._project.features.get(target)
-        intent = (
-            node.intents[0]
-            if node and node.intents
-            else IntentFile(name=target, body="")
+        profile = self._profile_for(
+            target, self._resolve_profile(profile_override), profile_override
         )
-        validations = node.validations if node else []
+        feature, intent, validations, context_files = self._target_inputs(target)
+        file_changes: list[FileChange] = []
 
         retries = profile.retries or 1  # total attempts
 
@@ -423,23 +1453,62 @@ class Builder:
             failed = False
 
             if attempt > 0:
+                delay = profile.backoff(attempt)
+                remaining = self._time_left()
+                if remaining is not None:
+                    delay = min(delay, max(remaining, 0.0))
+                if delay > 0:
+                    self._log(f"  Waiting {delay:.1f}s before retrying '{target}'")
+                    self._sleep(delay)
                 self._log(
                     f"  Retry {attempt}/{retries - 1} for target '{target}'..."
                 )
+            self._emit("attempt", target=target, attempt=attempt + 1, max_attempts=retries)
+            self._attempts[target] = attempt + 1
 
             # Step 1: resolve_deps
-            dep_step, dep_names = self._step_resolve_deps(target)
+            dep_step, dep_names = self._step_resolve_deps(feature)
             steps_this_attempt.append(dep_step)
 
+            if self._cancelled.is_set():
+                # Another target failed; do not start another agent run
+                self._log("  build: cancelled (another target failed)")
+                steps = steps_this_attempt + [
+                    BuildStep(
+                        phase="build",
+                        status="failed",
+                        summary="Cancelled: another target failed",
+                    )
+                ]
+                return self._make_result(
+                    target, generation_id, "failed", steps, commit_id, git_diff
+                ), RuntimeError(f"Build failed for target '{target}': cancelled")
+
+            remaining = self._time_left()
+            if remaining is not None and remaining <= 0:
+                # Out of time: fail now rather than start another agent run
+                self._log("  build: timed out (deadline reached)")
+                steps = steps_this_attempt + [
+                    BuildStep(
+                        phase="build",
+                        status="failed",
+                        summary="Timed out: the overall deadline was reached",
+                    )
+                ]
+                return self._make_result(
+                    target, generation_id, "failed", steps, commit_id, git_diff
+                ), RuntimeError(f"Build failed for target '{target}': timed out")
+
             # Step 2: build
             sandboxed_profile = self._apply_sandbox_paths(
-                profile, target, output_dir
+                self._within_deadline(profile), feature, output_dir
             )
             agent = self._create_agent(sandboxed_profile)
+            writer = self._file_writer(agent, output_dir)
 
             response_file = str(
                 self._state_manager.build_response_dir
-                / f"response-{target.replace('/', '_')}-{generation_id[:8]}.json"
+                / f"response-{target_slug(target)}-{generation_id[:8]}.json"
             )
 
             build_ctx = BuildContext(
@@ -452,10 +1521,18 @@ class Builder:
                 implementation=implementation,
                 response_file_path=response_file,
                 previous_errors=previous_errors,
+                context_files=context_files,
+                output_file_path=str(
+                    self._state_manager.output_log_path(target, generation_id)
+                ),
+                file_writer=writer,
             )
 
-            build_step, build_response = self._step_build(agent, build_ctx)
+            build_step, build_response = self._step_build(
+                agent, build_ctx, sandboxed_profile.provider
+            )
             steps_this_attempt.append(build_step)
+            file_changes = self._writer_changes(writer, build_response, target, output_dir)
 
             if build_step.status != "success":
                 previous_errors.append(build_step.summary)
@@ -471,9 +1548,9 @@ class Builder:
                 )
 
             # Step 3: validate
-            if validations:
+            if validations and run_validations:
                 val_step = self._step_validate(
-                    target, profile, output_dir
+                    feature, profile, output_dir, generation_id
                 )
                 steps_this_attempt.append(val_step)
 
@@ -494,22 +1571,242 @@ class Builder:
             steps = steps_this_attempt
 
             # Step 4: checkpoint
-            ckpt_step, commit_id, git_diff = self._step_checkpoint(
-                target, generation_id
-            )
+            with self._checkpoint_lock:
+                ckpt_step, commit_id, git_diff = self._step_checkpoint(
+                    target,
+                    generation_id,
+                    version_control or self._version_control,
+                    self._checkpoint_paths(build_response, file_changes, output_dir),
+                )
             steps.append(ckpt_step)
+            # Output outside the repository (or a failed checkpoint) has no
+            # git diff; fall back to what the file writer saw
+            git_diff = git_diff or "".join(c.diff for c in file_changes)
             break
 
         result, _ = self._make_result(
             target, generation_id, "built", steps, commit_id, git_diff
         ), None
 
+        if build_response:
+            result.outputs = dict(build_response.outputs)
+
         # Store file manifest from build response
         result._build_response = build_response  # type: ignore[attr-defined]
         result._git_diff = git_diff  # type: ignore[attr-defined]
+        result._file_changes = file_changes  # type: ignore[attr-defined]
 
         return result, None
 
+    def _target_inputs(
+        self, target: str
+    ) -> tuple[str, IntentFile, list[ValidationFile], dict[str, str]]:
+        """Return the feature, intent, validations and context files for *target*."""
+        feature, _, subtarget = target.partition(":")
+        node = self._project.features.get(feature)
+        intent = (
+            node.intents[0]
+            if node and node.intents
+            else IntentFile(name=target, body="")
+        )
+        if node and subtarget:
+            # Sub-targets are prompted with only their own section content
+            intent = node.subtarget_intent(subtarget) or intent
+        if node and "{{" in intent.body:
+            intent = intent.model_copy(
+                update={"body": self._resolve_outputs(feature, intent.body)}
+            )
+        validations = node.validations if node else []
+        return feature, intent, validations, self._load_context(intent, feature)
+
+    def _resolve_outputs(self, feature: str, body: str) -> str:
+        """Interpolate the outputs of *feature*'s dependencies into *body*."""
+        ancestors = self._project.ancestors(feature)
+
+        def outputs_for(dep: str) -> dict[str, str] | None:
+            if dep not in ancestors:
+                return None
+            result = self._state_manager.get_build_result(dep)
+            return result.outputs if result else {}
+
+        return resolve_dependency_outputs(body, outputs_for, self._log)
+
+    def _file_writer(self, agent: Agent, output_dir: str) -> FileWriter:
+        """Pick the writer for a real build of *agent* into *output_dir*."""
+        root = Path(output_dir or ".")
+        if agent.uses_file_writer:
+            return DiskWriter(root)
+        # Opaque agents write for themselves; snapshots reveal what they did
+        return SnapshotWriter(root, skip=self._non_output_dirs(output_dir))
+
+    def _writer_changes(
+        self,
+        writer: FileWriter,
+        response: BuildResponse | None,
+        target: str,
+        output_dir: str,
+    ) -> list[FileChange]:
+        """What a build of *target* changed, checking only the agent's files
+        when it listed any.
+
+        A snapshot of a parallel build also sees the targets building
+        alongside; files another target has claimed are left out.
+        """
+        listed = [*response.files_created, *response.files_modified] if response else []
+        if listed and isinstance(writer, SnapshotWriter):
+            return writer.changes(listed)
+        changes = writer.changes()
+        if not (self._parallel and isinstance(writer, SnapshotWriter)):
+            return changes
+        ownership = self._state_manager.ownership
+        with self._ownership_lock:
+            return [
+                c
+                for c in changes
+                if ownership.owner(self._output_path(output_dir, c.path)) in (None, target)
+            ]
+
+    def _checkpoint_paths(
+        self,
+        response: BuildResponse | None,
+        file_changes: list[FileChange],
+        output_dir: str,
+    ) -> list[str] | None:
+        """The paths a parallel build's checkpoint commits: the target's own.
+
+        None (commit everything) when targets build one at a time.
+        """
+        if not self._parallel:
+            return None
+        listed = [*response.files_created, *response.files_modified] if response else []
+        rels = listed or [c.path for c in file_changes]
+        return [self._output_path(output_dir, rel) for rel in rels]
+
+    @staticmethod
+    def _output_path(output_dir: str, rel: str) -> str:
+        """*rel* (relative to the output directory) as the project records it."""
+        return (Path(output_dir) / rel).as_posix() if output_dir else rel
+
+    def _non_output_dirs(self, output_dir: str) -> list[str]:
+        """Directories under *output_dir* that hold no build output.
+
+        Only a build into the project root has any: the intent directory
+        and the other output directories are left out of its snapshots.
+        """
+        if output_dir not in ("", "."):
+            return []
+        base = self._state_manager.base_dir
+        dirs = {d for d in recorded_output_dirs(base) if d not in ("", ".")}
+        source = self._project.project_intent.source_path
+        if source is not None:
+            try:
+                dirs.add(source.parent.resolve().relative_to(base.resolve()).as_posix())
+            except ValueError:
+                pass
+        return sorted(dirs)
+
+    def _preview(self, build_set: list[str], opts: BuildOptions) -> list[BuildResult]:
+        """Run the agents for *build_set* without touching the output directory.
+
+        Agents that write through the file writer keep their output in memory,
+        each target seeing the previous targets' pending files. Other agents
+        run against a scratch copy of the output directory. Each result carries
+        the would-be changes as ``_file_changes``; nothing is validated,
+        checkpointed or recorded.
+        """
+        implementation = self._project.resolve_implementation(opts.implementation or None)
+        profile = self._resolve_profile(opts.profile_override)
+        output_root = Path(opts.output_dir or ".")
+        pending: dict[str, str | None] = {}
+        results: list[BuildResult] = []
+
+        with tempfile.TemporaryDirectory(prefix="intentc-preview-") as tmp:
+            scratch = Path(tmp) / "output"
+            skip = self._non_output_dirs(opts.output_dir)
+
+            def ignore(directory: str, names: list[str]) -> set[str]:
+                rel = Path(directory).relative_to(output_root)
+                return {n for n in names if is_skipped((rel / n).as_posix(), skip)}
+
+            if output_root.is_dir():
+                shutil.copytree(output_root, scratch, ignore=ignore)
+            else:
+                scratch.mkdir()
+
+            for idx, target in enumerate(build_set):
+                self._log(f"[{idx + 1}/{len(build_set)}] Previewing target '{target}'...")
+                feature, intent, validations, context_files = self._target_inputs(target)
+                target_profile = self._profile_for(target, profile, opts.profile_override)
+                agent = self._create_agent(
+                    self._apply_sandbox_paths(target_profile, feature, str(scratch))
+                )
+                writer: FileWriter
+                if agent.uses_file_writer:
+                    writer = MemoryWriter(output_root, overlay=pending)
+                    agent_dir = output_root
+                else:
+                    writer = SnapshotWriter(scratch)
+                    agent_dir = scratch
+
+                _, dep_names = self._step_resolve_deps(feature)
+                ctx = BuildContext(
+                    intent=intent,
+                    validations=validations,
+                    output_dir=str(agent_dir),
+                    generation_id="preview",
+                    dependency_names=dep_names,
+                    project_intent=self._project.project_intent,
+                    implementation=implementation,
+                    response_file_path=str(Path(tmp) / f"response-{target_slug(target)}.json"),
+                    context_files=context_files,
+                    file_writer=writer,
+                )
+                build_step, _ = self._step_build(agent, ctx, target_profile.provider)
+
+                status = self._state_manager.get_status(target).value
+                result = BuildResult(
+                    target=target,
+                    status="failed" if build_step.status != "success" else status,
+                    total_duration_secs=build_step.duration_secs,
+                    steps=[build_step],
+                )
+                result._file_changes = writer.changes()  # type: ignore[attr-defined]
+                if isinstance(writer, MemoryWriter):
+                    pending = writer.files
+                results.append(result)
+
+        return results
+
+    def _carried_over_errors(self, target: str) -> list[str]:
+        """Errors from the target's last failed build, so a new run can learn from them."""
+        failures = self._state_manager.recent_failures(target)
+        if not failures:
+            return []
+        last_error = next(
+            (s.summary for s in reversed(failures[0].steps) if s.status != "success"),
+            "",
+        )
+        self._log(
+            f"  Target '{target}' has failed {len(failures)} time(s) in a row"
+            + (f"; last error: {last_error}" if last_error else "")
+        )
+        return [last_error] if last_error else []
+
+    def _context_dir(self, intent: IntentFile, feature: str) -> Path:
+        """The directory the intent's context files are relative to: its .ic file's."""
+        if intent.source_path is not None:
+            return intent.source_path.parent
+        if self._project.intent_dir is not None:
+            return self._project.intent_dir / feature
+        return Path.cwd()
+
+    def _load_context(self, intent: IntentFile, feature: str) -> dict[str, str]:
+        """Load the intent's context files, resolved next to its .ic file."""
+        if not intent.context:
+            return {}
+        base_dir = self._context_dir(intent, feature)
+        return load_context_files(intent.context, base_dir, log=self._log)
+
     def _step_resolve_deps(
         self, target: str
     ) -> tuple[BuildStep, list[str]]:
@@ -533,14 +1830,15 @@ class Builder:
         )
 
     def _step_build(
-        self, agent: Agent, ctx: BuildContext
+        self, agent: Agent, ctx: BuildContext, provider: str
     ) -> tuple[BuildStep, BuildResponse | None]:
-        """Invoke the agent to build."""
+        """Invoke the agent to build, within *provider*'s concurrency cap."""
         start = datetime.now()
         self._log(f"  build: invoking agent...")
 
         try:
-            response = agent.build(ctx)
+            with self._agents.slots_for(provider) or contextlib.nullcontext():
+                response = agent.build(ctx)
             duration = (datetime.now() - start).total_seconds()
 
             if response.status == "success":
@@ -581,18 +1879,24 @@ class Builder:
         target: str,
         profile: AgentProfile,
         output_dir: str,
+        generation_id: str = "",
     ) -> BuildStep:
-        """Run validations for a target."""
+        """Run validations for a target, filing results under *generation_id*."""
         start = datetime.now()
         self._log(f"  validate: running validations...")
 
         suite = ValidationSuite(
             project=self._project,
-            agent_profile=profile,
+            agent_profile=self._within_deadline(profile),
             output_dir=output_dir,
             val_response_dir=self._state_manager.val_response_dir,
             storage_backend=self._storage,
             log=self._log,
+            parallelism=self._validation_parallelism,
+            limiter=self._agents.limiter_for(profile.provider),
+            max_agent_concurrency=self._agents.concurrency_for(profile.provider),
+            agent_slots=self._agents.slots_for(profile.provider),
+            generation_id=generation_id,
         )
         result = suite.validate_feature(target)
         duration = (datetime.now() - start).total_seconds()
@@ -615,18 +1919,22 @@ class Builder:
             )
 
     def _step_checkpoint(
-        self, target: str, generation_id: str
+        self,
+        target: str,
+        generation_id: str,
+        version_control: VersionControl,
+        paths: list[str] | None = None,
     ) -> tuple[BuildStep, str, str]:
-        """Checkpoint via version control."""
+        """Checkpoint via version control, committing only *paths* if given."""
         start = datetime.now()
         message = f"build {target} [gen:{generation_id}]"
         self._log(f"  checkpoint: committing '{message}'")
 
         try:
-            commit_id = self._version_control.checkpoint(message)
+            commit_id = version_control.checkpoint(message, paths)
             git_diff = ""
             try:
-                git_diff = self._version_control.diff(
+                git_diff = version_control.diff(
                     f"{commit_id}~1", commit_id
                 )
             except Exception:
@@ -680,6 +1988,90 @@ class Builder:
             steps=steps,
         )
 
+    def _emit(self, event_type: str, **fields: object) -> None:
+        """Write to the event stream, if one is attached."""
+        if self._events is not None:
+            self._events.emit(event_type, **fields)
+
+    @staticmethod
+    def _generated_files(result: BuildResult) -> tuple[list[str], list[str]]:
+        """Files the target created and modified.
+
+        The agent's build response is trusted when it lists any files;
+        otherwise the changes seen by the file writer are used.
+        """
+        build_response: BuildResponse | None = getattr(
+            result, "_build_response", None
+        )
+        if build_response and (build_response.files_created or build_response.files_modified):
+            return build_response.files_created, build_response.files_modified
+        changes: list[FileChange] = getattr(result, "_file_changes", [])
+        return (
+            [c.path for c in changes if c.change == "created"],
+            [c.path for c in changes if c.change == "modified"],
+        )
+
+    @staticmethod
+    def _file_sources(result: BuildResult) -> dict[str, str]:
+        """How each generated file was found: ``"agent"`` if the build
+        response listed it, else ``"writer"``."""
+        build_response: BuildResponse | None = getattr(
+            result, "_build_response", None
+        )
+        listed = set()
+        if build_response:
+            listed = {*build_response.files_created, *build_response.files_modified}
+        created, modified = Builder._generated_files(result)
+        return {
+            path: "agent" if path in listed else "writer"
+            for path in created + modified
+        }
+
+    def _file_hashes(self, result: BuildResult, output_dir: str) -> dict[str, str]:
+        """SHA-256 of each file the target generated that is on disk."""
+        created, modified = self._generated_files(result)
+        hashes: dict[str, str] = {}
+        for rel in created + modified:
+            path = Path(output_dir or ".") / rel
+            if path.is_file():
+                hashes[rel] = hashlib.sha256(path.read_bytes()).hexdigest()
+        return hashes
+
+    def _emit_generated_files(self, target: str, result: BuildResult) -> None:
+        """Emit a ``file_generated`` event per file the target generated."""
+        created, modified = self._generated_files(result)
+        for path in created:
+            self._emit("file_generated", target=target, path=path, change="created")
+        for path in modified:
+            self._emit("file_generated", target=target, path=path, change="modified")
+
+    def _record_ownership(
+        self,
+        target: str,
+        result: BuildResult,
+        generation_id: str,
+        output_dir: str,
+    ) -> None:
+        """Claim the target's generated files, warning on cross-target overlap."""
+        created, modified = self._generated_files(result)
+        files = created + modified
+        if not files:
+            return
+
+        ownership = self._state_manager.ownership
+        with self._ownership_lock:
+            for rel in files:
+                path = self._output_path(output_dir, rel)
+                previous = ownership.claim(
+                    path, target, generation_id, source=result.file_sources.get(rel, "")
+                )
+                if previous is not None:
+                    self._log(
+                        f"  Warning: '{path}' was generated by '{previous}' "
+                        f"and is now claimed by '{target}'"
+                    )
+            ownership.save()
+
     def _save_and_cleanup_response(
         self,
         target: str,
@@ -687,19 +2079,11 @@ class Builder:
         generation_id: str,
     ) -> None:
         """Read the build agent response file, store it, then delete."""
-        build_response: BuildResponse | None = getattr(
-            result, "_build_response", None
-        )
         git_diff: str = getattr(result, "_git_diff", "")
-
-        files_created: list[str] = []
-        files_modified: list[str] = []
-        if build_response:
-            files_created = build_response.files_created
-            files_modified = build_response.files_modified
+        files_created, files_modified = self._generated_files(result)
 
         # Save build result with extra metadata
-        self._storage.save_build_result(
+        build_result_id = self._storage.save_build_result(
             target,
             result,
             git_diff=git_diff,
@@ -710,14 +2094,14 @@ class Builder:
         # Read response file from disk, persist, and clean up
         response_file = (
             self._state_manager.build_response_dir
-            / f"response-{target.replace('/', '_')}-{generation_id[:8]}.json"
+            / f"response-{target_slug(target)}-{generation_id[:8]}.json"
         )
         if response_file.exists():
             try:
                 with open(response_file, "r", encoding="utf-8") as f:
                     response_json = json.load(f)
                 self._storage.save_agent_response(
-                    build_result_id=None,
+                    build_result_id=build_result_id,
                     validation_result_id=None,
                     response_type="build",
                     response_json=response_json,
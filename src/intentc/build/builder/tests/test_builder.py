@@ -4,6 +4,8 @@ from __future__ import annotations
 
 import os
 import tempfile
+import threading
+import time
 from datetime import datetime, timedelta
 from pathlib import Path
 from unittest.mock import MagicMock
@@ -12,13 +14,25 @@ import pytest
 
 from intentc.build.agents import (
     AgentError,
+    AgentFactory,
     AgentProfile,
     BuildContext,
     BuildResponse,
     MockAgent,
+    NoCapableAgentError,
+    UnknownProfileError,
     ValidationResponse,
 )
-from intentc.build.builder.builder import Builder, BuildOptions
+from intentc.build.builder.builder import (
+    Builder,
+    BuildOptions,
+    BuildReport,
+    load_context_files,
+    resolve_dependency_outputs,
+)
+from intentc.build.events import EventLog
+from intentc.build.state.lock import BuildLock
+from intentc.build.state.ownership import OwnershipIndex
 from intentc.build.state.state import StateManager, VersionControl
 from intentc.build.storage.backend import (
     BuildResult,
@@ -28,7 +42,7 @@ from intentc.build.storage.backend import (
     TargetStatus,
 )
 from intentc.build.validations import ValidationSuiteResult
-from intentc.core.models import IntentFile, ProjectIntent, ValidationFile, Validation, ValidationType, Severity
+from intentc.core.models import IntentFile, ProjectIntent, UnbuiltDependencyError, ValidationFile, Validation, ValidationType, Severity
 from intentc.core.project import FeatureNode, Project
 
 
@@ -42,13 +56,15 @@ class FakeVersionControl(VersionControl):
 
     def __init__(self) -> None:
         self.checkpoints: list[tuple[str, str]] = []  # (message, commit_id)
+        self.checkpoint_paths: list[list[str] | None] = []
         self.restores: list[str] = []
         self._counter = 0
 
-    def checkpoint(self, message: str) -> str:
+    def checkpoint(self, message: str, paths: list[str] | None = None) -> str:
         self._counter += 1
         commit_id = f"fake-commit-{self._counter:04d}"
         self.checkpoints.append((message, commit_id))
+        self.checkpoint_paths.append(paths)
         return commit_id
 
     def diff(self, from_id: str, to_id: str) -> str:
@@ -116,7 +132,7 @@ class FakeStorageBackend(StorageBackend):
 
     def save_validation_result(self, build_result_id, generation_id, target,
                                 validation_file_version_id, name, type, severity,
-                                status, reason="", duration_secs=None):
+                                status, reason="", duration_secs=None, run_id=""):
         return 1
 
     def save_agent_response(self, build_result_id, validation_result_id,
@@ -140,6 +156,12 @@ class FakeStorageBackend(StorageBackend):
         self._statuses.clear()
         self._results.clear()
 
+    def delete_build_results(self, target):
+        kept = [(t, r) for t, r in self._saved_results if t != target]
+        deleted = len(self._saved_results) - len(kept)
+        self._saved_results = kept
+        return deleted
+
 
 def _make_project(
     features: dict[str, list[str]] | None = None,
@@ -210,6 +232,19 @@ def _make_builder(
     return builder, agent, storage_backend, version_control
 
 
+def _make_api_gateway_project() -> Project:
+    """Project with an api_gateway feature split into two sub-targets."""
+    project = _make_project(features={"core": [], "api_gateway": ["core"]})
+    project.features["api_gateway"].intents[0] = IntentFile(
+        name="api_gateway",
+        depends_on=["core"],
+        body="# API Gateway\n\n## Target: rest-api\nREST endpoints\n\n"
+        "## Target: graphql-api\nGraphQL schema",
+        targets={"rest-api": "REST endpoints", "graphql-api": "GraphQL schema"},
+    )
+    return project
+
+
 # ---------------------------------------------------------------------------
 # Tests: Build pipeline
 # ---------------------------------------------------------------------------
@@ -298,6 +333,7 @@ class TestBuildPipeline:
         targets_built = [r.target for r in results]
         assert "api" in targets_built
         assert "core" not in targets_built
+        assert builder.skipped == ["core"]
 
     def test_build_force_rebuilds_built(self):
         """Force flag rebuilds already-built targets."""
@@ -316,6 +352,93 @@ class TestBuildPipeline:
         assert len(results) == 1
         assert results[0].target == "core"
 
+    def test_build_cyclic_dependency_reports_path(self):
+        """A dependency cycle aborts the build with the full cycle path."""
+        project = _make_project(features={
+            "feature1": ["feature2"],
+            "feature2": ["feature1"],
+        })
+        builder, agent, storage, vc = _make_builder(project=project)
+
+        results, error = builder.build(BuildOptions(output_dir="/tmp/out"))
+
+        assert results == []
+        assert error is not None
+        assert str(error).startswith("Dependency cycle detected")
+        assert "feature1 -> feature2 -> feature1" in str(error)
+        assert len(agent.build_calls) == 0
+
+    def test_build_subtarget_uses_section_content(self):
+        """A feature:subtarget build prompts with only that section's content."""
+        builder, agent, storage, vc = _make_builder(project=_make_api_gateway_project())
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(
+                BuildOptions(target="api_gateway:rest-api", output_dir=out_dir)
+            )
+
+        assert error is None
+        assert [r.target for r in results] == ["core", "api_gateway:rest-api"]
+        assert agent.build_calls[-1].intent.body == "REST endpoints"
+        assert agent.build_calls[-1].dependency_names == ["core"]
+
+    def test_build_subtargets_tracked_independently(self):
+        """Each sub-target has its own build state."""
+        builder, agent, storage, vc = _make_builder(project=_make_api_gateway_project())
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            builder.build(BuildOptions(target="api_gateway:rest-api", output_dir=out_dir))
+            results, error = builder.build(
+                BuildOptions(target="api_gateway:graphql-api", output_dir=out_dir)
+            )
+
+        assert error is None
+        assert [r.target for r in results] == ["api_gateway:graphql-api"]
+        assert agent.build_calls[-1].intent.body == "GraphQL schema"
+        assert storage.get_status("api_gateway:rest-api") == TargetStatus.BUILT
+        assert storage.get_status("api_gateway:graphql-api") == TargetStatus.BUILT
+        assert storage.get_status("api_gateway") == TargetStatus.PENDING
+
+    def test_build_unknown_subtarget_raises(self):
+        builder, agent, storage, vc = _make_builder(project=_make_api_gateway_project())
+
+        with pytest.raises(KeyError, match="Sub-target 'soap-api' not found"):
+            builder.build(BuildOptions(target="api_gateway:soap-api", output_dir="/tmp/out"))
+
+    def test_build_refuses_on_low_disk_space(self, monkeypatch):
+        """A build is refused when free space is below min_free_mb."""
+        monkeypatch.setattr(
+            "intentc.build.builder.builder.free_disk_mb", lambda _path: 10.0
+        )
+        builder, agent, storage, vc = _make_builder()
+
+        results, error = builder.build(
+            BuildOptions(output_dir="/tmp/out", min_free_mb=100)
+        )
+
+        assert results == []
+        assert error is not None
+        assert "Low disk space" in str(error)
+        assert len(agent.build_calls) == 0
+
+    def test_build_low_disk_space_ignored(self, monkeypatch):
+        """With ignore_disk_space, low disk space only warns."""
+        monkeypatch.setattr(
+            "intentc.build.builder.builder.free_disk_mb", lambda _path: 10.0
+        )
+        log: list[str] = []
+        builder, agent, storage, vc = _make_builder()
+        builder._log = log.append
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(
+                BuildOptions(output_dir=out_dir, min_free_mb=100, ignore_disk_space=True)
+            )
+
+        assert error is None
+        assert len(agent.build_calls) == 2
+        assert any("Warning: Low disk space" in m for m in log)
+
     def test_build_dry_run(self):
         """Dry run returns build plan without side effects."""
         project = _make_project(features={"core": [], "api": ["core"]})
@@ -330,6 +453,28 @@ class TestBuildPipeline:
         assert len(agent.build_calls) == 0
         assert len(vc.checkpoints) == 0
 
+    def test_unbuilt_targets_in_stable_dependency_order(self):
+        """Dependencies precede dependents, and the order is the same every run."""
+        # Declared dependents-first, with independent targets in between
+        features = {
+            "app": ["api", "web"], "docs": [], "web": ["core"],
+            "api": ["core"], "core": [], "cli": ["core"],
+        }
+        builder, agent, storage, vc = _make_builder(project=_make_project(features=features))
+        storage.set_status("cli", TargetStatus.BUILT)
+
+        orders = [
+            [r.target for r in builder.build(BuildOptions(dry_run=True))[0]]
+            for _ in range(3)
+        ]
+
+        order = orders[0]
+        assert orders == [order] * 3
+        assert sorted(order) == ["api", "app", "core", "docs", "web"]
+        for target in order:
+            for dep in features[target]:
+                assert order.index(dep) < order.index(target)
+
     def test_build_generation_id_shared(self):
         """All targets in a build share the same generation ID."""
         project = _make_project(features={"core": [], "api": ["core"]})
@@ -405,6 +550,19 @@ class TestBuildPipeline:
         expected = sum(s.duration_secs for s in result.steps)
         assert abs(result.total_duration_secs - expected) < 0.001
 
+    def test_build_sets_output_file_path(self, tmp_path):
+        builder, agent, storage, vc = _make_builder()
+
+        results, _ = builder.build(
+            BuildOptions(target="core", output_dir=str(tmp_path / "out"))
+        )
+
+        expected = builder._state_manager.output_log_path(
+            "core", results[0].generation_id
+        )
+        assert agent.build_calls[0].output_file_path == str(expected)
+        assert expected.name == f"{results[0].generation_id}.output.txt"
+
 
 # ---------------------------------------------------------------------------
 # Tests: Build failure and retries
@@ -516,6 +674,85 @@ class TestBuildFailure:
         assert results[0].status == "built"
         assert call_count == 3
 
+    def test_retries_wait_with_backoff(self):
+        """Waits between attempts grow by backoff_multiplier up to max_backoff."""
+        project = _make_project(features={"core": []})
+        failing_agent = MockAgent(
+            build_response=BuildResponse(status="failure", summary="fail")
+        )
+        builder, _, storage, vc = _make_builder(project=project, mock_agent=failing_agent)
+        builder._agent_profile = AgentProfile(
+            name="test",
+            provider="cli",
+            retries=4,
+            retry_delay=1.0,
+            backoff_multiplier=2.0,
+            max_backoff=3.0,
+        )
+        waits: list[float] = []
+        builder._sleep = waits.append
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir))
+
+        assert error is not None
+        assert waits == [1.0, 2.0, 3.0]
+
+    def test_cancel_cuts_backoff_short(self):
+        """A failure elsewhere in the build ends a retry wait early."""
+        project = _make_project(features={"core": []})
+        failing_agent = MockAgent(
+            build_response=BuildResponse(status="failure", summary="fail")
+        )
+        builder, _, storage, vc = _make_builder(project=project, mock_agent=failing_agent)
+        builder._agent_profile = AgentProfile(
+            name="test", provider="cli", retries=2, retry_delay=60.0
+        )
+        failing_build = failing_agent.build
+
+        def build_then_cancel(ctx):
+            builder._cancelled.set()
+            return failing_build(ctx)
+
+        failing_agent.build = build_then_cancel
+
+        started = time.monotonic()
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir))
+
+        assert time.monotonic() - started < 10
+        assert "cancelled" in str(error)
+        assert len(failing_agent.build_calls) == 1
+
+    def test_stats_count_attempts_and_skips(self):
+        """stats() reports outcomes, durations and retries of the last build."""
+        project = _make_project(features={"core": [], "api": ["core"]})
+        attempts = {"api": 0}
+
+        class FlakyApiAgent(MockAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                if ctx.intent.name == "api":
+                    attempts["api"] += 1
+                    if attempts["api"] == 1:
+                        raise AgentError("Transient failure")
+                return super().build(ctx)
+
+        builder, agent, storage, vc = _make_builder(
+            project=project, mock_agent=FlakyApiAgent()
+        )
+        builder._agent_profile = AgentProfile(name="test", provider="cli", retries=2)
+        storage.set_status("core", TargetStatus.BUILT)
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir))
+        stats = builder.stats(results)
+
+        assert error is None
+        assert (stats.total_targets, stats.built, stats.skipped, stats.failed) == (2, 1, 1, 0)
+        assert (stats.attempts, stats.retries) == (2, 1)
+        assert [(t.target, t.attempts) for t in stats.targets] == [("api", 2)]
+        assert stats.total_duration_secs == results[0].total_duration_secs
+
     def test_build_error_returned_not_raised(self):
         """Build errors are returned, not raised."""
         project = _make_project(features={"core": []})
@@ -583,6 +820,16 @@ class TestClean:
 
         assert storage.get_status("core") == TargetStatus.PENDING
 
+    def test_clean_deletes_recorded_build_results(self):
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, _, storage, vc = _make_builder(project=project)
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            builder.build(BuildOptions(output_dir=out_dir))
+            builder.clean("core", out_dir)
+
+        assert {t for t, _ in storage._saved_results} == {"api"}
+
     def test_clean_restores_via_version_control(self):
         """Clean calls version_control.restore with the commit_id."""
         project = _make_project(features={"core": []})
@@ -634,158 +881,1575 @@ class TestClean:
         assert len(storage._statuses) == 0
         assert len(vc.restores) == 0  # No file modifications
 
+    def test_clean_unknown_target_suggests(self):
+        """Cleaning a mistyped target raises with a suggestion."""
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, _, storage, vc = _make_builder(project=project)
 
-# ---------------------------------------------------------------------------
-# Tests: Validate
-# ---------------------------------------------------------------------------
+        with pytest.raises(KeyError, match="Did you mean: core"):
+            builder.clean("cor", "/tmp/out")
 
+    def test_clean_removes_only_owned_files(self, tmp_path):
+        """Files recorded for another target survive cleaning."""
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        out_dir = tmp_path / "out"
+        out_dir.mkdir()
+        (out_dir / "core.py").write_text("core")
+        (out_dir / "api.py").write_text("api")
+
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["core.py"]
+        )
+        builder.build(BuildOptions(target="core", output_dir=str(out_dir)))
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["api.py"]
+        )
+        builder.build(BuildOptions(target="api", output_dir=str(out_dir)))
 
-class TestValidate:
-    """Tests for the validate() method."""
+        builder.clean("core", str(out_dir))
 
-    def test_validate_returns_result(self):
-        """Validate returns a ValidationSuiteResult without modifying state."""
-        project = _make_project(features={"core": []}, with_validations=True)
-        builder, _, storage, vc = _make_builder(project=project)
+        assert not (out_dir / "core.py").exists()
+        assert (out_dir / "api.py").exists()
+        assert vc.restores == []
+        assert builder._state_manager.ownership.files_for("core") == []
 
-        result = builder.validate("core", "/tmp/out")
+    def test_clean_cascade_removes_dependents_files(self, tmp_path):
+        """Cascade cleaning the root of a diamond removes every downstream file."""
+        project = _make_project(
+            features={"core": [], "api": ["core"], "web": ["core"], "app": ["api", "web"]}
+        )
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        out_dir = tmp_path / "out"
+        out_dir.mkdir()
+        for name in ("core", "api", "web", "app"):
+            (out_dir / f"{name}.py").write_text(name)
+            agent._build_response = BuildResponse(
+                status="success", summary="ok", files_created=[f"{name}.py"]
+            )
+            builder.build(BuildOptions(target=name, output_dir=str(out_dir)))
 
-        assert isinstance(result, ValidationSuiteResult)
-        assert result.target == "core"
+        builder.clean("core", str(out_dir), cascade=True)
 
-    def test_validate_does_not_modify_state(self):
-        """Validate does not save build results or change target status."""
-        project = _make_project(features={"core": []}, with_validations=True)
-        builder, _, storage, vc = _make_builder(project=project)
+        assert list(out_dir.iterdir()) == []
+        for name in ("core", "api", "web", "app"):
+            assert storage.get_status(name) == TargetStatus.PENDING
+
+    def test_clean_without_cascade_keeps_dependents_files(self, tmp_path):
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        out_dir = tmp_path / "out"
+        out_dir.mkdir()
+        for name in ("core", "api"):
+            (out_dir / f"{name}.py").write_text(name)
+            agent._build_response = BuildResponse(
+                status="success", summary="ok", files_created=[f"{name}.py"]
+            )
+            builder.build(BuildOptions(target=name, output_dir=str(out_dir)))
+
+        builder.clean("core", str(out_dir))
+
+        assert [p.name for p in out_dir.iterdir()] == ["api.py"]
+        assert storage.get_status("api") == TargetStatus.OUTDATED
 
+    def test_clean_scoped_to_output_dir(self, tmp_path):
+        """Cleaning a target in one output dir leaves siblings and other dirs alone."""
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+        ownership = OwnershipIndex(tmp_path / "ownership.json")
+        builder._state_manager._ownership = ownership
+        staging, prod = tmp_path / "staging", tmp_path / "prod"
+        for out_dir in (staging, prod):
+            out_dir.mkdir()
+            (out_dir / "core.py").write_text("core")
+            (out_dir / "api.py").write_text("api")
+            ownership.claim(f"{out_dir.as_posix()}/core.py", "core", "g1")
+            ownership.claim(f"{out_dir.as_posix()}/api.py", "api", "g1")
         storage.set_status("core", TargetStatus.BUILT)
-        builder.validate("core", "/tmp/out")
+        storage.set_status("api", TargetStatus.BUILT)
+        storage._results["core"] = BuildResult(target="core", status="built")
 
-        assert storage.get_status("core") == TargetStatus.BUILT
-        assert len(vc.checkpoints) == 0
+        builder.clean("core", str(staging))
+
+        assert not (staging / "core.py").exists()
+        assert (staging / "api.py").exists()
+        assert (prod / "core.py").exists()
+        assert ownership.files_for("core") == [f"{prod.as_posix()}/core.py"]
+        assert storage.get_status("core") == TargetStatus.PENDING
+        assert storage.get_status("api") == TargetStatus.OUTDATED
+
+
+class TestResolveTargets:
+    """Tests for expanding clean target names and patterns."""
+
+    def _builder(self):
+        project = _make_project(
+            features={"core/a": [], "core/b": ["core/a"], "api": ["core/b"]}
+        )
+        return _make_builder(project=project)
+
+    def test_wildcard_matches_in_topological_order(self):
+        builder, _, storage, vc = self._builder()
+        assert builder.resolve_targets(["core/*"]) == ["core/a", "core/b"]
+
+    def test_duplicates_removed(self):
+        builder, _, storage, vc = self._builder()
+        assert builder.resolve_targets(["api", "core/*", "core/b"]) == [
+            "core/a",
+            "core/b",
+            "api",
+        ]
+
+    def test_pattern_matches_recorded_subtargets(self):
+        builder, _, storage, vc = self._builder()
+        storage.set_status("api:rest", TargetStatus.BUILT)
+        assert builder.resolve_targets(["api*"]) == ["api", "api:rest"]
+
+    def test_unmatched_pattern_raises(self):
+        builder, _, storage, vc = self._builder()
+        with pytest.raises(KeyError, match="matched no targets"):
+            builder.resolve_targets(["web/*"])
+
+    def test_clean_wildcard_cleans_each_target(self, tmp_path):
+        builder, agent, storage, vc = self._builder()
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        out_dir = tmp_path / "out"
+        out_dir.mkdir()
+        for name in ("a", "b", "api"):
+            (out_dir / f"{name}.py").write_text(name)
+        for target, file in (("core/a", "a.py"), ("core/b", "b.py"), ("api", "api.py")):
+            agent._build_response = BuildResponse(
+                status="success", summary="ok", files_created=[file]
+            )
+            builder.build(BuildOptions(target=target, output_dir=str(out_dir)))
+
+        for target in builder.resolve_targets(["core/*"]):
+            builder.clean(target, str(out_dir))
+
+        assert not (out_dir / "a.py").exists()
+        assert not (out_dir / "b.py").exists()
+        assert (out_dir / "api.py").exists()
+        assert storage.get_status("core/a") == TargetStatus.PENDING
+        assert storage.get_status("core/b") == TargetStatus.PENDING
+        assert storage.get_status("api") == TargetStatus.OUTDATED
 
 
 # ---------------------------------------------------------------------------
-# Tests: Detect outdated
+# Tests: Tag builds
 # ---------------------------------------------------------------------------
 
 
-class TestDetectOutdated:
-    """Tests for the detect_outdated() method."""
+class TestTagBuild:
+    def _builder(self):
+        project = _make_project(
+            features={
+                "core": [],
+                "auth": ["core"],
+                "api": ["auth"],
+                "web": ["core"],
+                "docs": [],
+            }
+        )
+        project.features["api"].intents[0].tags = ["smoke"]
+        project.features["docs"].intents[0].tags = ["smoke", "nightly"]
+        return _make_builder(project=project)
 
-    def test_detect_outdated_with_newer_files(self):
-        """Targets with source files newer than build timestamp are outdated."""
-        with tempfile.TemporaryDirectory() as tmpdir:
-            # Create an intent file
-            ic_path = Path(tmpdir) / "core.ic"
-            ic_path.write_text("# Core intent")
+    def test_tag_includes_dependencies(self):
+        builder, _, storage, vc = self._builder()
 
-            intent = IntentFile(
-                name="core",
-                body="Core intent",
-                source_path=ic_path,
-            )
-            node = FeatureNode(path="core", intents=[intent])
-            project = Project(
-                project_intent=ProjectIntent(name="test", body="test"),
-                features={"core": node},
-            )
+        results, error = builder.build(BuildOptions(tag="smoke", dry_run=True))
 
-            builder, _, storage, vc = _make_builder(project=project)
+        assert error is None
+        assert [r.target for r in results] == ["core", "docs", "auth", "api"]
 
-            # Mark as built with an old timestamp
-            old_time = datetime.now() - timedelta(hours=1)
-            storage.set_status("core", TargetStatus.BUILT)
-            storage._results["core"] = BuildResult(
-                target="core",
-                status="built",
-                timestamp=old_time.isoformat(),
-            )
+    def test_tag_skips_built_targets_without_force(self):
+        builder, _, storage, vc = self._builder()
+        storage.set_status("core", TargetStatus.BUILT)
 
-            # Touch the file to make it newer
-            os.utime(ic_path, None)
+        results, _ = builder.build(BuildOptions(tag="smoke", dry_run=True))
 
-            outdated = builder.detect_outdated()
+        assert [r.target for r in results] == ["docs", "auth", "api"]
 
-        assert "core" in outdated
+    def test_unknown_tag_raises(self):
+        builder, _, storage, vc = self._builder()
+        with pytest.raises(KeyError, match="No features are tagged 'release'"):
+            builder.build(BuildOptions(tag="release"))
 
-    def test_detect_outdated_current_files(self):
-        """Targets with source files older than build are not outdated."""
-        with tempfile.TemporaryDirectory() as tmpdir:
-            ic_path = Path(tmpdir) / "core.ic"
-            ic_path.write_text("# Core intent")
 
-            intent = IntentFile(
-                name="core",
-                body="Core intent",
-                source_path=ic_path,
-            )
-            node = FeatureNode(path="core", intents=[intent])
-            project = Project(
-                project_intent=ProjectIntent(name="test", body="test"),
-                features={"core": node},
+class TestBuildReport:
+    def _builder(self):
+        project = _make_project(features={"core": [], "api": ["core"]})
+        agent = MockAgent(
+            build_response=BuildResponse(
+                status="success", summary="ok", files_created=["main.py"]
             )
+        )
+        builder, agent, storage, vc = _make_builder(project=project, mock_agent=agent)
+        storage.set_status("core", TargetStatus.BUILT)
+        return builder
 
-            builder, _, storage, vc = _make_builder(project=project)
+    def test_report_lists_built_and_skipped_targets(self, tmp_path):
+        builder = self._builder()
+        report_path = tmp_path / "reports" / "build.json"
 
-            # Mark as built with a future timestamp
-            future_time = datetime.now() + timedelta(hours=1)
-            storage.set_status("core", TargetStatus.BUILT)
-            storage._results["core"] = BuildResult(
-                target="core",
-                status="built",
-                timestamp=future_time.isoformat(),
+        results, error = builder.build(
+            BuildOptions(output_dir=str(tmp_path / "out"), report_path=str(report_path))
+        )
+
+        assert error is None
+        report = BuildReport.model_validate_json(report_path.read_text())
+        assert report.status == "completed"
+        assert report.generation_id == results[0].generation_id
+        assert [(t.target, t.status, t.forced) for t in report.targets] == [
+            ("api", "built", False),
+            ("core", "skipped", False),
+        ]
+        api = report.targets[0]
+        assert api.files == ["main.py"]
+        assert api.output_dir == str(tmp_path / "out")
+        assert api.duration_secs == results[0].total_duration_secs
+
+    def test_forced_targets_are_marked(self, tmp_path):
+        builder = self._builder()
+        report_path = tmp_path / "build.json"
+
+        builder.build(
+            BuildOptions(
+                output_dir=str(tmp_path / "out"), force=True, report_path=str(report_path)
             )
+        )
 
-            outdated = builder.detect_outdated()
+        report = BuildReport.model_validate_json(report_path.read_text())
+        assert [(t.target, t.status, t.forced) for t in report.targets] == [
+            ("core", "built", True),
+            ("api", "built", False),
+        ]
 
-        assert "core" not in outdated
+    def test_aborted_build_reports_failure(self, tmp_path):
+        project = _make_project(features={"a": ["b"], "b": ["a"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+        report_path = tmp_path / "build.json"
 
-    def test_detect_outdated_skips_non_built(self):
-        """Targets not in 'built' status are ignored."""
-        project = _make_project(features={"core": []})
-        builder, _, storage, vc = _make_builder(project=project)
+        results, error = builder.build(
+            BuildOptions(output_dir=str(tmp_path / "out"), report_path=str(report_path))
+        )
 
-        storage.set_status("core", TargetStatus.PENDING)
+        assert results == []
+        report = BuildReport.model_validate_json(report_path.read_text())
+        assert report.status == "failed"
+        assert report.targets == []
+        assert report.error == str(error)
 
-        outdated = builder.detect_outdated()
+    def test_interrupted_build_reports_failure(self, tmp_path):
+        builder, agent, storage, vc = _make_builder(
+            project=_make_project(features={"core": []})
+        )
+        report_path = tmp_path / "build.json"
 
-        assert outdated == []
+        def interrupted_build(ctx):
+            raise KeyboardInterrupt
 
-    def test_detect_outdated_does_not_modify_state(self):
-        """detect_outdated() does not change any state."""
-        project = _make_project(features={"core": []})
-        builder, _, storage, vc = _make_builder(project=project)
+        agent.build = interrupted_build
 
-        storage.set_status("core", TargetStatus.BUILT)
-        storage._results["core"] = BuildResult(
-            target="core",
-            status="built",
-            timestamp=datetime.now().isoformat(),
-        )
+        with pytest.raises(KeyboardInterrupt):
+            builder.build(
+                BuildOptions(output_dir=str(tmp_path / "out"), report_path=str(report_path))
+            )
 
-        builder.detect_outdated()
+        report = BuildReport.model_validate_json(report_path.read_text())
+        assert report.status == "failed"
+        assert report.generation_id
 
-        assert storage.get_status("core") == TargetStatus.BUILT
 
+class TestManualTargets:
+    def _builder(self):
+        project = _make_project(
+            features={"core": [], "e2e": ["core"], "fixtures": [], "api": ["fixtures"]}
+        )
+        project.features["e2e"].intents[0].manual = True
+        project.features["fixtures"].intents[0].manual = True
+        return _make_builder(project=project)
 
-# ---------------------------------------------------------------------------
-# Tests: Build context
-# ---------------------------------------------------------------------------
+    def test_build_all_leaves_out_manual_targets(self):
+        builder, agent, storage, vc = self._builder()
 
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir))
 
-class TestBuildContext:
-    """Tests for correct BuildContext construction."""
+        assert error is None
+        # fixtures is manual too, but api depends on it
+        assert sorted(r.target for r in results) == ["api", "core", "fixtures"]
+        assert builder.skipped == []
 
-    def test_build_context_has_dependency_names(self):
-        """BuildContext includes resolved dependency names."""
-        project = _make_project(features={"core": [], "api": ["core"]})
-        builder, agent, storage, vc = _make_builder(project=project)
+    def test_named_manual_target_builds(self):
+        builder, agent, storage, vc = self._builder()
 
         with tempfile.TemporaryDirectory() as out_dir:
-            builder.build(BuildOptions(output_dir=out_dir))
+            results, error = builder.build(BuildOptions(target="e2e", output_dir=out_dir))
 
-        # api's build context should have "core" as dependency
+        assert error is None
+        assert [r.target for r in results] == ["core", "e2e"]
+        assert storage.get_status("e2e") == TargetStatus.BUILT
+
+
+class _WorktreeVersionControl(FakeVersionControl):
+    """Fake version control that hands out a separate instance per worktree."""
+
+    def __init__(self) -> None:
+        super().__init__()
+        self.worktrees: dict[str, FakeVersionControl] = {}
+
+    def worktree(self, path: Path, branch: str) -> FakeVersionControl:
+        return self.worktrees.setdefault(branch, FakeVersionControl())
+
+
+class TestWorktreeBuild:
+    def _builder(self, base_dir: Path, vc: FakeVersionControl):
+        state_mgr = StateManager(
+            base_dir=base_dir, output_dir="src", backend=FakeStorageBackend()
+        )
+        agent = MockAgent()
+        builder = Builder(
+            project=_make_project(features={"core": []}),
+            state_manager=state_mgr,
+            version_control=vc,
+            agent_profile=AgentProfile(name="test", provider="cli"),
+            create_agent=lambda _p: agent,
+        )
+        return builder, agent
+
+    def test_builds_and_commits_in_worktree(self, tmp_path: Path):
+        vc = _WorktreeVersionControl()
+        builder, agent = self._builder(tmp_path, vc)
+
+        results, error = builder.build(BuildOptions(output_dir="src", worktree="review"))
+
+        assert error is None
+        wt_path = tmp_path / ".intentc" / "worktrees" / "review"
+        assert agent.build_calls[0].output_dir == str(wt_path / "src")
+        assert vc.checkpoints == []
+        assert len(vc.worktrees["intentc/review"].checkpoints) == 1
+        assert results[0].commit_id == "fake-commit-0001"
+
+    def test_worktree_unsupported_aborts(self, tmp_path: Path):
+        builder, agent = self._builder(tmp_path, FakeVersionControl())
+
+        results, error = builder.build(BuildOptions(output_dir="src", worktree="review"))
+
+        assert results == []
+        assert "Could not set up worktree 'review'" in str(error)
+        assert agent.build_calls == []
+
+
+class TestRetryFailed:
+    def test_next_run_starts_with_last_error(self):
+        project = _make_project(features={"core": []})
+        logs: list[str] = []
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder._log = logs.append
+        agent._build_response = BuildResponse(status="failure", summary="Compilation error")
+        builder.build(BuildOptions())
+
+        agent._build_response = BuildResponse(status="success", summary="ok")
+        agent.build_calls.clear()
+        results, error = builder.build(BuildOptions())
+
+        assert error is None
+        assert any("Compilation error" in e for e in agent.build_calls[0].previous_errors)
+        assert any("has failed 1 time(s) in a row" in m for m in logs)
+
+    def test_no_carry_over_after_success(self):
+        project = _make_project(features={"core": []})
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder.build(BuildOptions())
+
+        agent.build_calls.clear()
+        builder.build(BuildOptions(force=True, force_agent=True))
+
+        assert agent.build_calls[0].previous_errors == []
+
+    def test_retry_failed_selects_only_failed_targets(self):
+        project = _make_project(features={"core": [], "api": ["core"], "docs": []})
+        builder, agent, storage, vc = _make_builder(project=project)
+        storage.set_status("core", TargetStatus.BUILT)
+        storage.set_status("api", TargetStatus.FAILED)
+
+        results, error = builder.build(BuildOptions(retry_failed=True))
+
+        assert error is None
+        assert [r.target for r in results] == ["api"]
+
+
+class TestCascade:
+    def _diamond(self):
+        project = _make_project(
+            features={
+                "core": [],
+                "left": ["core"],
+                "right": ["core"],
+                "top": ["left", "right"],
+                "other": [],
+            }
+        )
+        builder, agent, storage, vc = _make_builder(project=project)
+        for name in ("core", "left", "right", "top", "other", "top:cli"):
+            storage.set_status(name, TargetStatus.BUILT)
+        return builder, storage
+
+    def test_diamond_dependents_marked_outdated(self):
+        builder, storage = self._diamond()
+
+        results, error = builder.build(BuildOptions(target="core", force=True))
+
+        assert error is None
+        assert [r.target for r in results] == ["core"]
+        assert storage.get_status("core") == TargetStatus.BUILT
+        for name in ("left", "right", "top", "top:cli"):
+            assert storage.get_status(name) == TargetStatus.OUTDATED
+        assert storage.get_status("other") == TargetStatus.BUILT
+
+        # The next build picks up exactly the stale targets
+        assert [p.target for p in builder.build_plan(BuildOptions())] == [
+            "left",
+            "right",
+            "top",
+        ]
+
+    def test_only_built_dependents_change(self):
+        builder, storage = self._diamond()
+        storage.set_status("left", TargetStatus.FAILED)
+
+        builder.build(BuildOptions(target="core", force=True))
+
+        assert storage.get_status("left") == TargetStatus.FAILED
+        assert storage.get_status("right") == TargetStatus.OUTDATED
+
+    def test_no_cascade(self):
+        builder, storage = self._diamond()
+
+        builder.build(BuildOptions(target="core", force=True, cascade=False))
+
+        for name in ("left", "right", "top"):
+            assert storage.get_status(name) == TargetStatus.BUILT
+
+
+class TestSelectDeps:
+    def _diamond(self):
+        project = _make_project(
+            features={
+                "core": [],
+                "left": ["core"],
+                "right": ["core"],
+                "top": ["left", "right"],
+            }
+        )
+        builder, agent, storage, vc = _make_builder(project=project)
+        return builder, storage
+
+    def test_transitive_builds_every_ancestor(self):
+        builder, storage = self._diamond()
+
+        results, error = builder.build(BuildOptions(target="top"))
+
+        assert error is None
+        assert [r.target for r in results] == ["core", "left", "right", "top"]
+
+    def test_direct_builds_immediate_dependencies_only(self):
+        builder, storage = self._diamond()
+        storage.set_status("core", TargetStatus.OUTDATED)
+
+        results, error = builder.build(BuildOptions(target="top", select_deps="direct"))
+
+        assert error is None
+        assert [r.target for r in results] == ["left", "right", "top"]
+        assert storage.get_status("core") == TargetStatus.OUTDATED
+
+    def test_none_builds_only_the_target(self):
+        builder, storage = self._diamond()
+        for name in ("core", "left", "right"):
+            storage.set_status(name, TargetStatus.BUILT)
+
+        results, error = builder.build(
+            BuildOptions(target="top", select_deps="none", force=True)
+        )
+
+        assert error is None
+        assert [r.target for r in results] == ["top"]
+
+    def test_unselected_unbuilt_dependency_is_an_error(self):
+        builder, storage = self._diamond()
+
+        results, error = builder.build(BuildOptions(target="top", select_deps="direct"))
+
+        assert results == []
+        assert isinstance(error, UnbuiltDependencyError)
+        assert (error.target, error.dependency) == ("left", "core")
+
+        with pytest.raises(UnbuiltDependencyError) as exc:
+            builder.build_plan(BuildOptions(target="top", select_deps="none"))
+        assert exc.value.dependency == "left"
+
+
+class TestParallelBuild:
+    """Tests for building independent targets at the same time."""
+
+    def test_independent_targets_build_concurrently(self):
+        project = _make_project(
+            features={"core": [], "left": ["core"], "right": ["core"], "top": ["left", "right"]}
+        )
+        # Only passes if left and right are building at the same time
+        both_running = threading.Barrier(2, timeout=5)
+        finished: list[str] = []
+
+        class DiamondAgent(MockAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                if ctx.intent.name in ("left", "right"):
+                    both_running.wait()
+                if ctx.intent.name == "top":
+                    assert {"left", "right"} <= set(finished)
+                finished.append(ctx.intent.name)
+                return super().build(ctx)
+
+        builder, agent, storage, vc = _make_builder(
+            project=project, mock_agent=DiamondAgent()
+        )
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir, parallelism=2))
+
+        assert error is None
+        assert sorted(r.target for r in results) == ["core", "left", "right", "top"]
+        assert all(r.status == "built" for r in results)
+        assert finished[0] == "core" and finished[-1] == "top"
+
+    def test_parallel_checkpoints_commit_only_the_targets_files(self, tmp_path):
+        project = _make_project(features={"a": [], "b": []})
+
+        class ListingAgent(MockAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                name = ctx.intent.name
+                return BuildResponse(status="success", summary="ok", files_created=[f"{name}.py"])
+
+        builder, agent, storage, vc = _make_builder(project=project, mock_agent=ListingAgent())
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+
+        out = (tmp_path / "out").as_posix()
+        builder.build(BuildOptions(output_dir=out, parallelism=2))
+
+        assert sorted(vc.checkpoint_paths) == [[f"{out}/a.py"], [f"{out}/b.py"]]
+
+        builder.build(BuildOptions(output_dir=out, force=True, force_agent=True))
+
+        assert vc.checkpoint_paths[2:] == [None, None]
+
+    def test_parallel_snapshot_leaves_out_files_other_targets_claimed(self, tmp_path):
+        project = _make_project(features={"a": [], "b": []})
+        out = tmp_path / "out"
+        a_file = (out / "a.py").as_posix()
+
+        class SiblingAgent(_OpaqueAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                name = ctx.intent.name
+                if name == "b":
+                    # Finish after a has claimed its file
+                    deadline = time.monotonic() + 5
+                    while ownership.owner(a_file) is None and time.monotonic() < deadline:
+                        time.sleep(0.01)
+                (Path(ctx.output_dir) / f"{name}.py").write_text(f"# {name}\n")
+                return BuildResponse(status="success", summary="ok")
+
+        builder, agent, storage, vc = _make_builder(project=project, mock_agent=SiblingAgent())
+        ownership = OwnershipIndex(tmp_path / "ownership.json")
+        builder._state_manager._ownership = ownership
+        out.mkdir()
+
+        results, error = builder.build(BuildOptions(output_dir=str(out), parallelism=2))
+
+        assert error is None
+        assert ownership.files_for("a") == [a_file]
+        assert ownership.files_for("b") == [(out / "b.py").as_posix()]
+
+    def test_interrupt_does_not_wait_for_running_targets(self):
+        project = _make_project(features={"a": [], "b": []})
+        release = threading.Event()
+
+        class InterruptedAgent(MockAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                if ctx.intent.name == "a":
+                    raise KeyboardInterrupt
+                release.wait(timeout=5)
+                return super().build(ctx)
+
+        builder, agent, storage, vc = _make_builder(
+            project=project, mock_agent=InterruptedAgent()
+        )
+
+        start = time.monotonic()
+        with tempfile.TemporaryDirectory() as out_dir:
+            with pytest.raises(KeyboardInterrupt):
+                builder.build(BuildOptions(output_dir=out_dir, parallelism=2))
+        release.set()
+
+        assert time.monotonic() - start < 2
+        assert builder._cancelled.is_set()
+
+    def test_max_concurrency_caps_build_agents(self):
+        project = _make_project(features={"a": [], "b": [], "c": []})
+        lock = threading.Lock()
+        running: list[int] = [0, 0]  # current, peak
+
+        class CountingAgent(MockAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                with lock:
+                    running[0] += 1
+                    running[1] = max(running)
+                time.sleep(0.05)
+                with lock:
+                    running[0] -= 1
+                return super().build(ctx)
+
+        builder, agent, storage, vc = _make_builder(
+            project=project, mock_agent=CountingAgent()
+        )
+        builder._agents = AgentFactory(max_concurrency={"cli": 1})
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir, parallelism=3))
+
+        assert error is None
+        assert len(results) == 3
+        assert running[1] == 1
+
+    def test_failure_cancels_sibling_builds(self):
+        project = _make_project(features={"a": [], "b": [], "c": ["a"]})
+        calls: list[str] = []
+
+        class FailFastAgent(MockAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                calls.append(ctx.intent.name)
+                if ctx.intent.name == "a":
+                    raise AgentError("broken")
+                # Still running when a fails; the retry must not start
+                assert builder._cancelled.wait(timeout=5)
+                raise AgentError("interrupted")
+
+        builder, agent, storage, vc = _make_builder(
+            project=project, mock_agent=FailFastAgent()
+        )
+        builder._agent_profile = AgentProfile(name="test", provider="cli", retries=3)
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir, parallelism=2))
+
+        assert "'a'" in str(error)
+        assert calls.count("a") == 3
+        assert calls.count("b") == 1
+        assert "c" not in calls
+        assert {r.target: r.status for r in results} == {"a": "failed", "b": "failed"}
+        assert "Cancelled" in next(r for r in results if r.target == "b").steps[-1].summary
+
+
+class TestWeightedScheduling:
+    def test_longest_recorded_target_is_built_first(self):
+        project = _make_project(
+            features={"core": [], "quick": ["core"], "slow": ["core"], "top": ["quick", "slow"]}
+        )
+        builder, agent, storage, vc = _make_builder(project=project)
+        storage._results["quick"] = BuildResult(target="quick", total_duration_secs=3.0)
+        storage._results["slow"] = BuildResult(target="slow", total_duration_secs=90.0)
+
+        plan = builder.build_plan(BuildOptions(force=True))
+
+        assert [p.target for p in plan] == ["core", "slow", "quick", "top"]
+
+    def test_subsecond_noise_keeps_topological_order(self):
+        project = _make_project(features={"a": [], "b": []})
+        builder, agent, storage, vc = _make_builder(project=project)
+        storage._results["b"] = BuildResult(target="b", total_duration_secs=0.4)
+
+        assert [p.target for p in builder.build_plan(BuildOptions())] == ["a", "b"]
+
+
+class TestDeadline:
+    def test_slow_agent_times_out_and_target_is_failed(self, tmp_path):
+        import time
+
+        from intentc.build.agents import CLIAgent
+
+        builder, _, storage, vc = _make_builder(project=_make_project(features={"core": []}))
+        builder._agent_profile = AgentProfile(name="slow", provider="cli", command="sleep 5")
+        builder._create_agent = lambda p: CLIAgent(p)
+        builder._deadline = time.monotonic() + 0.5
+
+        started = time.monotonic()
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert time.monotonic() - started < 4
+        assert error is not None
+        assert results[0].status == "failed"
+        assert "timed out" in results[0].steps[-1].summary.lower()
+        assert storage.get_status("core") == TargetStatus.FAILED
+        assert vc.checkpoints == []
+        assert builder._state_manager.build_lock.read() is None
+
+    def test_expired_deadline_starts_no_agent(self):
+        builder, agent, storage, _ = _make_builder()
+        builder._deadline = 0.0
+
+        results, error = builder.build(BuildOptions())
+
+        assert agent.build_calls == []
+        assert [r.target for r in results] == ["core"]
+        assert "timed out" in str(error)
+        assert storage.get_status("core") == TargetStatus.FAILED
+
+    def test_agent_timeout_capped_by_deadline(self):
+        import time
+
+        builder, _, _, _ = _make_builder()
+        builder._deadline = time.monotonic() + 30
+        profile = AgentProfile(name="p", provider="cli", timeout=3600)
+
+        assert builder._within_deadline(profile).timeout <= 30
+        builder._deadline = None
+        assert builder._within_deadline(profile).timeout == 3600
+
+    def test_validation_timeout_capped_by_deadline(self, tmp_path, monkeypatch):
+        project = _make_project(features={"core": []}, with_validations=True)
+        project.features["core"].validations[0].validations[0].args["timeout"] = "30m"
+        builder, _, _, _ = _make_builder(project=project)
+        builder._deadline = time.monotonic() + 60
+        timeouts: list[float] = []
+
+        def create_from_profile(profile, **kwargs):
+            timeouts.append(profile.timeout)
+            return MockAgent()
+
+        monkeypatch.setattr(
+            "intentc.build.validations.create_from_profile", create_from_profile
+        )
+
+        builder.validate("core", str(tmp_path))
+
+        # The suite's agent, then the one for the validation's own timeout
+        assert len(timeouts) == 2
+        assert timeouts[1] <= 60
+
+
+class TestBuildPlan:
+    def _builder(self):
+        project = _make_project(
+            features={"core": [], "auth": ["core"], "api": ["auth"], "docs": []}
+        )
+        return _make_builder(project=project)
+
+    def test_full_plan_reasons(self):
+        builder, _, storage, vc = self._builder()
+        storage.set_status("auth", TargetStatus.OUTDATED)
+        storage.set_status("docs", TargetStatus.BUILT)
+
+        plan = builder.build_plan(BuildOptions())
+
+        assert [(p.target, p.reason) for p in plan] == [
+            ("core", "pending"),
+            ("auth", "outdated"),
+            ("api", "pending"),
+        ]
+        assert plan[2].dependencies == ["auth"]
+
+    def test_targeted_plan_marks_dependencies_and_forced(self):
+        builder, _, storage, vc = self._builder()
+        storage.set_status("core", TargetStatus.BUILT)
+
+        plan = builder.build_plan(BuildOptions(target="api", force=True))
+
+        assert [(p.target, p.reason) for p in plan] == [
+            ("core", "forced"),
+            ("auth", "dependency"),
+            ("api", "pending"),
+        ]
+
+    def test_failed_target_planned_as_failed(self):
+        builder, _, storage, vc = self._builder()
+        storage.set_status("core", TargetStatus.BUILT)
+        storage.set_status("auth", TargetStatus.FAILED)
+
+        plan = builder.build_plan(BuildOptions(target="auth"))
+
+        assert [(p.target, p.reason) for p in plan] == [("auth", "failed")]
+
+    def test_plan_builds_nothing(self):
+        builder, agent, storage, vc = self._builder()
+
+        builder.build_plan(BuildOptions())
+
+        assert agent.build_calls == []
+        assert vc.checkpoints == []
+
+
+class _OpaqueAgent(MockAgent):
+    """Writes straight into the output directory, like a CLI agent."""
+
+    uses_file_writer = False
+
+    def build(self, ctx: BuildContext) -> BuildResponse:
+        self.build_calls.append(ctx)
+        out = Path(ctx.output_dir)
+        out.mkdir(parents=True, exist_ok=True)
+        (out / "main.py").write_text(f"# {ctx.intent.name}\n")
+        return self._build_response
+
+
+class TestFileWriter:
+    def test_preview_keeps_writes_in_memory(self, tmp_path):
+        out = tmp_path / "out"
+        out.mkdir()
+        (out / "main.py").write_text("old\n")
+        agent = MockAgent(files={"main.py": "new\n", "core.py": "core\n"})
+        builder, _, storage, vc = _make_builder(mock_agent=agent)
+
+        results, error = builder.build(
+            BuildOptions(dry_run=True, preview=True, output_dir=str(out))
+        )
+
+        assert error is None
+        assert [r.target for r in results] == ["core", "api"]
+        core_changes = [(c.path, c.change) for c in results[0]._file_changes]
+        assert core_changes == [("core.py", "created"), ("main.py", "modified")]
+        # api sees core's pending output, so writing the same content changes nothing
+        assert results[1]._file_changes == []
+        assert (out / "main.py").read_text() == "old\n"
+        assert not (out / "core.py").exists()
+        assert vc.checkpoints == []
+        assert storage.get_status("core") == TargetStatus.PENDING
+
+    def test_preview_runs_opaque_agent_in_scratch_copy(self, tmp_path):
+        out = tmp_path / "out"
+        out.mkdir()
+        (out / "main.py").write_text("old\n")
+        builder, agent, _, _ = _make_builder(
+            project=_make_project(features={"core": []}), mock_agent=_OpaqueAgent()
+        )
+
+        results, _ = builder.build(
+            BuildOptions(dry_run=True, preview=True, output_dir=str(out))
+        )
+
+        assert agent.build_calls[0].output_dir != str(out)
+        changes = results[0]._file_changes
+        assert [(c.path, c.change) for c in changes] == [("main.py", "modified")]
+        assert "+# core" in changes[0].diff
+        assert (out / "main.py").read_text() == "old\n"
+
+    def test_build_uses_writer_changes_when_response_lists_none(self, tmp_path):
+        builder, _, storage, _ = _make_builder(
+            project=_make_project(features={"core": []}), mock_agent=_OpaqueAgent()
+        )
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert error is None
+        assert [c.path for c in results[0]._file_changes] == ["main.py"]
+        out = (tmp_path / "out").as_posix()
+        assert builder._state_manager.ownership.files_for("core") == [f"{out}/main.py"]
+        assert results[0].file_sources == {"main.py": "writer"}
+        assert builder._state_manager.ownership.source(f"{out}/main.py") == "writer"
+
+    def test_build_checks_only_listed_files_of_opaque_agent(self, tmp_path):
+        class _NoisyAgent(_OpaqueAgent):
+            def build(self, ctx: BuildContext) -> BuildResponse:
+                response = super().build(ctx)
+                (Path(ctx.output_dir) / "scratch.log").write_text("noise\n")
+                return response
+
+        agent = _NoisyAgent(
+            build_response=BuildResponse(
+                status="success", summary="ok", files_created=["main.py"]
+            )
+        )
+        builder, _, _, _ = _make_builder(
+            project=_make_project(features={"core": []}), mock_agent=agent
+        )
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert error is None
+        assert [c.path for c in results[0]._file_changes] == ["main.py"]
+
+    def test_project_root_build_skips_intent_and_other_outputs(self, tmp_path):
+        builder, _, _, _ = _make_builder()
+        builder._state_manager._base_dir = tmp_path
+        builder._project.project_intent.source_path = tmp_path / "intent" / "project.ic"
+        (tmp_path / ".intentc" / "state" / "src").mkdir(parents=True)
+        (tmp_path / ".intentc" / "state" / "src" / "intentc.db").write_text("")
+
+        assert builder._non_output_dirs("") == ["intent", "src"]
+        assert builder._non_output_dirs("out") == []
+
+
+# ---------------------------------------------------------------------------
+# Tests: Context files
+# ---------------------------------------------------------------------------
+
+
+class TestContextFiles:
+    """Tests for injecting an intent's context files into the build prompt."""
+
+    def test_load_in_order(self, tmp_path):
+        (tmp_path / "a.md").write_text("alpha")
+        (tmp_path / "b.md").write_text("beta")
+        assert load_context_files(["b.md", "a.md"], tmp_path) == {
+            "b.md": "beta",
+            "a.md": "alpha",
+        }
+
+    def test_truncates_at_size_cap(self, tmp_path):
+        (tmp_path / "a.md").write_text("a" * 6)
+        (tmp_path / "b.md").write_text("b" * 6)
+        (tmp_path / "c.md").write_text("c")
+        log: list[str] = []
+
+        loaded = load_context_files(
+            ["a.md", "b.md", "c.md"], tmp_path, max_chars=10, log=log.append
+        )
+
+        assert loaded["a.md"] == "aaaaaa"
+        assert loaded["b.md"].startswith("bbbb\n")
+        assert "truncated: context size limit of 10 characters" in loaded["b.md"]
+        assert "c.md" not in loaded
+        assert any("'c.md' omitted" in m for m in log)
+
+    def test_missing_file_warns(self, tmp_path):
+        log: list[str] = []
+        assert load_context_files(["nope.md"], tmp_path, log=log.append) == {}
+        assert any("'nope.md' could not be read" in m for m in log)
+
+    def test_build_passes_context_to_agent(self, tmp_path):
+        (tmp_path / "core").mkdir()
+        (tmp_path / "core" / "spec.yaml").write_text("openapi: 3.1")
+        project = _make_project(features={"core": []})
+        project.features["core"].intents[0] = IntentFile(
+            name="core",
+            context=["spec.yaml"],
+            source_path=tmp_path / "core" / "core.ic",
+        )
+        builder, agent, storage, vc = _make_builder(project=project)
+
+        builder.build(BuildOptions(target="core", output_dir=str(tmp_path / "out")))
+
+        assert agent.build_calls[0].context_files == {"spec.yaml": "openapi: 3.1"}
+
+
+class TestDependencyOutputs:
+    """Tests for interpolating dependency outputs into an intent."""
+
+    def test_resolve_known_values(self):
+        body = "Mount under {{dep.auth.outputs.base_url}} ({{ dep.auth.outputs.port }})"
+        outputs = {"auth": {"base_url": "/api/v1", "port": "8080"}}
+
+        assert resolve_dependency_outputs(body, outputs.get) == "Mount under /api/v1 (8080)"
+
+    def test_unresolved_references_stay_with_warning(self):
+        body = "{{dep.auth.outputs.missing}} {{dep.other.outputs.base_url}}"
+        log: list[str] = []
+
+        resolved = resolve_dependency_outputs(
+            body, {"auth": {"base_url": "/api"}}.get, log=log.append
+        )
+
+        assert resolved == body
+        assert any("has no recorded value" in m for m in log)
+        assert any("does not name a dependency" in m for m in log)
+
+    def test_build_records_and_interpolates_outputs(self):
+        project = _make_project(features={"core": [], "api": ["core"], "cli": []})
+        project.features["api"].intents[0] = IntentFile(
+            name="api",
+            depends_on=["core"],
+            body="Serve under {{dep.core.outputs.base_url}}",
+        )
+        project.features["cli"].intents[0] = IntentFile(
+            name="cli", body="Call {{dep.core.outputs.base_url}}"
+        )
+        agent = MockAgent(
+            build_response=BuildResponse(
+                status="success", summary="ok", outputs={"base_url": "/api/v1"}
+            )
+        )
+        builder, agent, storage, vc = _make_builder(project=project, mock_agent=agent)
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            builder.build(BuildOptions(output_dir=out_dir))
+
+        bodies = {c.intent.name: c.intent.body for c in agent.build_calls}
+        assert storage.get_build_result("core").outputs == {"base_url": "/api/v1"}
+        assert bodies["api"] == "Serve under /api/v1"
+        # cli does not depend on core, so the reference is left alone
+        assert bodies["cli"] == "Call {{dep.core.outputs.base_url}}"
+        assert "{{" in project.features["api"].intents[0].body
+
+
+# ---------------------------------------------------------------------------
+# Tests: Interrupted builds
+# ---------------------------------------------------------------------------
+
+
+def _dead_pid() -> int:
+    """PID of a process that has already exited."""
+    import subprocess
+
+    proc = subprocess.Popen(["true"])
+    proc.wait()
+    return proc.pid
+
+
+class TestResume:
+    """Tests for recovering from and resuming interrupted builds."""
+
+    def _builder(self, tmp_path, features=None):
+        project = _make_project(features=features or {"core": [], "api": ["core"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+        lock = BuildLock(tmp_path / "build.lock")
+        builder._state_manager._build_lock = lock
+        return builder, agent, storage, lock
+
+    def _write_stale_lock(self, lock, generation_id="gen-old", target=""):
+        import json
+
+        lock.path.write_text(json.dumps({
+            "pid": _dead_pid(),
+            "generation_id": generation_id,
+            "target": target,
+        }))
+
+    def test_build_releases_lock(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+
+        builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert lock.read() is None
+
+    def test_stale_building_target_is_rebuilt(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+        storage.create_generation("gen-old", "src")
+        storage.set_status("core", TargetStatus.BUILT)
+        storage.set_status("api", TargetStatus.BUILDING)
+        self._write_stale_lock(lock)
+
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert error is None
+        assert [r.target for r in results] == ["api"]
+        assert storage._generations["gen-old"]["status"] == GenerationStatus.FAILED.value
+        assert lock.read() is None
+
+    def test_building_target_without_lock_is_rebuilt(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+        storage.set_status("core", TargetStatus.BUILDING)
+
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert "core" in [r.target for r in results]
+
+    def test_dry_run_leaves_interrupted_build_alone(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+        storage.create_generation("gen-old", "src")
+        storage.set_status("core", TargetStatus.BUILDING)
+        self._write_stale_lock(lock)
+
+        builder.build(BuildOptions(output_dir=str(tmp_path / "out"), dry_run=True))
+
+        assert storage.get_status("core") == TargetStatus.BUILDING
+        assert storage._generations["gen-old"]["status"] != GenerationStatus.FAILED.value
+        assert lock.read()["generation_id"] == "gen-old"
+
+    def test_lock_taken_by_racing_build_aborts(self, tmp_path, monkeypatch):
+        builder, agent, storage, lock = self._builder(tmp_path)
+        monkeypatch.setattr(lock, "acquire", lambda *a, **kw: False)
+
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert results == []
+        assert "Another build" in str(error)
+        assert len(agent.build_calls) == 0
+        assert storage._generations == {}
+
+    def test_unexpected_error_releases_lock(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+
+        def broken_build(ctx):
+            raise ValueError("boom")
+
+        agent.build = broken_build
+
+        with pytest.raises(ValueError):
+            builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert lock.read() is None
+
+    def test_live_lock_aborts(self, tmp_path):
+        import json
+
+        builder, agent, storage, lock = self._builder(tmp_path)
+        lock.path.write_text(json.dumps({"pid": os.getppid(), "generation_id": "g"}))
+
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert results == []
+        assert "Another build" in str(error)
+        assert len(agent.build_calls) == 0
+
+    def test_resume_continues_interrupted_target_set(self, tmp_path):
+        builder, agent, storage, lock = self._builder(
+            tmp_path, features={"core": [], "api": ["core"], "web": []}
+        )
+        storage.set_status("core", TargetStatus.BUILT)
+        storage.set_status("api", TargetStatus.BUILDING)
+        self._write_stale_lock(lock, target="api")
+
+        results, error = builder.build(
+            BuildOptions(output_dir=str(tmp_path / "out"), resume=True)
+        )
+
+        assert error is None
+        assert [r.target for r in results] == ["api"]
+        assert storage.get_status("web") == TargetStatus.PENDING
+
+    def test_interrupt_marks_target_failed_and_releases_lock(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+        calls = 0
+
+        def interrupted_build(ctx):
+            nonlocal calls
+            calls += 1
+            if calls == 2:
+                assert lock.read() is not None
+                raise KeyboardInterrupt
+            return BuildResponse(status="success", summary="ok")
+
+        agent.build = interrupted_build
+
+        with pytest.raises(KeyboardInterrupt):
+            builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        assert storage.get_status("core") == TargetStatus.BUILT
+        assert storage.get_status("api") == TargetStatus.FAILED
+        (generation,) = storage._generations.values()
+        assert generation["status"] == GenerationStatus.FAILED.value
+        assert lock.read() is None
+
+        # The next build picks up where the interrupted one stopped
+        agent.build = MockAgent().build
+        results, error = builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+        assert error is None
+        assert [r.target for r in results] == ["api"]
+
+    def test_resume_without_interrupted_build(self, tmp_path):
+        builder, agent, storage, lock = self._builder(tmp_path)
+
+        results, error = builder.build(
+            BuildOptions(output_dir=str(tmp_path / "out"), resume=True)
+        )
+
+        assert results == []
+        assert error is None
+        assert len(agent.build_calls) == 0
+
+
+# ---------------------------------------------------------------------------
+# Tests: Event stream
+# ---------------------------------------------------------------------------
+
+
+class TestEvents:
+    """Tests for the JSONL event stream written during builds."""
+
+    def _read(self, path: Path) -> list[dict]:
+        import json
+
+        return [json.loads(line) for line in path.read_text().splitlines()]
+
+    def test_build_emits_events_in_order(self, tmp_path):
+        builder, agent, storage, vc = _make_builder()
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        events = EventLog(tmp_path / "events.jsonl")
+        builder._events = events
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["main.py"]
+        )
+
+        builder.build(BuildOptions(target="core", output_dir=str(tmp_path / "out")))
+        events.close()
+
+        records = self._read(tmp_path / "events.jsonl")
+        assert [r["type"] for r in records] == [
+            "build_started",
+            "target_started",
+            "attempt",
+            "file_generated",
+            "target_built",
+            "build_finished",
+        ]
+        assert records[0]["targets"] == ["core"]
+        assert records[3]["path"] == "main.py"
+        assert records[5]["status"] == "completed"
+
+    def test_failed_target_emits_target_failed(self, tmp_path):
+        agent = MockAgent(
+            build_response=BuildResponse(status="failure", summary="boom")
+        )
+        builder, _, storage, vc = _make_builder(mock_agent=agent)
+        events = EventLog(tmp_path / "events.jsonl")
+        builder._events = events
+
+        builder.build(BuildOptions(target="core", output_dir=str(tmp_path / "out")))
+        events.close()
+
+        records = self._read(tmp_path / "events.jsonl")
+        failed = [r for r in records if r["type"] == "target_failed"]
+        assert len(failed) == 1
+        assert "boom" in failed[0]["error"]
+        assert records[-1]["type"] == "build_finished"
+        assert records[-1]["status"] == "failed"
+
+
+# ---------------------------------------------------------------------------
+# Tests: Ownership
+# ---------------------------------------------------------------------------
+
+
+class TestOwnership:
+    """Tests for generated-file ownership tracking during builds."""
+
+    def test_build_records_generated_files(self, tmp_path):
+        builder, agent, storage, vc = _make_builder()
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        agent._build_response = BuildResponse(
+            status="success",
+            summary="ok",
+            files_created=["main.py"],
+            files_modified=["config.yaml"],
+        )
+
+        builder.build(BuildOptions(target="core", output_dir=str(tmp_path / "out")))
+
+        out = (tmp_path / "out").as_posix()
+        assert builder._state_manager.ownership.files_for("core") == [
+            f"{out}/config.yaml",
+            f"{out}/main.py",
+        ]
+        assert (tmp_path / "ownership.json").exists()
+
+    def test_build_warns_on_cross_target_clobber(self, tmp_path):
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        log: list[str] = []
+        builder._log = log.append
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["config.yaml"]
+        )
+
+        builder.build(BuildOptions(output_dir=str(tmp_path / "out")))
+
+        warnings = [m for m in log if "Warning:" in m]
+        assert len(warnings) == 1
+        assert "generated by 'core'" in warnings[0]
+        assert "claimed by 'api'" in warnings[0]
+
+    def test_unchanged_target_is_rebuilt_when_its_files_are_gone(self, tmp_path):
+        builder, agent, storage, vc = _make_builder()
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["main.py"]
+        )
+        out = tmp_path / "out"
+        out.mkdir()
+        (out / "main.py").write_text("print()\n")
+        builder.build(BuildOptions(target="core", output_dir=str(out)))
+
+        results, _ = builder.build(BuildOptions(target="core", output_dir=str(out), force=True))
+        assert results == []
+        assert len(agent.build_calls) == 1
+
+        (out / "main.py").unlink()
+        results, _ = builder.build(BuildOptions(target="core", output_dir=str(out), force=True))
+        assert [r.target for r in results] == ["core"]
+        assert len(agent.build_calls) == 2
+
+    def test_unchanged_check_resolves_files_from_project_root(self, tmp_path, monkeypatch):
+        builder, agent, storage, vc = _make_builder()
+        builder._state_manager._base_dir = tmp_path
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["main.py"]
+        )
+        (tmp_path / "out").mkdir()
+        (tmp_path / "out" / "main.py").write_text("print()\n")
+        elsewhere = tmp_path / "elsewhere"
+        elsewhere.mkdir()
+        monkeypatch.chdir(elsewhere)
+        builder.build(BuildOptions(target="core", output_dir="out"))
+
+        results, _ = builder.build(BuildOptions(target="core", output_dir="out", force=True))
+
+        assert results == []
+        assert len(agent.build_calls) == 1
+
+    def test_project_intent_and_context_changes_rebuild(self, tmp_path):
+        project = _make_project(features={"core": []})
+        (tmp_path / "notes.md").write_text("v1\n")
+        project.features["core"].intents[0].context = ["notes.md"]
+        project.features["core"].intents[0].source_path = tmp_path / "core.ic"
+        builder, agent, storage, vc = _make_builder(project=project)
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        opts = BuildOptions(target="core", output_dir=str(tmp_path / "out"), force=True)
+        builder.build(opts)
+
+        builder.build(opts)
+        assert len(agent.build_calls) == 1
+
+        (tmp_path / "notes.md").write_text("v2\n")
+        builder.build(opts)
+        assert len(agent.build_calls) == 2
+
+        project.project_intent.body = "A changed project"
+        builder.build(opts)
+        assert len(agent.build_calls) == 3
+
+
+# ---------------------------------------------------------------------------
+# Tests: Validate
+# ---------------------------------------------------------------------------
+
+
+class TestValidate:
+    """Tests for the validate() method."""
+
+    def test_validate_returns_result(self):
+        """Validate returns a ValidationSuiteResult without modifying state."""
+        project = _make_project(features={"core": []}, with_validations=True)
+        builder, _, storage, vc = _make_builder(project=project)
+
+        result = builder.validate("core", "/tmp/out")
+
+        assert isinstance(result, ValidationSuiteResult)
+        assert result.target == "core"
+
+    def test_validate_does_not_modify_state(self):
+        """Validate does not save build results or change target status."""
+        project = _make_project(features={"core": []}, with_validations=True)
+        builder, _, storage, vc = _make_builder(project=project)
+
+        storage.set_status("core", TargetStatus.BUILT)
+        builder.validate("core", "/tmp/out")
+
+        assert storage.get_status("core") == TargetStatus.BUILT
+        assert len(vc.checkpoints) == 0
+
+    def _failing_suite(self, monkeypatch) -> MagicMock:
+        suite = MagicMock()
+        suite.validate_feature.return_value = ValidationSuiteResult(
+            target="core", passed=False, summary="0 passed out of 1 validations (1 errors, 0 warnings)"
+        )
+        suite_cls = MagicMock(return_value=suite)
+        monkeypatch.setattr("intentc.build.builder.builder.ValidationSuite", suite_cls)
+        return suite_cls
+
+    def test_build_fails_target_on_validation_failure(self, tmp_path, monkeypatch):
+        suite_cls = self._failing_suite(monkeypatch)
+        project = _make_project(features={"core": []}, with_validations=True)
+        builder, _, storage, vc = _make_builder(project=project)
+        builder._agent_profile = AgentProfile(name="test", provider="cli", retries=1)
+
+        results, error = builder.build(
+            BuildOptions(target="core", output_dir=str(tmp_path / "out"))
+        )
+
+        assert error is not None
+        assert results[0].status == "failed"
+        assert storage.get_status("core") == TargetStatus.FAILED
+        assert suite_cls.called
+
+    def test_build_skips_validation_when_disabled(self, tmp_path, monkeypatch):
+        suite_cls = self._failing_suite(monkeypatch)
+        project = _make_project(features={"core": []}, with_validations=True)
+        builder, _, storage, vc = _make_builder(project=project)
+
+        results, error = builder.build(
+            BuildOptions(target="core", output_dir=str(tmp_path / "out"), run_validations=False)
+        )
+
+        assert error is None
+        assert results[0].status == "built"
+        assert not suite_cls.called
+
+
+# ---------------------------------------------------------------------------
+# Tests: Detect outdated
+# ---------------------------------------------------------------------------
+
+
+class TestContentChanges:
+    """Tests for rebuilding built targets whose intents or validations changed."""
+
+    def _built(self):
+        project = _make_project(features={"core": [], "api": ["core"]}, with_validations=True)
+        builder, agent, storage, vc = _make_builder(project=project)
+        with tempfile.TemporaryDirectory() as out_dir:
+            builder.build(BuildOptions(output_dir=out_dir, run_validations=False))
+        agent.build_calls.clear()
+        return project, builder, agent, storage
+
+    def test_unchanged_targets_stay_built(self):
+        project, builder, agent, storage = self._built()
+
+        assert builder.build_plan(BuildOptions()) == []
+        assert builder.detect_outdated() == []
+
+    def test_edited_intent_rebuilds_and_cascades(self):
+        project, builder, agent, storage = self._built()
+        project.features["core"].intents[0].body = "Core, now with caching"
+
+        plan = builder.build_plan(BuildOptions())
+        assert [(p.target, p.reason) for p in plan] == [("core", "changed")]
+        assert builder.detect_outdated() == ["core"]
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            results, error = builder.build(BuildOptions(output_dir=out_dir, run_validations=False))
+
+        assert error is None
+        assert [r.target for r in results] == ["core"]
+        assert storage.get_status("api") == TargetStatus.OUTDATED
+
+    def test_edited_validation_rebuilds(self):
+        project, builder, agent, storage = self._built()
+        project.features["api"].validations[0].validations[0].args["rubric"] = "Stricter"
+
+        assert [p.target for p in builder.build_plan(BuildOptions())] == ["api"]
+
+
+class TestDetectOutdated:
+    """Tests for the detect_outdated() method."""
+
+    def test_detect_outdated_with_newer_files(self):
+        """Targets with source files newer than build timestamp are outdated."""
+        with tempfile.TemporaryDirectory() as tmpdir:
+            # Create an intent file
+            ic_path = Path(tmpdir) / "core.ic"
+            ic_path.write_text("# Core intent")
+
+            intent = IntentFile(
+                name="core",
+                body="Core intent",
+                source_path=ic_path,
+            )
+            node = FeatureNode(path="core", intents=[intent])
+            project = Project(
+                project_intent=ProjectIntent(name="test", body="test"),
+                features={"core": node},
+            )
+
+            builder, _, storage, vc = _make_builder(project=project)
+
+            # Mark as built with an old timestamp
+            old_time = datetime.now() - timedelta(hours=1)
+            storage.set_status("core", TargetStatus.BUILT)
+            storage._results["core"] = BuildResult(
+                target="core",
+                status="built",
+                timestamp=old_time.isoformat(),
+            )
+
+            # Touch the file to make it newer
+            os.utime(ic_path, None)
+
+            outdated = builder.detect_outdated()
+
+        assert "core" in outdated
+
+    def test_detect_outdated_current_files(self):
+        """Targets with source files older than build are not outdated."""
+        with tempfile.TemporaryDirectory() as tmpdir:
+            ic_path = Path(tmpdir) / "core.ic"
+            ic_path.write_text("# Core intent")
+
+            intent = IntentFile(
+                name="core",
+                body="Core intent",
+                source_path=ic_path,
+            )
+            node = FeatureNode(path="core", intents=[intent])
+            project = Project(
+                project_intent=ProjectIntent(name="test", body="test"),
+                features={"core": node},
+            )
+
+            builder, _, storage, vc = _make_builder(project=project)
+
+            # Mark as built with a future timestamp
+            future_time = datetime.now() + timedelta(hours=1)
+            storage.set_status("core", TargetStatus.BUILT)
+            storage._results["core"] = BuildResult(
+                target="core",
+                status="built",
+                timestamp=future_time.isoformat(),
+            )
+
+            outdated = builder.detect_outdated()
+
+        assert "core" not in outdated
+
+    def test_detect_outdated_skips_non_built(self):
+        """Targets not in 'built' status are ignored."""
+        project = _make_project(features={"core": []})
+        builder, _, storage, vc = _make_builder(project=project)
+
+        storage.set_status("core", TargetStatus.PENDING)
+
+        outdated = builder.detect_outdated()
+
+        assert outdated == []
+
+    def test_detect_outdated_does_not_modify_state(self):
+        """detect_outdated() does not change any state."""
+        project = _make_project(features={"core": []})
+        builder, _, storage, vc = _make_builder(project=project)
+
+        storage.set_status("core", TargetStatus.BUILT)
+        storage._results["core"] = BuildResult(
+            target="core",
+            status="built",
+            timestamp=datetime.now().isoformat(),
+        )
+
+        builder.detect_outdated()
+
+        assert storage.get_status("core") == TargetStatus.BUILT
+
+
+# ---------------------------------------------------------------------------
+# Tests: Build context
+# ---------------------------------------------------------------------------
+
+
+class TestBuildContext:
+    """Tests for correct BuildContext construction."""
+
+    def test_build_context_has_dependency_names(self):
+        """BuildContext includes resolved dependency names."""
+        project = _make_project(features={"core": [], "api": ["core"]})
+        builder, agent, storage, vc = _make_builder(project=project)
+
+        with tempfile.TemporaryDirectory() as out_dir:
+            builder.build(BuildOptions(output_dir=out_dir))
+
+        # api's build context should have "core" as dependency
         api_ctx = [c for c in agent.build_calls if c.intent.name == "api"]
         assert len(api_ctx) == 1
         assert "core" in api_ctx[0].dependency_names
@@ -878,3 +2542,158 @@ class TestLogging:
         assert len(logs) > 0
         assert any("Build plan" in msg for msg in logs)
         assert any("core" in msg for msg in logs)
+
+
+class TestAgentCapabilities:
+    def _build(
+        self,
+        requires: list[str],
+        profiles: list[AgentProfile],
+        agent: str | None = None,
+        profile_override: str = "",
+    ):
+        project = _make_project(features={"core": [], "scraper": ["core"]})
+        project.features["scraper"].intents[0].requires_capabilities = requires
+        project.features["scraper"].intents[0].agent = agent
+        used: list[AgentProfile] = []
+
+        def create_agent(profile: AgentProfile) -> MockAgent:
+            used.append(profile)
+            return MockAgent()
+
+        with tempfile.TemporaryDirectory() as tmpdir:
+            builder = Builder(
+                project=project,
+                state_manager=StateManager(
+                    base_dir=Path(tmpdir), output_dir="src", backend=FakeStorageBackend()
+                ),
+                version_control=FakeVersionControl(),
+                agent_profile=AgentProfile(name="default", provider="cli"),
+                create_agent=create_agent,
+                agent_profiles=profiles,
+            )
+            results, error = builder.build(
+                BuildOptions(
+                    output_dir=os.path.join(tmpdir, "out"),
+                    profile_override=profile_override,
+                )
+            )
+        return results, error, [p.name for p in used]
+
+    def test_target_gets_profile_with_capability(self):
+        profiles = [
+            AgentProfile(name="decompiler", provider="cli", capabilities=["decompile"]),
+            AgentProfile(name="browser", provider="cli", capabilities=["web", "decompile"]),
+        ]
+
+        results, error, used = self._build(["web"], profiles)
+
+        assert error is None
+        assert used == ["default", "browser"]
+
+    def test_no_capable_profile_aborts_before_building(self):
+        profiles = [AgentProfile(name="decompiler", provider="cli", capabilities=["decompile"])]
+
+        results, error, used = self._build(["web"], profiles)
+
+        assert isinstance(error, NoCapableAgentError)
+        assert error.target == "scraper"
+        assert results == []
+        assert used == []
+
+    def test_intent_names_its_agent_profile(self):
+        profiles = [
+            AgentProfile(name="cheap", provider="cli"),
+            AgentProfile(name="heavy", provider="cli"),
+        ]
+
+        results, error, used = self._build([], profiles, agent="heavy")
+
+        assert error is None
+        # core has no agent: and falls back to the default profile
+        assert used == ["default", "heavy"]
+
+    def test_profile_flag_wins_over_intent_agent(self):
+        profiles = [AgentProfile(name="heavy", provider="cli")]
+
+        results, error, used = self._build([], profiles, agent="heavy", profile_override="fast")
+
+        assert error is None
+        assert used == ["fast", "fast"]
+
+    def test_unknown_agent_profile_aborts_before_building(self):
+        results, error, used = self._build([], [], agent="heavy")
+
+        assert isinstance(error, UnknownProfileError)
+        assert (error.target, error.profile) == ("scraper", "heavy")
+        assert used == []
+
+
+class _CheckpointVersionControl(FakeVersionControl):
+    """Fake version control serving file contents per checkpoint."""
+
+    def __init__(self, files: dict[tuple[str, str], bytes]) -> None:
+        super().__init__()
+        self.files = files
+
+    def read_file(self, commit_id: str, path: str) -> bytes:
+        return self.files[(commit_id, path)]
+
+
+class TestReplay:
+    def test_build_records_file_hashes(self, tmp_path):
+        import hashlib
+
+        builder, agent, storage, vc = _make_builder()
+        builder._state_manager._ownership = OwnershipIndex(tmp_path / "ownership.json")
+        (tmp_path / "out").mkdir()
+        (tmp_path / "out" / "main.py").write_text("print(1)\n")
+        agent._build_response = BuildResponse(
+            status="success", summary="ok", files_created=["main.py", "gone.py"]
+        )
+
+        results, _ = builder.build(
+            BuildOptions(target="core", output_dir=str(tmp_path / "out"))
+        )
+
+        expected = hashlib.sha256(b"print(1)\n").hexdigest()
+        assert results[0].file_hashes == {"main.py": expected}
+        assert storage._results["core"].file_hashes == {"main.py": expected}
+        assert results[0].file_sources == {"main.py": "agent", "gone.py": "agent"}
+
+    def test_replay_writes_files_and_reports_mismatches(self, tmp_path):
+        import hashlib
+
+        vc = _CheckpointVersionControl(
+            {("c1", "src/a.py"): b"A\n", ("c1", "src/pkg/b.py"): b"B\n"}
+        )
+        builder, _, _, _ = _make_builder(vc=vc)
+        builder._state_manager._base_dir = tmp_path
+        result = BuildResult(
+            target="core",
+            generation_id="gen-1",
+            commit_id="c1",
+            file_hashes={
+                "a.py": hashlib.sha256(b"A\n").hexdigest(),
+                "pkg/b.py": "recorded-before-an-edit",
+            },
+        )
+
+        mismatched = builder.replay(result, "src")
+
+        assert mismatched == ["pkg/b.py"]
+        assert not (tmp_path / "src").exists()
+
+        mismatched = builder.replay(result, "src", force=True)
+
+        assert (tmp_path / "src" / "a.py").read_bytes() == b"A\n"
+        assert (tmp_path / "src" / "pkg" / "b.py").read_bytes() == b"B\n"
+        assert mismatched == ["pkg/b.py"]
+
+    def test_replay_requires_checkpoint_and_files(self):
+        builder, _, _, _ = _make_builder()
+
+        with pytest.raises(ValueError, match="no checkpoint"):
+            builder.replay(BuildResult(target="core", generation_id="g"), "src")
+        with pytest.raises(ValueError, match="no recorded files"):
+            builder.replay(BuildResult(target="core", generation_id="g", commit_id="c"), "src")
@@ -0,0 +1,38 @@
+"""Tests for cost-aware build ordering."""
+
+from __future__ import annotations
+
+from intentc.build.builder.schedule import makespan, weighted_order
+
+
+class TestWeightedOrder:
+    def test_equal_weights_keep_topological_order(self):
+        order = ["core", "left", "right", "top"]
+        deps = {"left": ["core"], "right": ["core"], "top": ["left", "right"]}
+
+        assert weighted_order(order, deps, {}) == order
+
+    def test_longest_ready_target_first(self):
+        order = ["core", "left", "right", "top"]
+        deps = {"left": ["core"], "right": ["core"], "top": ["left", "right"]}
+
+        result = weighted_order(order, deps, {"left": 5, "right": 60})
+
+        assert result == ["core", "right", "left", "top"]
+
+    def test_unknown_durations_weigh_the_average(self):
+        order = ["a", "b", "c"]
+
+        # The average of 10 and 30 puts the never-built "b" between them
+        assert weighted_order(order, {}, {"a": 10, "c": 30}) == ["c", "b", "a"]
+
+    def test_skewed_durations_shorten_makespan(self):
+        order = ["q1", "q2", "q3", "q4", "slow", "done"]
+        deps = {"done": ["q1", "q2", "q3", "q4", "slow"]}
+        durations = {"q1": 1, "q2": 1, "q3": 1, "q4": 1, "slow": 4, "done": 1}
+
+        weighted = weighted_order(order, deps, durations)
+
+        assert weighted[0] == "slow"
+        assert makespan(order, deps, durations, workers=2) == 7
+        assert makespan(weighted, deps, durations, workers=2) == 5
@@ -0,0 +1,56 @@
+"""Build event stream: append-only JSONL records for tooling integration."""
+
+from __future__ import annotations
+
+import json
+import threading
+from datetime import datetime, timezone
+from pathlib import Path
+from typing import IO
+
+from intentc.build.redact import redact_json
+
+
+def default_events_path(base_dir: Path, now: datetime | None = None) -> Path:
+    """Return ``.intentc/logs/build-<timestamp>.jsonl`` under *base_dir*."""
+    stamp = (now or datetime.now()).strftime("%Y%m%d-%H%M%S")
+    return base_dir / ".intentc" / "logs" / f"build-{stamp}.jsonl"
+
+
+class EventLog:
+    """Writes one JSON object per line, each with a ``type`` and ``timestamp``.
+
+    Unlike the human-readable log callback, the event stream is meant to be
+    parsed: every line is flushed as soon as it is written so consumers can
+    tail the file while a build is running. Emitting is thread-safe, so
+    targets building in parallel can share one log. Secrets in string
+    fields are redacted before they are written.
+    """
+
+    def __init__(self, path: Path) -> None:
+        self._path = path
+        self._file: IO[str] | None = None
+        self._lock = threading.Lock()
+
+    @property
+    def path(self) -> Path:
+        return self._path
+
+    def emit(self, event_type: str, **fields: object) -> None:
+        """Append an event of *event_type* with the given fields."""
+        record = {
+            "type": event_type,
+            "timestamp": datetime.now(timezone.utc).isoformat(),
+            **fields,
+        }
+        with self._lock:
+            if self._file is None:
+                self._path.parent.mkdir(parents=True, exist_ok=True)
+                self._file = open(self._path, "a", encoding="utf-8")
+            self._file.write(json.dumps(redact_json(record)) + "\n")
+            self._file.flush()
+
+    def close(self) -> None:
+        if self._file is not None:
+            self._file.close()
+            self._file = None
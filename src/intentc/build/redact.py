@@ -0,0 +1,51 @@
+"""Secret redaction for log lines and stored agent output."""
+
+from __future__ import annotations
+
+import re
+from typing import Any
+
+# Regexes for common credential formats. A match is replaced whole, so
+# patterns that include a key name (e.g. "password=...") hide the name too.
+DEFAULT_REDACT_PATTERNS: list[str] = [
+    # AWS access key IDs and secret keys given as assignments
+    r"\b(?:AKIA|ASIA)[0-9A-Z]{16}\b",
+    r"(?i)aws_secret_access_key\s*[=:]\s*\S+",
+    # Authorization headers
+    r"(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{8,}",
+    # Provider API keys (sk-..., sk-ant-...) and GitHub tokens
+    r"\bsk-[A-Za-z0-9_-]{20,}",
+    r"\bgh[pousr]_[A-Za-z0-9]{36,}\b",
+]
+
+REDACTED = "[REDACTED]"
+
+_patterns: list[re.Pattern[str]] = [re.compile(p) for p in DEFAULT_REDACT_PATTERNS]
+
+
+def set_redact_patterns(patterns: list[str]) -> None:
+    """Replace the process-wide redaction patterns.
+
+    Raises re.error if a pattern does not compile; the previous patterns
+    stay in effect.
+    """
+    global _patterns
+    _patterns = [re.compile(p) for p in patterns]
+
+
+def redact(text: str) -> str:
+    """Return *text* with every match of the redaction patterns replaced."""
+    for pattern in _patterns:
+        text = pattern.sub(REDACTED, text)
+    return text
+
+
+def redact_json(value: Any) -> Any:
+    """Redact every string inside a JSON-like structure, keeping its shape."""
+    if isinstance(value, str):
+        return redact(value)
+    if isinstance(value, dict):
+        return {k: redact_json(v) for k, v in value.items()}
+    if isinstance(value, list):
+        return [redact_json(v) for v in value]
+    return value
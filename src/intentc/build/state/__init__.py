@@ -2,17 +2,34 @@
 
 from intentc.build.storage.backend import BuildResult, BuildStep, TargetStatus
 
+from intentc.build.state.baseline import ValidationBaseline, default_baseline_path
+from intentc.build.state.lock import BuildLock
+from intentc.build.state.ownership import OwnershipIndex
 from intentc.build.state.state import (
     GitVersionControl,
     StateManager,
+    TargetDrift,
     VersionControl,
+    diff_build_states,
+    recorded_output_dirs,
+    target_slug,
+    worktree_path,
 )
 
 __all__ = [
+    "BuildLock",
     "BuildResult",
     "BuildStep",
     "GitVersionControl",
+    "OwnershipIndex",
     "StateManager",
+    "TargetDrift",
     "TargetStatus",
+    "ValidationBaseline",
     "VersionControl",
+    "default_baseline_path",
+    "diff_build_states",
+    "recorded_output_dirs",
+    "target_slug",
+    "worktree_path",
 ]
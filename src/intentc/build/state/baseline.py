@@ -0,0 +1,85 @@
+"""Validation baseline: a snapshot of validation results to measure regressions against."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+# target -> validation name -> status ("pass", "fail", "skipped", ...)
+Statuses = dict[str, dict[str, str]]
+
+
+def default_baseline_path(base_dir: Path) -> Path:
+    """Return ``.intentc/validation-baseline.json`` under *base_dir*."""
+    return base_dir / ".intentc" / "validation-baseline.json"
+
+
+class ValidationBaseline:
+    """Validation statuses recorded at one point in time, persisted as JSON.
+
+    Comparing a later run against the baseline separates regressions
+    (validations that passed then and fail now) from failures that were
+    already there or are new, so a project can adopt validations gradually.
+    """
+
+    def __init__(self, path: Path) -> None:
+        self._path = path
+
+    @property
+    def path(self) -> Path:
+        return self._path
+
+    def exists(self) -> bool:
+        return self._path.exists()
+
+    def read(self) -> Statuses:
+        """Return the recorded statuses, or {} if there is no usable baseline."""
+        try:
+            data = json.loads(self._path.read_text(encoding="utf-8"))
+        except (json.JSONDecodeError, OSError):
+            return {}
+        if not isinstance(data, dict):
+            return {}
+        return {t: v for t, v in data.items() if isinstance(v, dict)}
+
+    def write(self, statuses: Statuses) -> None:
+        self._path.parent.mkdir(parents=True, exist_ok=True)
+        self._path.write_text(
+            json.dumps(statuses, indent=2, sort_keys=True), encoding="utf-8"
+        )
+
+    def update(self, statuses: Statuses) -> None:
+        """Merge *statuses* into the recorded baseline and write it.
+
+        Targets and validations that did not run keep their recorded status,
+        so a partial run (one target, --skip/--only) refines the baseline
+        instead of replacing it.
+        """
+        recorded = self.read()
+        for target, results in statuses.items():
+            recorded.setdefault(target, {}).update(results)
+        self.write(recorded)
+
+    def compare(
+        self, statuses: Statuses
+    ) -> tuple[list[tuple[str, str]], list[tuple[str, str]]]:
+        """Split the failures in *statuses* into (regressions, other failures).
+
+        A regression is a validation the baseline recorded as passing. Every
+        other failure, including one the baseline has never seen, lands in
+        the second list. Skipped validations are not failures: the
+        prerequisite that caused the skip is reported instead. Both lists
+        hold (target, name) pairs in sorted order.
+        """
+        recorded = self.read()
+        regressions: list[tuple[str, str]] = []
+        others: list[tuple[str, str]] = []
+        for target in sorted(statuses):
+            for name, status in sorted(statuses[target].items()):
+                if status in ("pass", "skipped"):
+                    continue
+                if recorded.get(target, {}).get(name) == "pass":
+                    regressions.append((target, name))
+                else:
+                    others.append((target, name))
+        return regressions, others
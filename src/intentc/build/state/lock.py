@@ -0,0 +1,88 @@
+"""Build lock: records the running build so an interrupted one can be detected."""
+
+from __future__ import annotations
+
+import json
+import os
+from datetime import datetime, timezone
+from pathlib import Path
+from typing import Any
+
+
+def _pid_alive(pid: int) -> bool:
+    """Return True if a process with *pid* exists."""
+    try:
+        os.kill(pid, 0)
+    except ProcessLookupError:
+        return False
+    except PermissionError:
+        # Exists but owned by someone else
+        return True
+    except OSError:
+        return False
+    return True
+
+
+class BuildLock:
+    """A JSON lock file held for the duration of a build.
+
+    The file records the owning PID, the generation ID and the requested
+    target. It is removed when a build finishes normally, so a lock whose PID
+    is no longer running marks a build that was killed part-way through.
+    """
+
+    def __init__(self, path: Path) -> None:
+        self._path = path
+
+    @property
+    def path(self) -> Path:
+        return self._path
+
+    def read(self) -> dict[str, Any] | None:
+        """Return the lock contents, or None if no lock is held."""
+        if not self._path.exists():
+            return None
+        try:
+            data = json.loads(self._path.read_text(encoding="utf-8"))
+        except (json.JSONDecodeError, OSError):
+            # An unreadable lock is as good as a stale one
+            return {}
+        return data if isinstance(data, dict) else {}
+
+    def is_active(self, info: dict[str, Any]) -> bool:
+        """True if *info* belongs to another build that is still running."""
+        pid = info.get("pid")
+        if not isinstance(pid, int) or pid == os.getpid():
+            return False
+        return _pid_alive(pid)
+
+    def acquire(self, generation_id: str, target: str, tag: str = "") -> bool:
+        """Create the lock file; False if another build created it first.
+
+        The file is created with O_EXCL, so of two builds racing past
+        read() only one gets the lock.
+        """
+        self._path.parent.mkdir(parents=True, exist_ok=True)
+        try:
+            fd = os.open(self._path, os.O_WRONLY | os.O_CREAT | os.O_EXCL, 0o644)
+        except FileExistsError:
+            return False
+        with os.fdopen(fd, "w", encoding="utf-8") as fh:
+            fh.write(
+                json.dumps(
+                    {
+                        "pid": os.getpid(),
+                        "generation_id": generation_id,
+                        "target": target,
+                        "tag": tag,
+                        "started_at": datetime.now(timezone.utc).isoformat(),
+                    }
+                )
+            )
+        return True
+
+    def release(self) -> None:
+        try:
+            self._path.unlink()
+        except FileNotFoundError:
+            pass
@@ -0,0 +1,86 @@
+"""Ownership index: which target generated each file in the output tree."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+
+class OwnershipIndex:
+    """Project-level map of generated path -> owning target and generation.
+
+    Persisted as JSON so that every output directory and every command sees
+    the same view. Paths are stored as given (typically ``<output_dir>/<file>``).
+    """
+
+    def __init__(self, path: Path) -> None:
+        self._path = path
+        self._entries: dict[str, dict[str, str]] | None = None
+
+    @property
+    def path(self) -> Path:
+        return self._path
+
+    def _load(self) -> dict[str, dict[str, str]]:
+        if self._entries is None:
+            self._entries = {}
+            if self._path.exists():
+                try:
+                    data = json.loads(self._path.read_text(encoding="utf-8"))
+                except (json.JSONDecodeError, OSError):
+                    data = {}
+                if isinstance(data, dict):
+                    self._entries = {
+                        k: v for k, v in data.items() if isinstance(v, dict)
+                    }
+        return self._entries
+
+    def owner(self, path: str) -> str | None:
+        """Return the target that owns *path*, or None if unowned."""
+        entry = self._load().get(path)
+        return entry.get("target") if entry else None
+
+    def source(self, path: str) -> str:
+        """How *path* was found to be generated (see BuildResult.file_sources);
+        ``""`` if unowned or claimed without one."""
+        entry = self._load().get(path)
+        return entry.get("source", "") if entry else ""
+
+    def claim(
+        self, path: str, target: str, generation_id: str, source: str = ""
+    ) -> str | None:
+        """Record *target* as the owner of *path*.
+
+        Returns the previous owner if it was a different target, else None.
+        """
+        entries = self._load()
+        previous = self.owner(path)
+        entries[path] = {"target": target, "generation_id": generation_id}
+        if source:
+            entries[path]["source"] = source
+        return previous if previous not in (None, target) else None
+
+    def files_for(self, target: str, output_dir: str = "") -> list[str]:
+        """Return all paths owned by *target*, sorted.
+
+        With *output_dir*, only paths inside that output directory are returned.
+        """
+        prefix = f"{Path(output_dir).as_posix()}/" if output_dir else ""
+        return sorted(
+            p
+            for p, e in self._load().items()
+            if e.get("target") == target and p.startswith(prefix)
+        )
+
+    def release(self, target: str, output_dir: str = "") -> None:
+        """Drop the paths owned by *target* (within *output_dir*, if given)."""
+        entries = self._load()
+        for path in self.files_for(target, output_dir):
+            del entries[path]
+
+    def save(self) -> None:
+        """Write the index to disk."""
+        self._path.parent.mkdir(parents=True, exist_ok=True)
+        self._path.write_text(
+            json.dumps(self._load(), indent=2, sort_keys=True), encoding="utf-8"
+        )
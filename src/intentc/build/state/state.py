@@ -4,8 +4,11 @@ from __future__ import annotations
 
 import abc
 import subprocess
+from dataclasses import dataclass, field
 from pathlib import Path
 
+from intentc.build.state.lock import BuildLock
+from intentc.build.state.ownership import OwnershipIndex
 from intentc.build.storage.backend import BuildResult, StorageBackend, TargetStatus
 from intentc.build.storage.sqlite_backend import SQLiteBackend
 
@@ -14,8 +17,11 @@ class VersionControl(abc.ABC):
     """Abstract interface for checkpointing file changes."""
 
     @abc.abstractmethod
-    def checkpoint(self, message: str) -> str:
-        """Snapshot current changes, return a unique commit/checkpoint ID."""
+    def checkpoint(self, message: str, paths: list[str] | None = None) -> str:
+        """Snapshot current changes, return a unique commit/checkpoint ID.
+
+        With *paths*, only changes to those paths are included.
+        """
 
     @abc.abstractmethod
     def diff(self, from_id: str, to_id: str) -> str:
@@ -29,6 +35,42 @@ class VersionControl(abc.ABC):
     def log(self, target: str | None = None) -> list[str]:
         """List checkpoint IDs, optionally filtered by target."""
 
+    def worktree(self, path: Path, branch: str) -> VersionControl:
+        """Return version control for a worktree at *path* checked out to *branch*.
+
+        The worktree is created if it does not exist yet.
+        """
+        raise NotImplementedError(
+            f"{type(self).__name__} does not support build worktrees"
+        )
+
+    def read_file(self, commit_id: str, path: str) -> bytes:
+        """Return the content of *path* as of checkpoint *commit_id*."""
+        raise NotImplementedError(
+            f"{type(self).__name__} cannot read files from checkpoints"
+        )
+
+
+def target_slug(target: str) -> str:
+    """Make a target name safe for use in a file name."""
+    return target.replace("/", "_").replace(":", "__")
+
+
+def worktree_path(base_dir: Path, name: str) -> Path:
+    """Return ``.intentc/worktrees/<name>`` under *base_dir*."""
+    return base_dir / ".intentc" / "worktrees" / name
+
+
+def recorded_output_dirs(base_dir: Path) -> list[str]:
+    """Return every output directory with build state under *base_dir*, sorted."""
+    state_root = base_dir / ".intentc" / "state"
+    if not state_root.is_dir():
+        return []
+    return sorted(
+        db.parent.relative_to(state_root).as_posix()
+        for db in state_root.rglob("intentc.db")
+    )
+
 
 class GitVersionControl(VersionControl):
     """Concrete VersionControl backed by git."""
@@ -46,17 +88,98 @@ class GitVersionControl(VersionControl):
         )
         return result.stdout.strip()
 
-    def checkpoint(self, message: str) -> str:
-        self._run("add", "-A")
-        self._run("commit", "-m", message, "--allow-empty")
+    def checkpoint(self, message: str, paths: list[str] | None = None) -> str:
+        if paths is None:
+            self._run("add", "-A")
+            self._run("commit", "-m", message, "--allow-empty")
+            return self._run("rev-parse", "HEAD")
+        # git add rejects a path that is neither on disk nor tracked
+        present = [
+            p for p in paths
+            if (self._repo_dir / p).exists() or self._run("ls-files", "--", p)
+        ]
+        if present:
+            self._run("add", "-A", "--", *present)
+        # --only: whatever else is staged stays out of this commit
+        self._run("commit", "-m", message, "--allow-empty", "--only", "--", *present)
         return self._run("rev-parse", "HEAD")
 
-    def diff(self, from_id: str, to_id: str) -> str:
+    def commit_paths(self, paths: list[str], message: str) -> str | None:
+        """Stage and commit only *paths*; return the commit ID.
+
+        Returns None without doing anything when the directory is not inside
+        a git work tree or none of *paths* changed.
+        """
+        try:
+            self._run("rev-parse", "--is-inside-work-tree")
+        except (subprocess.CalledProcessError, FileNotFoundError):
+            return None
+        if not paths:
+            return None
+        self._run("add", "--", *paths)
+        if not self._run("diff", "--cached", "--name-only", "--", *paths):
+            return None
+        self._run("commit", "-m", message, "--", *paths)
+        return self._run("rev-parse", "HEAD")
+
+    def commit_removal(self, path: str, message: str) -> str | None:
+        """Commit the deletion of everything tracked under *path*.
+
+        Returns None when the directory is not inside a git work tree or
+        nothing under *path* was tracked.
+        """
+        try:
+            self._run("rev-parse", "--is-inside-work-tree")
+        except (subprocess.CalledProcessError, FileNotFoundError):
+            return None
+        self._run("rm", "-r", "-q", "--cached", "--ignore-unmatch", "--", path)
+        if not self._run("diff", "--cached", "--name-only", "--", path):
+            return None
+        self._run("commit", "-m", message, "--", path)
+        return self._run("rev-parse", "HEAD")
+
+    def diff(self, from_id: str, to_id: str, paths: list[str] | None = None) -> str:
+        if paths is not None:
+            return self._run("diff", from_id, to_id, "--", *paths) if paths else ""
         return self._run("diff", from_id, to_id)
 
+    def changed_files(self, commit_id: str) -> list[str]:
+        """Return the paths a checkpoint commit added, changed or removed."""
+        output = self._run(
+            "diff-tree", "--no-commit-id", "--name-only", "-r", "--root", commit_id
+        )
+        return output.splitlines() if output else []
+
+    def file_changes(
+        self, from_id: str, to_id: str, paths: list[str]
+    ) -> list[tuple[str, str]]:
+        """Return ``(status, path)`` pairs for *paths* between two commits.
+
+        Status is git's letter: ``A`` added, ``D`` removed, ``M`` modified.
+        """
+        if not paths:
+            return []
+        output = self._run(
+            "diff", "--name-status", "--no-renames", from_id, to_id, "--", *paths
+        )
+        changes: list[tuple[str, str]] = []
+        for line in output.splitlines():
+            status, _, path = line.partition("\t")
+            changes.append((status[:1], path))
+        return changes
+
     def restore(self, commit_id: str) -> None:
         self._run("checkout", commit_id, "--", ".")
 
+    def read_file(self, commit_id: str, path: str) -> bytes:
+        # Not through _run: the content may be binary
+        return subprocess.run(
+            ["git", "show", f"{commit_id}:./{path}"],
+            cwd=str(self._repo_dir),
+            capture_output=True,
+            check=True,
+        ).stdout
+
     def log(self, target: str | None = None) -> list[str]:
         if target:
             output = self._run("log", "--format=%H", "--grep", target)
@@ -66,6 +189,41 @@ class GitVersionControl(VersionControl):
             return []
         return output.splitlines()
 
+    def worktree(self, path: Path, branch: str) -> GitVersionControl:
+        if not (path / ".git").exists():
+            path.parent.mkdir(parents=True, exist_ok=True)
+            # -B reuses the branch if an earlier worktree was removed
+            self._run("worktree", "add", "-B", branch, str(path))
+        self._exclude(path)
+        return GitVersionControl(repo_dir=path)
+
+    def _exclude(self, path: Path) -> None:
+        """List *path* in .git/info/exclude so ``git add -A`` skips it.
+
+        A worktree inside the repository would otherwise be staged as an
+        embedded repository by the next checkpoint.
+        """
+        top = Path(self._run("rev-parse", "--show-toplevel")).resolve()
+        try:
+            rel = path.resolve().relative_to(top)
+        except ValueError:
+            return
+        exclude = Path(self._run("rev-parse", "--git-path", "info/exclude"))
+        if not exclude.is_absolute():
+            exclude = self._repo_dir / exclude
+        pattern = f"/{rel.as_posix()}/"
+        text = exclude.read_text(encoding="utf-8") if exclude.exists() else ""
+        if pattern in text.splitlines():
+            return
+        exclude.parent.mkdir(parents=True, exist_ok=True)
+        if text and not text.endswith("\n"):
+            text += "\n"
+        exclude.write_text(text + pattern + "\n", encoding="utf-8")
+
+    def remove_worktree(self, path: Path) -> None:
+        """Remove the worktree at *path*; its branch and commits are kept."""
+        self._run("worktree", "remove", "--force", str(path))
+
 
 class StateManager:
     """Manages per-target state for a given output directory.
@@ -84,12 +242,50 @@ class StateManager:
         self._backend = backend or SQLiteBackend(base_dir, output_dir)
 
         # Response file directories
-        resp_base = base_dir / ".intentc" / "state" / output_dir / "responses"
+        state_dir = base_dir / ".intentc" / "state" / output_dir
+        resp_base = state_dir / "responses"
         self._build_response_dir = resp_base / "build"
         self._val_response_dir = resp_base / "val"
         self._build_response_dir.mkdir(parents=True, exist_ok=True)
         self._val_response_dir.mkdir(parents=True, exist_ok=True)
 
+        # Ownership is shared across output directories, so it lives one level up
+        self._ownership = OwnershipIndex(
+            base_dir / ".intentc" / "state" / "ownership.json"
+        )
+        self._build_lock = BuildLock(state_dir / "build.lock")
+        self._builds_dir = state_dir / "builds"
+
+    @property
+    def base_dir(self) -> Path:
+        return self._base_dir
+
+    @property
+    def output_dir(self) -> str:
+        return self._output_dir
+
+    @property
+    def ownership(self) -> OwnershipIndex:
+        return self._ownership
+
+    def generated_files(self, target: str) -> list[str]:
+        """Files *target* generated in this output directory, relative to it."""
+        if not self._output_dir:
+            return self._ownership.files_for(target)
+        prefix = f"{Path(self._output_dir).as_posix()}/"
+        return [
+            p[len(prefix):]
+            for p in self._ownership.files_for(target, self._output_dir)
+        ]
+
+    def output_log_path(self, target: str, generation_id: str) -> Path:
+        """Where the raw agent output for a target's generation is kept."""
+        return self._builds_dir / target_slug(target) / f"{generation_id}.output.txt"
+
+    @property
+    def build_lock(self) -> BuildLock:
+        return self._build_lock
+
     @property
     def build_response_dir(self) -> Path:
         return self._build_response_dir
@@ -108,6 +304,18 @@ class StateManager:
     def get_build_result(self, target: str) -> BuildResult | None:
         return self._backend.get_build_result(target)
 
+    def get_build_history(self, target: str, limit: int = 50) -> list[BuildResult]:
+        return self._backend.get_build_history(target, limit)
+
+    def recent_failures(self, target: str) -> list[BuildResult]:
+        """Failed results of *target* since its last successful build, newest first."""
+        failures: list[BuildResult] = []
+        for result in self._backend.get_build_history(target):
+            if result.status != "failed":
+                break
+            failures.append(result)
+        return failures
+
     def save_build_result(self, target: str, result: BuildResult) -> None:
         self._backend.save_build_result(target, result)
 
@@ -128,8 +336,75 @@ class StateManager:
     def reset(self, target: str) -> None:
         self._backend.reset(target)
 
+    def validation_results(self, target: str) -> tuple[str, dict[str, str]]:
+        """The generation *target* was last validated under, and each
+        validation's status then; ``("", {})`` if it never was."""
+        return self._backend.latest_validation_results(target)
+
+    def delete_build_results(self, target: str) -> int:
+        """Forget *target*'s recorded builds in this output directory."""
+        return self._backend.delete_build_results(target)
+
     def reset_all(self) -> None:
         self._backend.reset_all()
 
     def list_targets(self) -> list[tuple[str, TargetStatus]]:
         return self._backend.list_targets()
+
+
+@dataclass
+class TargetDrift:
+    """How one target differs between two output directories' build state."""
+
+    target: str
+    status_a: str | None
+    status_b: str | None
+    generation_a: str | None = None
+    generation_b: str | None = None
+    files_only_a: list[str] = field(default_factory=list)
+    files_only_b: list[str] = field(default_factory=list)
+
+    @property
+    def description(self) -> str:
+        if self.status_b is None:
+            return "only in A"
+        if self.status_a is None:
+            return "only in B"
+        parts = []
+        if self.status_a != self.status_b:
+            parts.append("status differs")
+        if self.generation_a != self.generation_b:
+            parts.append("generation differs")
+        if self.files_only_a or self.files_only_b:
+            parts.append("files differ")
+        return ", ".join(parts)
+
+
+def diff_build_states(a: StateManager, b: StateManager) -> list[TargetDrift]:
+    """Compare the recorded state of two output directories.
+
+    Returns one entry per target that is tracked in only one of them, or whose
+    status, latest generation ID or generated file list differs, sorted by
+    target name.
+    """
+    statuses_a = {t: s.value for t, s in a.list_targets()}
+    statuses_b = {t: s.value for t, s in b.list_targets()}
+
+    drifts: list[TargetDrift] = []
+    for target in sorted(statuses_a.keys() | statuses_b.keys()):
+        result_a = a.get_build_result(target) if target in statuses_a else None
+        result_b = b.get_build_result(target) if target in statuses_b else None
+        files_a = set(a.generated_files(target))
+        files_b = set(b.generated_files(target))
+        drift = TargetDrift(
+            target=target,
+            status_a=statuses_a.get(target),
+            status_b=statuses_b.get(target),
+            generation_a=result_a.generation_id if result_a else None,
+            generation_b=result_b.generation_id if result_b else None,
+            files_only_a=sorted(files_a - files_b),
+            files_only_b=sorted(files_b - files_a),
+        )
+        if drift.description:
+            drifts.append(drift)
+    return drifts
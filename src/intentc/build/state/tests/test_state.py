@@ -3,6 +3,8 @@
 from __future__ import annotations
 
 import json
+import shutil
+import subprocess
 import tempfile
 import uuid
 from datetime import datetime, timezone
@@ -11,12 +13,19 @@ from pathlib import Path
 import pytest
 
 from intentc.build.state import (
+    BuildLock,
     BuildResult,
     BuildStep,
     GitVersionControl,
+    OwnershipIndex,
     StateManager,
     TargetStatus,
+    ValidationBaseline,
     VersionControl,
+    default_baseline_path,
+    diff_build_states,
+    recorded_output_dirs,
+    worktree_path,
 )
 from intentc.build.storage import SQLiteBackend
 from intentc.core.project import FeatureNode, Project
@@ -36,6 +45,15 @@ def backend(tmp_dir: Path) -> SQLiteBackend:
     be.close()
 
 
+@pytest.fixture()
+def git_identity(monkeypatch):
+    """Give git commits made by the code under test an author."""
+    for var in ("GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"):
+        monkeypatch.setenv(var, "t")
+    for var in ("GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"):
+        monkeypatch.setenv(var, "t@example.com")
+
+
 @pytest.fixture()
 def state_manager(tmp_dir: Path, backend: SQLiteBackend) -> StateManager:
     return StateManager(base_dir=tmp_dir, output_dir="src", backend=backend)
@@ -432,3 +450,376 @@ class TestResponseFileCleanup:
         assert len(list(sm.val_response_dir.iterdir())) == 0
 
         be.close()
+
+
+# ---------------------------------------------------------------------------
+# Ownership index
+# ---------------------------------------------------------------------------
+
+
+class TestOwnershipIndex:
+    def test_index_lives_under_project_state(self, state_manager: StateManager, tmp_dir: Path):
+        assert state_manager.ownership.path == tmp_dir / ".intentc" / "state" / "ownership.json"
+
+    def test_claim_reports_previous_owner(self, tmp_dir: Path):
+        index = OwnershipIndex(tmp_dir / "ownership.json")
+        assert index.claim("src/config.yaml", "core", "gen-1") is None
+        assert index.claim("src/config.yaml", "core", "gen-2") is None
+        assert index.claim("src/config.yaml", "api", "gen-3") == "core"
+        assert index.owner("src/config.yaml") == "api"
+
+    def test_claim_records_source(self, tmp_dir: Path):
+        index = OwnershipIndex(tmp_dir / "ownership.json")
+        index.claim("src/a.py", "core", "gen-1", source="agent")
+        index.claim("src/b.py", "core", "gen-1")
+
+        assert index.source("src/a.py") == "agent"
+        assert index.source("src/b.py") == ""
+        assert index.source("src/unowned.py") == ""
+
+    def test_save_and_reload(self, tmp_dir: Path):
+        path = tmp_dir / "state" / "ownership.json"
+        index = OwnershipIndex(path)
+        index.claim("src/a.py", "core", "gen-1")
+        index.claim("src/b.py", "api", "gen-1")
+        index.save()
+
+        reloaded = OwnershipIndex(path)
+        assert reloaded.files_for("core") == ["src/a.py"]
+        assert reloaded.owner("src/b.py") == "api"
+
+    def test_release_drops_only_target_entries(self, tmp_dir: Path):
+        index = OwnershipIndex(tmp_dir / "ownership.json")
+        index.claim("src/a.py", "core", "gen-1")
+        index.claim("src/b.py", "api", "gen-1")
+        index.release("core")
+        assert index.files_for("core") == []
+        assert index.files_for("api") == ["src/b.py"]
+
+    def test_files_and_release_scoped_to_output_dir(self, tmp_dir: Path):
+        index = OwnershipIndex(tmp_dir / "ownership.json")
+        index.claim("src/a.py", "core", "gen-1")
+        index.claim("srcgen/a.py", "core", "gen-1")
+        index.claim("out/a.py", "core", "gen-2")
+        assert index.files_for("core", "src") == ["src/a.py"]
+
+        index.release("core", "out")
+        assert index.files_for("core") == ["src/a.py", "srcgen/a.py"]
+
+
+# ---------------------------------------------------------------------------
+# Build lock
+# ---------------------------------------------------------------------------
+
+
+class TestBuildLock:
+    def test_lock_lives_in_output_state_dir(self, state_manager: StateManager, tmp_dir: Path):
+        assert state_manager.build_lock.path == tmp_dir / ".intentc" / "state" / "src" / "build.lock"
+
+    def test_acquire_read_release(self, tmp_dir: Path):
+        import os
+
+        lock = BuildLock(tmp_dir / "build.lock")
+        assert lock.read() is None
+
+        assert lock.acquire("gen-1", "core") is True
+        info = lock.read()
+        assert info["pid"] == os.getpid()
+        assert info["generation_id"] == "gen-1"
+        assert info["target"] == "core"
+        # Our own lock never counts as another running build
+        assert lock.is_active(info) is False
+
+        lock.release()
+        assert lock.read() is None
+
+    def test_acquire_fails_while_held(self, tmp_dir: Path):
+        lock = BuildLock(tmp_dir / "build.lock")
+        assert lock.acquire("gen-1", "core") is True
+        assert lock.acquire("gen-2", "api") is False
+        assert lock.read()["generation_id"] == "gen-1"
+
+    def test_dead_pid_is_not_active(self, tmp_dir: Path):
+        import subprocess
+
+        proc = subprocess.Popen(["true"])
+        proc.wait()
+        lock = BuildLock(tmp_dir / "build.lock")
+        assert lock.is_active({"pid": proc.pid}) is False
+
+    def test_unreadable_lock_reads_as_stale(self, tmp_dir: Path):
+        path = tmp_dir / "build.lock"
+        path.write_text("not json")
+        lock = BuildLock(path)
+        assert lock.read() == {}
+        assert lock.is_active({}) is False
+
+
+class TestValidationBaseline:
+    def test_write_and_read(self, tmp_dir: Path):
+        baseline = ValidationBaseline(default_baseline_path(tmp_dir))
+        assert not baseline.exists()
+        assert baseline.read() == {}
+
+        baseline.write({"core": {"builds": "pass"}})
+        assert baseline.path == tmp_dir / ".intentc" / "validation-baseline.json"
+        assert baseline.read() == {"core": {"builds": "pass"}}
+
+    def test_update_merges_into_recorded(self, tmp_dir: Path):
+        baseline = ValidationBaseline(tmp_dir / "baseline.json")
+        baseline.write({"core": {"builds": "pass", "lint": "fail"}, "web": {"ui": "pass"}})
+
+        baseline.update({"core": {"lint": "pass"}, "api": {"ok": "pass"}})
+
+        assert baseline.read() == {
+            "core": {"builds": "pass", "lint": "pass"},
+            "web": {"ui": "pass"},
+            "api": {"ok": "pass"},
+        }
+
+    def test_compare_separates_regressions(self, tmp_dir: Path):
+        baseline = ValidationBaseline(tmp_dir / "baseline.json")
+        baseline.write({"core": {"builds": "pass", "lint": "fail", "web": "pass"}})
+
+        regressions, others = baseline.compare(
+            {
+                "core": {"builds": "fail", "lint": "fail", "web": "skipped"},
+                "api": {"new-check": "fail", "ok": "pass"},
+            }
+        )
+        assert regressions == [("core", "builds")]
+        assert others == [("api", "new-check"), ("core", "lint")]
+
+    def test_unreadable_baseline_is_empty(self, tmp_dir: Path):
+        path = tmp_dir / "baseline.json"
+        path.write_text("[1, 2]")
+        assert ValidationBaseline(path).read() == {}
+
+
+class TestOutputLog:
+    def test_output_log_path(self, state_manager: StateManager, tmp_dir: Path):
+        path = state_manager.output_log_path("api/gateway:rest", "gen-1")
+        assert path == (
+            tmp_dir / ".intentc" / "state" / "src" / "builds"
+            / "api_gateway__rest" / "gen-1.output.txt"
+        )
+
+
+class TestDiffBuildStates:
+    def _state(self, tmp_dir: Path, output_dir: str) -> StateManager:
+        return StateManager(
+            base_dir=tmp_dir,
+            output_dir=output_dir,
+            backend=SQLiteBackend(base_dir=tmp_dir, output_dir=output_dir),
+        )
+
+    def _record(self, sm: StateManager, target: str, gen: str, files: list[str]) -> None:
+        sm.save_build_result(target, _make_build_result(target, generation_id=gen))
+        sm.set_status(target, TargetStatus.BUILT)
+        for f in files:
+            sm.ownership.claim(f"{sm.output_dir}/{f}", target, gen)
+
+    def test_reports_missing_and_changed_targets(self, tmp_dir: Path):
+        a = self._state(tmp_dir, "staging")
+        b = self._state(tmp_dir, "prod")
+        self._record(a, "core", "gen-1", ["core.py"])
+        self._record(b, "core", "gen-1", ["core.py"])
+        self._record(a, "api", "gen-2", ["api.py", "routes.py"])
+        self._record(b, "api", "gen-3", ["api.py"])
+        self._record(a, "web", "gen-4", [])
+
+        drifts = {d.target: d for d in diff_build_states(a, b)}
+
+        assert set(drifts) == {"api", "web"}
+        assert drifts["api"].generation_a == "gen-2"
+        assert drifts["api"].generation_b == "gen-3"
+        assert drifts["api"].files_only_a == ["routes.py"]
+        assert drifts["api"].description == "generation differs, files differ"
+        assert drifts["web"].status_b is None
+        assert drifts["web"].description == "only in A"
+
+    def test_generated_files_scoped_to_output_dir(self, tmp_dir: Path):
+        a = self._state(tmp_dir, "staging")
+        b = self._state(tmp_dir, "prod")
+        a.ownership.claim("staging/x.py", "core", "g1")
+        a.ownership.claim("prod/y.py", "core", "g2")
+        a.ownership.save()
+
+        assert a.generated_files("core") == ["x.py"]
+        assert b.generated_files("core") == ["y.py"]
+
+
+# ---------------------------------------------------------------------------
+# Build worktrees
+# ---------------------------------------------------------------------------
+
+
+def _git(repo: Path, *args: str) -> str:
+    return subprocess.run(
+        ["git", "-c", "user.name=t", "-c", "user.email=t@example.com", *args],
+        cwd=repo,
+        capture_output=True,
+        text=True,
+        check=True,
+    ).stdout.strip()
+
+
+class TestWorktree:
+    def test_base_version_control_has_no_worktrees(self, tmp_dir: Path):
+        class _NoWorktrees(VersionControl):
+            def checkpoint(self, message: str, paths: list[str] | None = None) -> str:
+                return ""
+
+            def diff(self, from_id: str, to_id: str) -> str:
+                return ""
+
+            def restore(self, commit_id: str) -> None:
+                pass
+
+            def log(self, target: str | None = None) -> list[str]:
+                return []
+
+        with pytest.raises(NotImplementedError, match="does not support build worktrees"):
+            _NoWorktrees().worktree(tmp_dir / "wt", "intentc/wt")
+
+    def test_git_worktree_created_reused_and_removed(self, tmp_dir: Path):
+        _git(tmp_dir, "init", "-q")
+        _git(tmp_dir, "commit", "-q", "--allow-empty", "-m", "initial")
+        vc = GitVersionControl(tmp_dir)
+        path = worktree_path(tmp_dir, "review")
+        assert path == tmp_dir / ".intentc" / "worktrees" / "review"
+
+        wt = vc.worktree(path, "intentc/review")
+        assert isinstance(wt, GitVersionControl)
+        assert _git(path, "rev-parse", "--abbrev-ref", "HEAD") == "intentc/review"
+        (path / "out.txt").write_text("generated")
+        _git(path, "add", "-A")
+        _git(path, "commit", "-q", "-m", "build core")
+
+        # Reusing an existing worktree keeps its commits
+        vc.worktree(path, "intentc/review")
+        assert _git(path, "log", "-1", "--format=%s") == "build core"
+
+        # The main checkout does not pick the worktree up as a gitlink
+        excludes = (tmp_dir / ".git" / "info" / "exclude").read_text().splitlines()
+        assert excludes.count("/.intentc/worktrees/review/") == 1
+        assert _git(tmp_dir, "status", "--porcelain") == ""
+
+        vc.remove_worktree(path)
+        assert not path.exists()
+        assert _git(tmp_dir, "log", "-1", "--format=%s", "intentc/review") == "build core"
+
+
+class TestRecentFailures:
+    def test_counts_failures_since_last_success(self, state_manager: StateManager):
+        for gen, status in [("g1", "failed"), ("g2", "built"), ("g3", "failed"), ("g4", "failed")]:
+            state_manager.save_build_result(
+                "core", _make_build_result("core", generation_id=gen, status=status)
+            )
+
+        failures = state_manager.recent_failures("core")
+
+        assert [r.generation_id for r in failures] == ["g4", "g3"]
+        assert state_manager.recent_failures("api") == []
+
+
+class TestGitFileChanges:
+    def test_changed_files_and_file_changes(self, tmp_dir: Path):
+        _git(tmp_dir, "init", "-q")
+        (tmp_dir / "a.py").write_text("a = 1\n")
+        (tmp_dir / "b.py").write_text("b = 1\n")
+        vc = GitVersionControl(tmp_dir)
+        _git(tmp_dir, "add", "-A")
+        _git(tmp_dir, "commit", "-q", "-m", "build core [gen:g1]")
+        first = _git(tmp_dir, "rev-parse", "HEAD")
+        (tmp_dir / "a.py").write_text("a = 2\n")
+        (tmp_dir / "b.py").unlink()
+        (tmp_dir / "c.py").write_text("c = 1\n")
+        (tmp_dir / "unrelated.txt").write_text("x\n")
+        _git(tmp_dir, "add", "-A")
+        _git(tmp_dir, "commit", "-q", "-m", "build core [gen:g2]")
+        second = _git(tmp_dir, "rev-parse", "HEAD")
+
+        assert vc.changed_files(first) == ["a.py", "b.py"]
+        paths = ["a.py", "b.py", "c.py"]
+        assert vc.file_changes(first, second, paths) == [
+            ("M", "a.py"),
+            ("D", "b.py"),
+            ("A", "c.py"),
+        ]
+        diff_text = vc.diff(first, second, paths)
+        assert "-a = 1" in diff_text and "+a = 2" in diff_text
+        assert "unrelated.txt" not in diff_text
+        assert vc.diff(first, second, []) == ""
+        assert vc.read_file(first, "a.py") == b"a = 1\n"
+        assert vc.read_file(second, "a.py") == b"a = 2\n"
+
+    def test_read_file_relative_to_project_in_subdirectory(self, tmp_dir: Path, git_identity):
+        _git(tmp_dir, "init", "-q")
+        project = tmp_dir / "proj"
+        (project / "src").mkdir(parents=True)
+        (project / "src" / "a.py").write_text("a = 1\n")
+        _git(tmp_dir, "add", "-A")
+        _git(tmp_dir, "commit", "-q", "-m", "build core")
+        commit = _git(tmp_dir, "rev-parse", "HEAD")
+
+        assert GitVersionControl(project).read_file(commit, "src/a.py") == b"a = 1\n"
+
+
+class TestCommitPaths:
+    def test_commits_only_given_paths(self, tmp_dir: Path, git_identity):
+        _git(tmp_dir, "init", "-q")
+        (tmp_dir / "api.ic").write_text("intent\n")
+        (tmp_dir / "notes.txt").write_text("scratch\n")
+        vc = GitVersionControl(tmp_dir)
+
+        commit_id = vc.commit_paths(["api.ic"], "intent: add api")
+
+        assert commit_id == _git(tmp_dir, "rev-parse", "HEAD")
+        assert _git(tmp_dir, "log", "-1", "--format=%s") == "intent: add api"
+        assert vc.changed_files(commit_id) == ["api.ic"]
+        # Nothing left to commit
+        assert vc.commit_paths(["api.ic"], "intent: again") is None
+
+    def test_noop_outside_git_repo(self, tmp_dir: Path):
+        (tmp_dir / "api.ic").write_text("intent\n")
+        assert GitVersionControl(tmp_dir).commit_paths(["api.ic"], "intent: add") is None
+
+    def test_checkpoint_only_given_paths(self, tmp_dir: Path, git_identity):
+        _git(tmp_dir, "init", "-q")
+        (tmp_dir / "old.py").write_text("old\n")
+        vc = GitVersionControl(tmp_dir)
+        vc.checkpoint("initial")
+        (tmp_dir / "old.py").unlink()
+        (tmp_dir / "mine.py").write_text("mine\n")
+        (tmp_dir / "sibling.py").write_text("sibling\n")
+        _git(tmp_dir, "add", "sibling.py")
+
+        commit_id = vc.checkpoint("build a", ["mine.py", "old.py", "never-written.py"])
+
+        assert vc.changed_files(commit_id) == ["mine.py", "old.py"]
+        assert _git(tmp_dir, "status", "--porcelain") == "A  sibling.py"
+
+    def test_commit_removal(self, tmp_dir: Path, git_identity):
+        _git(tmp_dir, "init", "-q")
+        (tmp_dir / "out").mkdir()
+        (tmp_dir / "out" / "main.py").write_text("print()\n")
+        vc = GitVersionControl(tmp_dir)
+        vc.commit_paths(["out"], "build out")
+        shutil.rmtree(tmp_dir / "out")
+
+        commit_id = vc.commit_removal("out", "discard out")
+
+        assert commit_id == _git(tmp_dir, "rev-parse", "HEAD")
+        assert _git(tmp_dir, "status", "--porcelain") == ""
+        # Nothing tracked under it any more
+        assert vc.commit_removal("out", "discard again") is None
+
+
+class TestRecordedOutputDirs:
+    def test_lists_every_output_dir_with_state(self, tmp_dir: Path):
+        assert recorded_output_dirs(tmp_dir) == []
+        for name in ("src", "out/staging"):
+            SQLiteBackend(base_dir=tmp_dir, output_dir=name).close()
+
+        assert recorded_output_dirs(tmp_dir) == ["out/staging", "src"]
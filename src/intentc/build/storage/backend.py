@@ -50,6 +50,11 @@ class BuildResult:
         total_duration_secs: float = 0.0,
         timestamp: str = "",
         steps: list[BuildStep] | None = None,
+        input_hash: str = "",
+        file_hashes: dict[str, str] | None = None,
+        outputs: dict[str, str] | None = None,
+        intent_hash: str = "",
+        file_sources: dict[str, str] | None = None,
     ) -> None:
         self.target = target
         self.generation_id = generation_id
@@ -58,6 +63,18 @@ class BuildResult:
         self.total_duration_secs = total_duration_secs
         self.timestamp = timestamp
         self.steps: list[BuildStep] = steps or []
+        # Hash of the intent content and dependency generations built from
+        self.input_hash = input_hash
+        # Hash of the intent and validation file content alone
+        self.intent_hash = intent_hash
+        # SHA-256 of each generated file, keyed by path relative to the output dir
+        self.file_hashes: dict[str, str] = file_hashes or {}
+        # How each generated file was found, keyed like file_hashes: "agent"
+        # if the agent's build response listed it, "writer" if the file
+        # writer saw the change
+        self.file_sources: dict[str, str] = file_sources or {}
+        # Values the agent reported for dependents' {{dep.<feature>.outputs.<key>}}
+        self.outputs: dict[str, str] = outputs or {}
 
 
 class StorageBackend(abc.ABC):
@@ -128,6 +145,27 @@ class StorageBackend(abc.ABC):
         self, target: str, limit: int = 50
     ) -> list[BuildResult]: ...
 
+    def prune_build_results(
+        self, keep_last: int = 0, keep_days: float = 0, dry_run: bool = False
+    ) -> list[BuildResult]:
+        """Delete old build results and return the ones removed.
+
+        Each target always keeps its latest result. An older result is kept
+        if it is among the target's *keep_last* newest or was recorded
+        within *keep_days* days; 0 turns a rule off. With *dry_run* nothing
+        is deleted. Backends that keep no history prune nothing.
+        """
+        return []
+
+    def delete_build_results(self, target: str) -> int:
+        """Delete every build result *target* recorded in this output directory.
+
+        Their steps, validation results and agent responses go too. Results
+        from builds into other output directories are kept. Returns how many
+        results were deleted; backends that keep no history delete nothing.
+        """
+        return 0
+
     # -- Build step methods --------------------------------------------------
 
     @abc.abstractmethod
@@ -154,8 +192,27 @@ class StorageBackend(abc.ABC):
         status: str,
         reason: str = "",
         duration_secs: float | None = None,
+        run_id: str = "",
     ) -> int: ...
 
+    def latest_validation_statuses(self) -> dict[tuple[str, str], str]:
+        """Map (target, validation name) to its status in the target's last
+        validation run (*run_id* of save_validation_result).
+
+        Backends that keep no history return an empty dict, so every
+        validation looks as if it never ran.
+        """
+        return {}
+
+    def latest_validation_results(self, target: str) -> tuple[str, dict[str, str]]:
+        """Return the generation *target*'s validations last ran under, and
+        each validation's status in that last run.
+
+        Returns ``("", {})`` when they never ran, or when the backend keeps
+        no history.
+        """
+        return "", {}
+
     # -- Agent response methods ----------------------------------------------
 
     @abc.abstractmethod
@@ -2,12 +2,15 @@
 
 from __future__ import annotations
 
+import functools
 import json
 import sqlite3
-from datetime import datetime, timezone
+import threading
+from datetime import datetime, timedelta, timezone
 from pathlib import Path
-from typing import Any
+from typing import Any, Callable, TypeVar
 
+from intentc.build.redact import redact, redact_json
 from intentc.build.storage.backend import (
     BuildResult,
     BuildStep,
@@ -63,7 +66,12 @@ CREATE TABLE IF NOT EXISTS build_results (
     timestamp          TEXT NOT NULL,
     git_diff           TEXT,
     files_created      TEXT,
-    files_modified     TEXT
+    files_modified     TEXT,
+    input_hash         TEXT NOT NULL DEFAULT '',
+    file_hashes        TEXT NOT NULL DEFAULT '{}',
+    outputs            TEXT NOT NULL DEFAULT '{}',
+    intent_hash        TEXT NOT NULL DEFAULT '',
+    file_sources       TEXT NOT NULL DEFAULT '{}'
 );
 
 CREATE TABLE IF NOT EXISTS build_steps (
@@ -89,7 +97,8 @@ CREATE TABLE IF NOT EXISTS validation_results (
     status                      TEXT NOT NULL,
     reason                      TEXT NOT NULL DEFAULT '',
     duration_secs               REAL,
-    timestamp                   TEXT NOT NULL
+    timestamp                   TEXT NOT NULL,
+    run_id                      TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS agent_responses (
@@ -116,6 +125,33 @@ def _now_iso() -> str:
     return datetime.now(timezone.utc).isoformat()
 
 
+def _parse_timestamp(value: str) -> datetime | None:
+    """Parse a stored timestamp; naive ones are in local time."""
+    try:
+        parsed = datetime.fromisoformat(value)
+    except ValueError:
+        return None
+    return parsed if parsed.tzinfo else parsed.astimezone()
+
+
+_F = TypeVar("_F", bound=Callable[..., Any])
+
+
+def _synchronized(method: _F) -> _F:
+    """Run *method* holding the backend's lock.
+
+    Targets building in parallel share one connection; the lock keeps their
+    statements and commits (and last_insert_rowid() reads) from interleaving.
+    """
+
+    @functools.wraps(method)
+    def wrapper(self: SQLiteBackend, *args: Any, **kwargs: Any) -> Any:
+        with self._lock:
+            return method(self, *args, **kwargs)
+
+    return wrapper  # type: ignore[return-value]
+
+
 class SQLiteBackend(StorageBackend):
     """SQLite-backed storage for intentc build state."""
 
@@ -124,6 +160,7 @@ class SQLiteBackend(StorageBackend):
         db_dir = base_dir / ".intentc" / "state" / output_dir
         db_dir.mkdir(parents=True, exist_ok=True)
         self._db_path = db_dir / "intentc.db"
+        self._lock = threading.RLock()
 
         self._conn = sqlite3.connect(
             str(self._db_path), check_same_thread=False
@@ -132,6 +169,7 @@ class SQLiteBackend(StorageBackend):
         self._conn.execute("PRAGMA foreign_keys=ON")
         self._conn.row_factory = sqlite3.Row
         self._conn.executescript(_SCHEMA_SQL)
+        self._add_missing_columns()
 
         # Migrate from flat-file state if present
         self._migrate_flat_files(db_dir)
@@ -147,6 +185,32 @@ class SQLiteBackend(StorageBackend):
 
     # -- Migration -----------------------------------------------------------
 
+    def _add_missing_columns(self) -> None:
+        """Add columns introduced after the database was created."""
+        added = {
+            "build_results": {
+                "input_hash": "TEXT NOT NULL DEFAULT ''",
+                "file_hashes": "TEXT NOT NULL DEFAULT '{}'",
+                "outputs": "TEXT NOT NULL DEFAULT '{}'",
+                "intent_hash": "TEXT NOT NULL DEFAULT ''",
+                "file_sources": "TEXT NOT NULL DEFAULT '{}'",
+            },
+            "validation_results": {
+                "run_id": "TEXT NOT NULL DEFAULT ''",
+            },
+        }
+        for table, table_columns in added.items():
+            columns = {
+                row["name"]
+                for row in self._conn.execute(f"PRAGMA table_info({table})")
+            }
+            for name, definition in table_columns.items():
+                if name not in columns:
+                    self._conn.execute(
+                        f"ALTER TABLE {table} ADD COLUMN {name} {definition}"
+                    )
+        self._conn.commit()
+
     def _migrate_flat_files(self, db_dir: Path) -> None:
         state_json = db_dir / "state.json"
         migrated_marker = db_dir / "state.json.migrated"
@@ -231,6 +295,7 @@ class SQLiteBackend(StorageBackend):
 
     # -- Generation methods --------------------------------------------------
 
+    @_synchronized
     def create_generation(
         self,
         generation_id: str,
@@ -253,6 +318,7 @@ class SQLiteBackend(StorageBackend):
         )
         self._conn.commit()
 
+    @_synchronized
     def complete_generation(
         self, generation_id: str, status: GenerationStatus
     ) -> None:
@@ -263,6 +329,7 @@ class SQLiteBackend(StorageBackend):
         )
         self._conn.commit()
 
+    @_synchronized
     def log_generation_event(
         self, generation_id: str, message: str
     ) -> None:
@@ -273,6 +340,7 @@ class SQLiteBackend(StorageBackend):
         )
         self._conn.commit()
 
+    @_synchronized
     def get_generation(self, generation_id: str) -> dict[str, Any] | None:
         row = self._conn.execute(
             "SELECT * FROM generations WHERE generation_id = ?",
@@ -296,6 +364,7 @@ class SQLiteBackend(StorageBackend):
 
     # -- Intent / validation file version methods ----------------------------
 
+    @_synchronized
     def record_intent_version(
         self, name: str, source_path: str, content_hash: str
     ) -> int:
@@ -315,6 +384,7 @@ class SQLiteBackend(StorageBackend):
         self._conn.commit()
         return self._conn.execute("SELECT last_insert_rowid()").fetchone()[0]
 
+    @_synchronized
     def record_validation_version(
         self, target: str, source_path: str, content_hash: str
     ) -> int:
@@ -336,6 +406,7 @@ class SQLiteBackend(StorageBackend):
 
     # -- Build result methods ------------------------------------------------
 
+    @_synchronized
     def save_build_result(
         self,
         target: str,
@@ -348,8 +419,9 @@ class SQLiteBackend(StorageBackend):
         self._conn.execute(
             "INSERT INTO build_results "
             "(target, generation_id, intent_version_id, status, commit_id, "
-            "total_duration_secs, timestamp, git_diff, files_created, files_modified) "
-            "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+            "total_duration_secs, timestamp, git_diff, files_created, files_modified, "
+            "input_hash, file_hashes, outputs, intent_hash, file_sources) "
+            "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
             (
                 target,
                 result.generation_id,
@@ -358,9 +430,14 @@ class SQLiteBackend(StorageBackend):
                 result.commit_id,
                 result.total_duration_secs,
                 result.timestamp or _now_iso(),
-                git_diff,
+                redact(git_diff) if git_diff else git_diff,
                 json.dumps(files_created) if files_created else None,
                 json.dumps(files_modified) if files_modified else None,
+                result.input_hash,
+                json.dumps(result.file_hashes),
+                json.dumps(result.outputs),
+                result.intent_hash,
+                json.dumps(result.file_sources),
             ),
         )
         br_id: int = self._conn.execute(
@@ -373,7 +450,7 @@ class SQLiteBackend(StorageBackend):
                 "INSERT INTO build_steps "
                 "(build_result_id, step_order, phase, status, duration_secs, summary, log) "
                 "VALUES (?, ?, ?, ?, ?, ?, ?)",
-                (br_id, i, step.phase, step.status, step.duration_secs, step.summary, ""),
+                (br_id, i, step.phase, step.status, step.duration_secs, redact(step.summary), ""),
             )
 
         # Update target state
@@ -386,6 +463,7 @@ class SQLiteBackend(StorageBackend):
         self._conn.commit()
         return br_id
 
+    @_synchronized
     def get_build_result(self, target: str) -> BuildResult | None:
         state_row = self._conn.execute(
             "SELECT last_build_result_id FROM target_state "
@@ -396,6 +474,7 @@ class SQLiteBackend(StorageBackend):
             return None
         return self._load_build_result(state_row[0])
 
+    @_synchronized
     def get_build_history(
         self, target: str, limit: int = 50
     ) -> list[BuildResult]:
@@ -432,10 +511,101 @@ class SQLiteBackend(StorageBackend):
             total_duration_secs=row["total_duration_secs"],
             timestamp=row["timestamp"],
             steps=steps,
+            input_hash=row["input_hash"],
+            file_hashes=json.loads(row["file_hashes"]),
+            outputs=json.loads(row["outputs"]),
+            intent_hash=row["intent_hash"],
+            file_sources=json.loads(row["file_sources"]),
         )
 
+    @_synchronized
+    def prune_build_results(
+        self, keep_last: int = 0, keep_days: float = 0, dry_run: bool = False
+    ) -> list[BuildResult]:
+        latest = {
+            row[0]
+            for row in self._conn.execute(
+                "SELECT last_build_result_id FROM target_state "
+                "WHERE output_dir = ? AND last_build_result_id IS NOT NULL",
+                (self.output_dir,),
+            )
+        }
+        cutoff = (
+            datetime.now(timezone.utc) - timedelta(days=keep_days)
+            if keep_days > 0
+            else None
+        )
+        rows = self._conn.execute(
+            "SELECT id, target, timestamp FROM build_results ORDER BY target, id DESC"
+        ).fetchall()
+
+        doomed: list[int] = []
+        rank: dict[str, int] = {}
+        for row in rows:
+            rank[row["target"]] = rank.get(row["target"], 0) + 1
+            if row["id"] in latest or rank[row["target"]] <= max(keep_last, 1):
+                continue
+            if cutoff is not None:
+                recorded = _parse_timestamp(row["timestamp"])
+                if recorded is None or recorded >= cutoff:
+                    continue
+            doomed.append(row["id"])
+
+        pruned = [self._load_build_result(br_id) for br_id in doomed]
+        if dry_run or not doomed:
+            return pruned
+        self._delete_build_results(doomed)
+        return pruned
+
+    @_synchronized
+    def delete_build_results(self, target: str) -> int:
+        # The database belongs to one output directory, so all of the
+        # target's results are this directory's
+        doomed = [
+            row[0]
+            for row in self._conn.execute(
+                "SELECT id FROM build_results WHERE target = ?", (target,)
+            )
+        ]
+        if doomed:
+            self._conn.execute(
+                "UPDATE target_state SET last_build_result_id = NULL "
+                "WHERE target = ? AND output_dir = ?",
+                (target, self.output_dir),
+            )
+            self._delete_build_results(doomed)
+        return len(doomed)
+
+    def _delete_build_results(self, ids: list[int]) -> None:
+        """Delete the build results *ids* and the rows that reference them.
+
+        Validation results belong to a build result when they link to it or,
+        unlinked, were recorded for its target in its generation.
+        """
+        params = [(br_id,) for br_id in ids]
+        validations = (
+            "SELECT v.id FROM validation_results v JOIN build_results b "
+            "ON v.build_result_id = b.id OR (v.build_result_id IS NULL "
+            "AND v.target = b.target AND v.generation_id = b.generation_id) "
+            "WHERE b.id = ?"
+        )
+        self._conn.executemany(
+            "DELETE FROM agent_responses WHERE build_result_id = ? "
+            f"OR validation_result_id IN ({validations})",
+            [(br_id, br_id) for br_id in ids],
+        )
+        self._conn.executemany(
+            f"DELETE FROM validation_results WHERE id IN ({validations})", params
+        )
+        self._conn.executemany(
+            "DELETE FROM build_steps WHERE build_result_id = ?", params
+        )
+        self._conn.executemany("DELETE FROM build_results WHERE id = ?", params)
+        self._conn.commit()
+
     # -- Build step methods --------------------------------------------------
 
+    @_synchronized
     def save_build_step(
         self,
         build_result_id: int,
@@ -453,14 +623,15 @@ class SQLiteBackend(StorageBackend):
                 step.phase,
                 step.status,
                 step.duration_secs,
-                step.summary,
-                log,
+                redact(step.summary),
+                redact(log),
             ),
         )
         self._conn.commit()
 
     # -- Validation result methods -------------------------------------------
 
+    @_synchronized
     def save_validation_result(
         self,
         build_result_id: int | None,
@@ -473,12 +644,13 @@ class SQLiteBackend(StorageBackend):
         status: str,
         reason: str = "",
         duration_secs: float | None = None,
+        run_id: str = "",
     ) -> int:
         self._conn.execute(
             "INSERT INTO validation_results "
             "(build_result_id, generation_id, target, validation_file_version_id, "
-            "name, type, severity, status, reason, duration_secs, timestamp) "
-            "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+            "name, type, severity, status, reason, duration_secs, timestamp, run_id) "
+            "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
             (
                 build_result_id,
                 generation_id,
@@ -488,16 +660,48 @@ class SQLiteBackend(StorageBackend):
                 type,
                 severity,
                 status,
-                reason,
+                redact(reason),
                 duration_secs,
                 _now_iso(),
+                run_id,
             ),
         )
         self._conn.commit()
         return self._conn.execute("SELECT last_insert_rowid()").fetchone()[0]
 
+    def _last_run_validations(self, target: str | None = None) -> list[sqlite3.Row]:
+        """Each validation's latest row from its target's last run, in run order.
+
+        A validation run twice in one run counts with its last status.
+        """
+        query = (
+            "SELECT target, generation_id, name, status FROM validation_results v "
+            "WHERE id IN (SELECT MAX(id) FROM validation_results "
+            "GROUP BY target, run_id, name) "
+            "AND run_id = (SELECT run_id FROM validation_results "
+            "WHERE target = v.target ORDER BY id DESC LIMIT 1)"
+        )
+        if target is None:
+            return self._conn.execute(f"{query} ORDER BY id").fetchall()
+        return self._conn.execute(
+            f"{query} AND target = ? ORDER BY id", (target,)
+        ).fetchall()
+
+    @_synchronized
+    def latest_validation_statuses(self) -> dict[tuple[str, str], str]:
+        rows = self._last_run_validations()
+        return {(row["target"], row["name"]): row["status"] for row in rows}
+
+    @_synchronized
+    def latest_validation_results(self, target: str) -> tuple[str, dict[str, str]]:
+        rows = self._last_run_validations(target)
+        if not rows:
+            return "", {}
+        return rows[-1]["generation_id"], {r["name"]: r["status"] for r in rows}
+
     # -- Agent response methods ----------------------------------------------
 
+    @_synchronized
     def save_agent_response(
         self,
         build_result_id: int | None,
@@ -513,7 +717,7 @@ class SQLiteBackend(StorageBackend):
                 build_result_id,
                 validation_result_id,
                 response_type,
-                json.dumps(response_json),
+                json.dumps(redact_json(response_json)),
                 _now_iso(),
             ),
         )
@@ -521,6 +725,7 @@ class SQLiteBackend(StorageBackend):
 
     # -- Target state methods ------------------------------------------------
 
+    @_synchronized
     def get_status(self, target: str) -> TargetStatus:
         row = self._conn.execute(
             "SELECT status FROM target_state "
@@ -534,15 +739,20 @@ class SQLiteBackend(StorageBackend):
         except ValueError:
             return TargetStatus.PENDING
 
+    @_synchronized
     def set_status(self, target: str, status: TargetStatus) -> None:
+        # Upsert so the link to the last build result survives status changes
         self._conn.execute(
-            "INSERT OR REPLACE INTO target_state "
+            "INSERT INTO target_state "
             "(target, output_dir, status, updated_at) "
-            "VALUES (?, ?, ?, ?)",
+            "VALUES (?, ?, ?, ?) "
+            "ON CONFLICT(target, output_dir) DO UPDATE SET "
+            "status = excluded.status, updated_at = excluded.updated_at",
             (target, self.output_dir, status.value, _now_iso()),
         )
         self._conn.commit()
 
+    @_synchronized
     def list_targets(self) -> list[tuple[str, TargetStatus]]:
         rows = self._conn.execute(
             "SELECT target, status FROM target_state WHERE output_dir = ? "
@@ -558,6 +768,7 @@ class SQLiteBackend(StorageBackend):
             result.append((r[0], s))
         return result
 
+    @_synchronized
     def reset(self, target: str) -> None:
         self._conn.execute(
             "DELETE FROM target_state WHERE target = ? AND output_dir = ?",
@@ -565,6 +776,7 @@ class SQLiteBackend(StorageBackend):
         )
         self._conn.commit()
 
+    @_synchronized
     def reset_all(self) -> None:
         self._conn.execute(
             "DELETE FROM target_state WHERE output_dir = ?",
@@ -47,7 +47,7 @@ class MockVersionControl(VersionControl):
         self.restores: list[str] = []
         self._counter = 0
 
-    def checkpoint(self, message: str) -> str:
+    def checkpoint(self, message: str, paths: list[str] | None = None) -> str:
         self._counter += 1
         commit_id = f"mock-commit-{self._counter:04d}"
         self.checkpoints.append((message, commit_id))
@@ -115,7 +115,7 @@ class FakeStorageBackend(StorageBackend):
 
     def save_validation_result(self, build_result_id, generation_id, target,
                                 validation_file_version_id, name, type, severity,
-                                status, reason="", duration_secs=None):
+                                status, reason="", duration_secs=None, run_id=""):
         return 1
 
     def save_agent_response(self, build_result_id, validation_result_id,
@@ -333,7 +333,9 @@ class TestEndToEnd:
             _build(builder, agent, output_dir=output_dir)
 
             # Force rebuild
-            results, error = _build(builder, agent, output_dir=output_dir, force=True)
+            results, error = _build(
+                builder, agent, output_dir=output_dir, force=True, force_agent=True
+            )
 
             assert error is None
             assert len(results) == 3
@@ -342,6 +344,27 @@ class TestEndToEnd:
             # 3 original + 3 forced = 6 total
             assert len(agent.build_calls) == 6
 
+    def test_force_skips_unchanged_intents(self) -> None:
+        with tempfile.TemporaryDirectory() as tmpdir:
+            tmp_dir = Path(tmpdir)
+            builder, agent, state_mgr, vc, storage = _setup(tmp_dir)
+            output_dir = str(tmp_dir / "src")
+            _build(builder, agent, output_dir=output_dir)
+
+            # Nothing changed, so even --force makes no agent calls
+            results, error = _build(builder, agent, output_dir=output_dir, force=True)
+            assert error is None
+            assert results == []
+            assert len(agent.build_calls) == 3
+
+            # Editing one intent rebuilds it and, through the new generation,
+            # everything downstream of it
+            models = builder._project.features["models"].intents[0]
+            models.body += "\nAlso track timestamps."
+            results, error = _build(builder, agent, output_dir=output_dir, force=True)
+            assert error is None
+            assert [r.target for r in results] == ["models", "store", "api"]
+
     def test_targeted_build_with_ancestors(self) -> None:
         with tempfile.TemporaryDirectory() as tmpdir:
             tmp_dir = Path(tmpdir)
@@ -3,6 +3,9 @@
 from __future__ import annotations
 
 import tempfile
+import threading
+import time
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
 from pathlib import Path
 
 import pytest
@@ -13,11 +16,16 @@ from intentc.build.agents import (
     ValidationResponse,
 )
 from intentc.build.validations import (
+    AgentBudget,
     AgentValidationRunner,
+    FileCheckRunner,
+    FolderCheckRunner,
     ValidationContext,
     ValidationRunner,
     ValidationSuite,
     ValidationSuiteResult,
+    WebCheckRunner,
+    parse_duration,
 )
 from intentc.core.models import (
     Implementation,
@@ -179,6 +187,342 @@ class TestAgentValidationRunner:
         assert resp.status == "fail"
         assert "Agent error" in resp.reason
 
+    def _run_with_confidence(
+        self, confidence: float | None, min_confidence: float | None
+    ) -> ValidationResponse:
+        agent = MockAgent(
+            validation_response=ValidationResponse(
+                name="check-1", status="pass", reason="Looks fine", confidence=confidence
+            )
+        )
+        args: dict[str, object] = {"rubric": "Check something."}
+        if min_confidence is not None:
+            args["min_confidence"] = min_confidence
+        ctx = ValidationContext(
+            project_intent=ProjectIntent(name="p", body=""),
+            implementation=None,
+            feature_intent=IntentFile(name="f", body=""),
+            output_dir="/tmp/out",
+            response_file_path="/tmp/resp.json",
+        )
+        return AgentValidationRunner(agent).run(
+            Validation(name="check-1", args=args), ctx
+        )
+
+    def test_low_confidence_pass_fails(self):
+        resp = self._run_with_confidence(0.4, 0.8)
+        assert resp.status == "fail"
+        assert resp.reason == "Confidence 0.40 is below the minimum 0.80: Looks fine"
+
+    def test_confident_pass_passes(self):
+        resp = self._run_with_confidence(0.9, 0.8)
+        assert resp.status == "pass"
+        assert resp.reason == "Looks fine"
+
+    def test_missing_confidence_fails_when_required(self):
+        resp = self._run_with_confidence(None, 0.8)
+        assert resp.status == "fail"
+        assert resp.reason.startswith("No confidence reported")
+
+    def test_confidence_ignored_without_threshold(self):
+        resp = self._run_with_confidence(0.1, None)
+        assert resp.status == "pass"
+
+    def _flaky_agent(self, failures: int) -> MockAgent:
+        class FlakyAgent(MockAgent):
+            def validate(self, ctx, validation):
+                self.validate_calls.append((ctx, validation))
+                if len(self.validate_calls) <= failures:
+                    raise RuntimeError("connection reset")
+                return ValidationResponse(name="web", status="pass", reason="up")
+
+        return FlakyAgent()
+
+    def _ctx(self) -> ValidationContext:
+        return ValidationContext(
+            project_intent=ProjectIntent(name="p", body=""),
+            implementation=None,
+            feature_intent=IntentFile(name="f", body=""),
+            output_dir="/tmp/out",
+            response_file_path="/tmp/resp.json",
+        )
+
+    def test_validation_timeout_and_retries(self):
+        default, timed = MockAgent(), self._flaky_agent(failures=2)
+        timeouts: list[float] = []
+
+        def agent_for_timeout(timeout: float) -> MockAgent:
+            timeouts.append(timeout)
+            return timed
+
+        runner = AgentValidationRunner(default, agent_for_timeout=agent_for_timeout)
+        validation = Validation(name="web", args={"timeout": "1m30s", "retries": 2})
+
+        resp = runner.run(validation, self._ctx())
+
+        assert resp.status == "pass"
+        assert timeouts == [90.0]
+        assert len(timed.validate_calls) == 3
+        assert default.validate_calls == []
+
+    def test_retries_run_out(self):
+        agent = self._flaky_agent(failures=5)
+        runner = AgentValidationRunner(agent)
+
+        resp = runner.run(Validation(name="web", args={"retries": "1"}), self._ctx())
+
+        assert resp.status == "fail"
+        assert resp.reason == "Agent error: connection reset"
+        assert len(agent.validate_calls) == 2
+
+    def test_no_retry_without_budget(self):
+        agent, log = self._flaky_agent(failures=1), []
+        runner = AgentValidationRunner(agent, log=log.append)
+        ctx = self._ctx()
+        ctx.may_retry = lambda: False
+
+        resp = runner.run(Validation(name="web", args={"retries": 2}), ctx)
+
+        assert resp.status == "fail"
+        assert len(agent.validate_calls) == 1
+        assert any("not retrying (budget exceeded)" in m for m in log)
+
+    def test_invalid_limits_warn_and_are_ignored(self):
+        agent, log = MockAgent(), []
+        runner = AgentValidationRunner(
+            agent, agent_for_timeout=lambda _t: pytest.fail("timeout was used"), log=log.append
+        )
+
+        resp = runner.run(
+            Validation(name="web", args={"timeout": "soon", "retries": -1}), self._ctx()
+        )
+
+        assert resp.status == "pass"
+        assert len(agent.validate_calls) == 1
+        assert any("timeout 'soon' is not a duration" in m for m in log)
+        assert any("retries -1 is not a count" in m for m in log)
+
+    @pytest.mark.parametrize(
+        "value,expected",
+        [(30, 30.0), ("2.5", 2.5), ("500ms", 0.5), ("5m", 300.0), ("1h", 3600.0),
+         ("1m30s", 90.0), ("0", None), ("5 minutes", None), (True, None), ("s", None)],
+    )
+    def test_parse_duration(self, value, expected):
+        assert parse_duration(value) == expected
+
+
+# ---------------------------------------------------------------------------
+# FileCheckRunner tests
+# ---------------------------------------------------------------------------
+
+
+class TestFileCheckRunner:
+    def _run(self, tmp_path: Path, **args: object) -> ValidationResponse:
+        ctx = ValidationContext(
+            project_intent=ProjectIntent(name="p", body=""),
+            implementation=None,
+            feature_intent=IntentFile(name="f", body=""),
+            output_dir=str(tmp_path),
+            response_file_path="",
+        )
+        validation = Validation(name="file", type=ValidationType.FILE_CHECK, args=args)
+        return FileCheckRunner().run(validation, ctx)
+
+    @pytest.fixture
+    def tree(self, tmp_path: Path) -> Path:
+        (tmp_path / "pkg").mkdir()
+        (tmp_path / "pkg" / "main.py").write_text("def main():\n    serve()\n")
+        return tmp_path
+
+    def test_passes_when_file_has_text(self, tree: Path):
+        resp = self._run(tree, file="pkg/main.py", contains=["def main", "serve()"])
+        assert resp.status == "pass"
+        assert resp.reason == "File 'pkg/main.py' exists"
+
+    def test_missing_file(self, tree: Path):
+        resp = self._run(tree, file="pkg/nope.py")
+        assert resp.status == "fail"
+        assert resp.reason == "File 'pkg/nope.py' does not exist"
+
+    def test_exists_false(self, tree: Path):
+        assert self._run(tree, file="pkg/nope.py", exists=False).status == "pass"
+        assert self._run(tree, file="pkg/main.py", exists=False).status == "fail"
+
+    def test_missing_text(self, tree: Path):
+        resp = self._run(tree, file="pkg/main.py", contains="argparse")
+        assert resp.status == "fail"
+        assert resp.reason == "File 'pkg/main.py' does not contain expected text 'argparse'"
+
+    def test_folder_is_not_a_file(self, tree: Path):
+        assert self._run(tree, file="pkg").status == "fail"
+
+    def test_registered_by_default(self, tree: Path):
+        suite = _make_suite(_make_project(), output_dir=str(tree))
+
+        result = suite.validate_entries(
+            "f",
+            [Validation(name="main", type=ValidationType.FILE_CHECK, args={"file": "pkg/main.py"})],
+        )
+
+        assert result.passed is True
+
+
+# ---------------------------------------------------------------------------
+# FolderCheckRunner tests
+# ---------------------------------------------------------------------------
+
+
+class TestFolderCheckRunner:
+    def _run(self, tmp_path: Path, **args: object) -> ValidationResponse:
+        ctx = ValidationContext(
+            project_intent=ProjectIntent(name="p", body=""),
+            implementation=None,
+            feature_intent=IntentFile(name="f", body=""),
+            output_dir=str(tmp_path),
+            response_file_path="",
+        )
+        validation = Validation(
+            name="folder", type=ValidationType.FOLDER_CHECK, args=args
+        )
+        return FolderCheckRunner().run(validation, ctx)
+
+    @pytest.fixture
+    def tree(self, tmp_path: Path) -> Path:
+        (tmp_path / "pkg" / "sub").mkdir(parents=True)
+        (tmp_path / "pkg" / "a.py").write_text("")
+        (tmp_path / "pkg" / "b.py").write_text("")
+        (tmp_path / "pkg" / "sub" / "c.py").write_text("")
+        return tmp_path
+
+    def test_passes_when_all_assertions_hold(self, tree: Path):
+        resp = self._run(
+            tree, folder="pkg", min_files=2, max_files=2, contains_dirs=["sub"]
+        )
+        assert resp.status == "pass"
+        assert resp.reason == "Folder 'pkg' has 2 files"
+
+    def test_missing_folder(self, tree: Path):
+        resp = self._run(tree, folder="nope")
+        assert resp.status == "fail"
+        assert resp.reason == "Folder 'nope' does not exist"
+
+    def test_exists_false(self, tree: Path):
+        assert self._run(tree, folder="nope", exists=False).status == "pass"
+        assert self._run(tree, folder="pkg", exists=False).status == "fail"
+
+    def test_recursive_counts_subdirectories(self, tree: Path):
+        resp = self._run(tree, folder="pkg", recursive=True, min_files=3)
+        assert resp.status == "pass"
+        assert self._run(tree, folder="pkg", min_files=3).status == "fail"
+
+    def test_reports_first_failing_assertion(self, tree: Path):
+        resp = self._run(tree, folder="pkg", max_files=1, contains_dirs=["docs"])
+        assert resp.status == "fail"
+        assert resp.reason == "Folder 'pkg' has 2 files, expected at most 1"
+
+    def test_missing_subdirectory(self, tree: Path):
+        resp = self._run(tree, folder="pkg", contains_dirs=["sub", "docs"])
+        assert resp.reason == "Folder 'pkg' is missing subdirectory 'docs'"
+
+    def test_registered_by_default(self, tree: Path):
+        project = _make_project(features={
+            "f": FeatureNode(path="f", intents=[IntentFile(name="f", body="")]),
+        })
+        suite = _make_suite(project, output_dir=str(tree))
+
+        result = suite.validate_entries(
+            "f",
+            [Validation(name="pkg", type=ValidationType.FOLDER_CHECK, args={"folder": "pkg"})],
+        )
+
+        assert result.passed is True
+
+
+# ---------------------------------------------------------------------------
+# WebCheckRunner tests
+# ---------------------------------------------------------------------------
+
+
+class _PageHandler(BaseHTTPRequestHandler):
+    """Serves /health as 200 "status: ok"; every other path is a 404."""
+
+    def do_GET(self) -> None:
+        found = self.path == "/health"
+        body = b"status: ok" if found else b"not here"
+        self.send_response(200 if found else 404)
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def log_message(self, *args: object) -> None:
+        pass
+
+
+class TestWebCheckRunner:
+    @pytest.fixture
+    def server(self):
+        httpd = ThreadingHTTPServer(("127.0.0.1", 0), _PageHandler)
+        thread = threading.Thread(target=httpd.serve_forever, daemon=True)
+        thread.start()
+        yield f"http://127.0.0.1:{httpd.server_address[1]}"
+        httpd.shutdown()
+        httpd.server_close()
+
+    def _run(self, **args: object) -> ValidationResponse:
+        ctx = ValidationContext(
+            project_intent=ProjectIntent(name="p", body=""),
+            implementation=None,
+            feature_intent=IntentFile(name="f", body=""),
+            output_dir=tempfile.mkdtemp(),
+            response_file_path="",
+        )
+        validation = Validation(name="web", type=ValidationType.WEB_CHECK, args=args)
+        return WebCheckRunner().run(validation, ctx)
+
+    def test_status_and_body_match(self, server: str):
+        resp = self._run(url=f"{server}/health", contains="ok")
+        assert resp.status == "pass"
+        assert resp.reason == f"GET {server}/health returned 200"
+
+    def test_expected_error_status(self, server: str):
+        assert self._run(url=f"{server}/missing", status_code=404).status == "pass"
+
+    def test_unexpected_status(self, server: str):
+        resp = self._run(url=f"{server}/missing")
+        assert resp.status == "fail"
+        assert resp.reason == f"GET {server}/missing returned 404, expected 200"
+
+    def test_missing_text(self, server: str):
+        resp = self._run(url=f"{server}/health", contains="degraded")
+        assert resp.status == "fail"
+        assert "does not contain 'degraded'" in resp.reason
+
+    def test_unreachable_server(self, server: str):
+        port = int(server.rsplit(":", 1)[1])
+        resp = self._run(url=f"http://127.0.0.1:{port + 1}/", timeout=2)
+        assert resp.status == "fail"
+        assert resp.reason.startswith(f"GET http://127.0.0.1:{port + 1}/ failed:")
+
+    def test_natural_language_check_goes_to_agent(self):
+        agent = StubRunner("agent_validation")
+        web = StubRunner("web_check")
+        suite = _make_suite(
+            _make_project(), runner_registry={"agent_validation": agent, "web_check": web}
+        )
+        entries = [
+            Validation(name="plain", type=ValidationType.WEB_CHECK, args={"url": "http://x"}),
+            Validation(
+                name="judged",
+                type=ValidationType.WEB_CHECK,
+                args={"url": "http://x", "check": "The page greets the user"},
+            ),
+        ]
+
+        suite.validate_entries("f", entries)
+
+        assert [v.name for v, _ in web.calls] == ["plain"]
+        assert [v.name for v, _ in agent.calls] == ["judged"]
+
 
 # ---------------------------------------------------------------------------
 # ValidationSuite lifecycle tests
@@ -237,6 +581,50 @@ class TestValidationSuiteLifecycle:
         assert result.results[1].name == "v2"
         assert len(passing_runner.calls) == 2
 
+    def test_validate_entries_records_duration(self):
+        """Each response carries the time its runner took."""
+        runner = StubRunner(type_name="agent_validation", status="pass")
+        project = _make_project(features={
+            "f": FeatureNode(path="f", intents=[IntentFile(name="f", body="")]),
+        })
+        suite = _make_suite(project, runner_registry={"agent_validation": runner})
+
+        result = suite.validate_entries("f", [Validation(name="timed")])
+
+        assert result.results[0].duration_secs is not None
+        assert result.results[0].duration_secs >= 0.0
+
+    def test_results_are_filed_under_the_build_generation(self, tmp_path: Path):
+        """Stored results group under the target's build generation."""
+        from intentc.build.storage import BuildResult, SQLiteBackend
+
+        backend = SQLiteBackend(base_dir=tmp_path, output_dir="src")
+        backend.create_generation("gen-b", "src")
+        backend.save_build_result("f", BuildResult(target="f", generation_id="gen-b"))
+        project = _make_project(features={
+            name: FeatureNode(path=name, intents=[IntentFile(name=name, body="")])
+            for name in ("f", "g")
+        })
+        suite = ValidationSuite(
+            project=project,
+            agent_profile=_make_agent_profile(),
+            output_dir=str(tmp_path),
+            runner_registry={"agent_validation": StubRunner(type_name="agent_validation")},
+            storage_backend=backend,
+        )
+
+        suite.validate_entries("f", [Validation(name="a"), Validation(name="b")])
+        suite.validate_entries("g", [Validation(name="a"), Validation(name="b")])
+
+        assert backend.latest_validation_results("f") == (
+            "gen-b", {"a": "pass", "b": "pass"}
+        )
+        # Without a build, the suite's results still share one generation
+        generation_id, statuses = backend.latest_validation_results("g")
+        assert generation_id.startswith("val-")
+        assert statuses == {"a": "pass", "b": "pass"}
+        backend.close()
+
     def test_validate_feature_error_severity_fails_suite(self):
         """An error-severity failure makes the suite result fail."""
         failing_runner = StubRunner(
@@ -594,3 +982,232 @@ class TestProgressLogging:
         suite.validate_project()
 
         assert any("Validating project" in m for m in log_msgs)
+
+
+# ---------------------------------------------------------------------------
+# Validation dependencies
+# ---------------------------------------------------------------------------
+
+
+class ByNameRunner(ValidationRunner):
+    """Fails the validations named in *failing*; records the run order."""
+
+    def __init__(self, failing: set[str] | None = None) -> None:
+        self._failing = failing or set()
+        self.order: list[str] = []
+
+    def type(self) -> str:
+        return "agent_validation"
+
+    def run(self, validation: Validation, ctx: ValidationContext) -> ValidationResponse:
+        self.order.append(validation.name)
+        status = "fail" if validation.name in self._failing else "pass"
+        return ValidationResponse(name=validation.name, status=status, reason=status)
+
+
+class TestValidationDependencies:
+    def _suite(self, runner: ByNameRunner) -> ValidationSuite:
+        project = _make_project(features={
+            "f": FeatureNode(path="f", intents=[IntentFile(name="f", body="")]),
+        })
+        return _make_suite(project, runner_registry={"agent_validation": runner})
+
+    def test_dependent_runs_after_prerequisite(self):
+        runner = ByNameRunner()
+        result = self._suite(runner).validate_entries("f", [
+            Validation(name="endpoint", depends_on="binary-exists"),
+            Validation(name="binary-exists"),
+        ])
+
+        assert runner.order == ["binary-exists", "endpoint"]
+        assert [r.name for r in result.results] == ["endpoint", "binary-exists"]
+        assert result.passed is True
+
+    def test_dependent_skipped_when_prerequisite_fails(self):
+        runner = ByNameRunner(failing={"binary-exists"})
+        result = self._suite(runner).validate_entries("f", [
+            Validation(name="binary-exists"),
+            Validation(name="endpoint", depends_on="binary-exists"),
+            Validation(name="health", depends_on="endpoint"),
+        ])
+
+        assert runner.order == ["binary-exists"]
+        assert result.results[1].status == "skipped"
+        assert result.results[1].reason == "skipped: dependency 'binary-exists' failed"
+        assert result.results[2].status == "skipped"
+        assert result.passed is False
+        assert result.summary.endswith("(1 errors, 0 warnings, 2 skipped)")
+
+    def test_unknown_prerequisite_fails(self):
+        runner = ByNameRunner()
+        result = self._suite(runner).validate_entries("f", [
+            Validation(name="endpoint", depends_on="missing"),
+        ])
+
+        assert runner.order == []
+        assert result.results[0].status == "fail"
+        assert "unknown validation 'missing'" in result.results[0].reason
+
+    def test_dependency_cycle_fails(self):
+        runner = ByNameRunner()
+        result = self._suite(runner).validate_entries("f", [
+            Validation(name="a", depends_on="b"),
+            Validation(name="b", depends_on="a"),
+            Validation(name="c"),
+        ])
+
+        assert runner.order == ["c"]
+        assert [r.status for r in result.results] == ["fail", "fail", "pass"]
+        assert "Dependency cycle" in result.results[0].reason
+
+
+class ConcurrencyRunner(ValidationRunner):
+    """Records the peak number of validations running at the same time."""
+
+    def __init__(self, type_name: str, uses_agent: bool) -> None:
+        self._type_name = type_name
+        self.uses_agent = uses_agent
+        self._lock = threading.Lock()
+        self._running = 0
+        self.peak = 0
+
+    def type(self) -> str:
+        return self._type_name
+
+    def run(self, validation: Validation, ctx: ValidationContext) -> ValidationResponse:
+        with self._lock:
+            self._running += 1
+            self.peak = max(self.peak, self._running)
+        time.sleep(0.05)
+        with self._lock:
+            self._running -= 1
+        return ValidationResponse(name=validation.name, status="pass", reason="ok")
+
+
+class TestValidationParallelism:
+    def _run(
+        self, parallelism: int, max_agent_concurrency: int = 1
+    ) -> tuple[ConcurrencyRunner, ConcurrencyRunner]:
+        native = ConcurrencyRunner("folder_check", uses_agent=False)
+        agent = ConcurrencyRunner("agent_validation", uses_agent=True)
+        suite = ValidationSuite(
+            project=_make_project(),
+            agent_profile=_make_agent_profile(),
+            output_dir=tempfile.mkdtemp(),
+            runner_registry={"folder_check": native, "agent_validation": agent},
+            parallelism=parallelism,
+            max_agent_concurrency=max_agent_concurrency,
+        )
+        entries = [
+            Validation(name=f"native-{i}", type=ValidationType.FOLDER_CHECK)
+            for i in range(4)
+        ] + [Validation(name=f"agent-{i}") for i in range(4)]
+
+        result = suite.validate_entries("f", entries)
+
+        assert result.passed is True
+        return native, agent
+
+    def test_native_concurrent_agent_serialized(self):
+        native, agent = self._run(parallelism=8)
+
+        assert native.peak > 1
+        assert agent.peak == 1
+
+    def test_agent_concurrency_cap(self):
+        _, agent = self._run(parallelism=8, max_agent_concurrency=3)
+
+        assert agent.peak == 3
+
+    def test_parallelism_caps_workers(self):
+        native, agent = self._run(parallelism=1)
+
+        assert native.peak == 1
+        assert agent.peak == 1
+
+    def test_agent_runner_uses_agent(self):
+        assert AgentValidationRunner(MockAgent()).uses_agent is True
+        assert FileCheckRunner().uses_agent is False
+        assert FolderCheckRunner().uses_agent is False
+        assert WebCheckRunner().uses_agent is False
+
+
+class TestAgentBudget:
+    def _suite(
+        self, runner: StubRunner, budget: AgentBudget, project: Project | None = None
+    ) -> ValidationSuite:
+        return ValidationSuite(
+            project=project or _make_project(),
+            agent_profile=_make_agent_profile(),
+            output_dir=tempfile.mkdtemp(),
+            runner_registry={runner.type(): runner, "folder_check": StubRunner("folder_check")},
+            agent_budget=budget,
+        )
+
+    def test_budget_of_one_runs_one_agent_check(self):
+        web = StubRunner("agent_validation")
+        web.uses_agent = True
+        suite = self._suite(web, AgentBudget(1))
+        entries = [
+            Validation(name="web-home", severity=Severity.WARNING),
+            Validation(name="web-login"),
+            Validation(name="web-cart"),
+            Validation(name="layout", type=ValidationType.FOLDER_CHECK),
+        ]
+
+        result = suite.validate_entries("shop", entries)
+
+        # The error-severity check gets the budget ahead of the earlier warning
+        assert [v.name for v, _ in web.calls] == ["web-login"]
+        statuses = {r.name: (r.status, r.reason) for r in result.results}
+        assert statuses["web-login"][0] == "pass"
+        assert statuses["layout"][0] == "pass"
+        assert statuses["web-home"] == ("skipped", "skipped: budget exceeded")
+        assert statuses["web-cart"] == ("skipped", "skipped: budget exceeded")
+        assert result.passed is True
+
+    def test_budget_is_shared_between_suites(self):
+        web = StubRunner("agent_validation")
+        web.uses_agent = True
+        budget = AgentBudget(2)
+
+        self._suite(web, budget).validate_entries("a", [Validation(name="one")])
+        result = self._suite(web, budget).validate_entries(
+            "b", [Validation(name="two"), Validation(name="three")]
+        )
+
+        assert [v.name for v, _ in web.calls] == ["one", "two"]
+        assert result.results[1].status == "skipped"
+        assert budget.remaining == 0
+
+    def test_errors_in_later_features_rank_first(self):
+        web = StubRunner("agent_validation")
+        web.uses_agent = True
+
+        def node(path: str, severity: Severity) -> FeatureNode:
+            check = Validation(name=f"{path}-check", severity=severity)
+            return FeatureNode(
+                path=path, validations=[ValidationFile(target=path, validations=[check])]
+            )
+
+        project = _make_project(features={
+            "a": node("a", Severity.WARNING),
+            "b": node("b", Severity.ERROR),
+        })
+
+        results = self._suite(web, AgentBudget(1), project).validate_features(["a", "b"])
+
+        assert [v.name for v, _ in web.calls] == ["b-check"]
+        assert results[0].results[0].reason == "skipped: budget exceeded"
+        assert results[1].results[0].status == "pass"
+
+    def test_retries_spend_only_what_granted_checks_leave(self):
+        budget = AgentBudget(3)
+        budget.allot([("t", Validation(name="a")), ("t", Validation(name="b"))])
+
+        assert budget.take("t", "a")
+        assert budget.take_retry()
+        # The last unit stays with "b", which has not run yet
+        assert not budget.take_retry()
+        assert budget.take("t", "b")
+        assert budget.remaining == 0
@@ -0,0 +1,96 @@
+"""Tests for watching the intent directory."""
+
+from __future__ import annotations
+
+import os
+from pathlib import Path
+
+from intentc.build.watch import (
+    IntentWatcher,
+    affected_features,
+    changed_files,
+    snapshot,
+)
+from intentc.core.models import IntentFile, ProjectIntent
+from intentc.core.project import FeatureNode, Project
+
+
+def _project(features: dict[str, list[str]]) -> Project:
+    return Project(
+        project_intent=ProjectIntent(name="test-project"),
+        features={
+            path: FeatureNode(path=path, intents=[IntentFile(name=path, depends_on=deps)])
+            for path, deps in features.items()
+        },
+    )
+
+
+def _touch(path: Path, mtime: float) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.touch()
+    os.utime(path, (mtime, mtime))
+
+
+class TestSnapshot:
+    def test_only_intent_files_are_watched(self, tmp_path: Path):
+        _touch(tmp_path / "project.ic", 100)
+        _touch(tmp_path / "core" / "core.ic", 100)
+        _touch(tmp_path / "core" / "checks.icv", 100)
+        _touch(tmp_path / "core" / "notes.md", 100)
+
+        assert sorted(p.name for p in snapshot(tmp_path)) == [
+            "checks.icv", "core.ic", "project.ic"
+        ]
+
+    def test_changed_files_covers_added_removed_and_modified(self, tmp_path: Path):
+        a, b, c = tmp_path / "a.ic", tmp_path / "b.ic", tmp_path / "c.ic"
+
+        changed = changed_files({a: 1.0, b: 1.0}, {a: 1.0, b: 2.0, c: 1.0})
+
+        assert changed == [b, c]
+        assert changed_files({a: 1.0}, {}) == [a]
+
+
+class TestAffectedFeatures:
+    def test_dependents_are_included_in_order(self, tmp_path: Path):
+        project = _project({"core": [], "api": ["core"], "web": ["api"], "docs": []})
+
+        affected = affected_features(project, tmp_path, [tmp_path / "core" / "core.icv"])
+
+        assert affected == ["core", "api", "web"]
+
+    def test_project_and_shared_files_affect_everything(self, tmp_path: Path):
+        project = _project({"core": [], "docs": []})
+
+        for path in (tmp_path / "project.ic", tmp_path / "implementations" / "py.ic"):
+            assert sorted(affected_features(project, tmp_path, [path])) == ["core", "docs"]
+
+    def test_files_outside_features_are_ignored(self, tmp_path: Path):
+        project = _project({"core": []})
+
+        assert affected_features(project, tmp_path, [tmp_path / "drafts" / "x.ic"]) == []
+
+
+class TestIntentWatcher:
+    def test_burst_of_edits_is_reported_once_settled(self, tmp_path: Path):
+        core, api = tmp_path / "core" / "core.ic", tmp_path / "api" / "api.ic"
+        _touch(core, 100)
+        _touch(api, 100)
+        now = [0.0]
+        # Each poll applies the next edit: nothing, core, api, then quiet
+        edits = [None, (core, 200), (api, 200)]
+
+        def sleep(secs: float) -> None:
+            now[0] += secs
+            if edits:
+                edit = edits.pop(0)
+                if edit:
+                    _touch(*edit)
+
+        watcher = IntentWatcher(
+            tmp_path, debounce=0.5, interval=0.2, clock=lambda: now[0], sleep=sleep
+        )
+
+        assert watcher.wait() == sorted([core, api])
+        # The api edit restarted the debounce window
+        assert now[0] >= 0.6 + 0.5
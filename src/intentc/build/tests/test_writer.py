@@ -0,0 +1,117 @@
+"""Tests for the build file writers."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+from intentc.build.writer import (
+    DiskWriter,
+    MemoryWriter,
+    SnapshotWriter,
+    snapshot_dir,
+)
+
+
+class TestDiskWriter:
+    def test_write_and_delete_report_changes(self, tmp_path: Path):
+        (tmp_path / "keep.txt").write_text("old\n")
+        (tmp_path / "gone.txt").write_text("bye\n")
+        writer = DiskWriter(tmp_path)
+
+        writer.write("pkg/new.txt", "hello\n")
+        writer.write("keep.txt", "new\n")
+        writer.delete("gone.txt")
+
+        assert (tmp_path / "pkg" / "new.txt").read_text() == "hello\n"
+        assert not (tmp_path / "gone.txt").exists()
+        changes = {c.path: c for c in writer.changes()}
+        assert {p: c.change for p, c in changes.items()} == {
+            "gone.txt": "deleted",
+            "keep.txt": "modified",
+            "pkg/new.txt": "created",
+        }
+        assert "-old" in changes["keep.txt"].diff
+        assert "+new" in changes["keep.txt"].diff
+        assert "/dev/null" in changes["pkg/new.txt"].diff
+
+    def test_rewriting_original_content_is_not_a_change(self, tmp_path: Path):
+        (tmp_path / "a.txt").write_text("same\n")
+        writer = DiskWriter(tmp_path)
+        writer.write("a.txt", "other\n")
+        writer.write("a.txt", "same\n")
+
+        assert writer.changes() == []
+
+
+class TestMemoryWriter:
+    def test_writes_stay_in_memory(self, tmp_path: Path):
+        (tmp_path / "a.txt").write_text("disk\n")
+        writer = MemoryWriter(tmp_path)
+
+        writer.write("a.txt", "memory\n")
+        writer.write("b.txt", "new\n")
+
+        assert (tmp_path / "a.txt").read_text() == "disk\n"
+        assert not (tmp_path / "b.txt").exists()
+        assert writer.read("a.txt") == "memory\n"
+        assert [(c.path, c.change) for c in writer.changes()] == [
+            ("a.txt", "modified"),
+            ("b.txt", "created"),
+        ]
+
+    def test_overlay_is_read_but_not_reported(self, tmp_path: Path):
+        first = MemoryWriter(tmp_path)
+        first.write("a.txt", "from first\n")
+
+        second = MemoryWriter(tmp_path, overlay=first.files)
+        assert second.read("a.txt") == "from first\n"
+        second.write("a.txt", "from second\n")
+
+        changes = second.changes()
+        assert [(c.path, c.change) for c in changes] == [("a.txt", "modified")]
+        assert "-from first" in changes[0].diff
+        assert second.files == {"a.txt": "from second\n"}
+
+
+class TestSnapshotWriter:
+    def test_sees_changes_made_outside_the_writer(self, tmp_path: Path):
+        (tmp_path / "a.txt").write_text("one\n")
+        (tmp_path / "b.txt").write_text("two\n")
+        (tmp_path / ".git").mkdir()
+        (tmp_path / ".git" / "HEAD").write_text("ref\n")
+        writer = SnapshotWriter(tmp_path)
+
+        # As an opaque agent would, behind the writer's back
+        (tmp_path / "a.txt").write_text("uno\n")
+        (tmp_path / "b.txt").unlink()
+        (tmp_path / ".git" / "HEAD").write_text("other\n")
+        writer.write("c.txt", "three\n")
+
+        assert [(c.path, c.change) for c in writer.changes()] == [
+            ("a.txt", "modified"),
+            ("b.txt", "deleted"),
+            ("c.txt", "created"),
+        ]
+
+    def test_skipped_dirs_and_listed_paths(self, tmp_path: Path):
+        (tmp_path / "intent").mkdir()
+        (tmp_path / "intent" / "core.ic").write_text("intent\n")
+        writer = SnapshotWriter(tmp_path, skip=["intent"])
+
+        (tmp_path / "intent" / "core.ic").write_text("edited\n")
+        (tmp_path / "main.py").write_text("code\n")
+        (tmp_path / "scratch.log").write_text("noise\n")
+        writer.write("extra.py", "x\n")
+
+        assert [c.path for c in writer.changes()] == ["extra.py", "main.py", "scratch.log"]
+        assert [c.path for c in writer.changes(["main.py", "intent/core.ic"])] == [
+            "extra.py",
+            "main.py",
+        ]
+
+    def test_snapshot_skips_binary_files(self, tmp_path: Path):
+        (tmp_path / "image.bin").write_bytes(b"\xff\xfe\x00")
+        (tmp_path / "a.txt").write_text("x\n")
+
+        assert snapshot_dir(tmp_path) == {"a.txt": "x\n"}
+        assert snapshot_dir(tmp_path / "missing") == {}
@@ -5,7 +5,12 @@ from __future__ import annotations
 import abc
 import json
 import os
+import re
 import secrets
+import threading
+import time
+import urllib.error
+import urllib.request
 from concurrent.futures import ThreadPoolExecutor, as_completed
 from dataclasses import dataclass, field
 from pathlib import Path
@@ -15,6 +20,7 @@ from intentc.build.agents import (
     Agent,
     AgentProfile,
     BuildContext,
+    RateLimiter,
     ValidationResponse,
     create_from_profile,
 )
@@ -25,6 +31,7 @@ from intentc.core.models import (
     Severity,
     Validation,
     ValidationFile,
+    ValidationType,
 )
 from intentc.core.project import Project
 
@@ -50,6 +57,8 @@ class ValidationContext:
     feature_intent: IntentFile
     output_dir: str
     response_file_path: str
+    # Asked before retrying an agent call; False means no budget is left for it
+    may_retry: Callable[[], bool] | None = None
 
 
 # ---------------------------------------------------------------------------
@@ -67,13 +76,62 @@ class ValidationSuiteResult:
     summary: str = ""
 
 
+# ---------------------------------------------------------------------------
+# Dependency ordering
+# ---------------------------------------------------------------------------
+
+
+def _dependency_waves(
+    entries: list[Validation],
+) -> tuple[list[list[int]], dict[int, str]]:
+    """Group entry indices into waves where each entry's prerequisite is in an
+    earlier wave.
+
+    Returns the waves plus a map of index -> reason for entries that can never
+    run (unknown prerequisite, or part of a dependency cycle).
+    """
+    index_by_name = {entry.name: i for i, entry in enumerate(entries)}
+    unresolved: dict[int, str] = {}
+    for i, entry in enumerate(entries):
+        if entry.depends_on is not None and entry.depends_on not in index_by_name:
+            unresolved[i] = f"Depends on unknown validation '{entry.depends_on}'"
+
+    placed: set[int] = set()
+    waves: list[list[int]] = []
+    pending = [i for i in range(len(entries)) if i not in unresolved]
+    while pending:
+        wave = [
+            i
+            for i in pending
+            if entries[i].depends_on is None
+            or index_by_name[entries[i].depends_on] in placed
+            or index_by_name[entries[i].depends_on] in unresolved
+        ]
+        if not wave:
+            for i in pending:
+                unresolved[i] = f"Dependency cycle involving '{entries[i].name}'"
+            break
+        waves.append(wave)
+        placed.update(wave)
+        pending = [i for i in pending if i not in placed]
+
+    return waves, unresolved
+
+
 # ---------------------------------------------------------------------------
 # ValidationRunner interface
 # ---------------------------------------------------------------------------
 
 
 class ValidationRunner(abc.ABC):
-    """Abstract runner interface. Each runner handles one validation type."""
+    """Abstract runner interface. Each runner handles one validation type.
+
+    Runners that call an agent set ``uses_agent``; the suite runs only as many
+    of their validations at a time as the agent's provider accepts (one
+    unless configured otherwise).
+    """
+
+    uses_agent: bool = False
 
     @abc.abstractmethod
     def run(self, validation: Validation, ctx: ValidationContext) -> ValidationResponse:
@@ -84,16 +142,96 @@ class ValidationRunner(abc.ABC):
         ...
 
 
+# ---------------------------------------------------------------------------
+# Per-validation limits
+# ---------------------------------------------------------------------------
+
+_DURATION_PART_RE = re.compile(r"(\d+(?:\.\d+)?)(ms|s|m|h)")
+_DURATION_UNITS = {"ms": 0.001, "s": 1.0, "m": 60.0, "h": 3600.0}
+
+
+def parse_duration(value: object) -> float | None:
+    """Seconds in a validation's ``timeout`` arg, or None if it is not one.
+
+    Accepts a positive number of seconds (``90``, ``"2.5"``) or a duration
+    string made of number-and-unit parts (``"500ms"``, ``"30s"``, ``"5m"``,
+    ``"1h"``, ``"1m30s"``).
+    """
+    if isinstance(value, bool):
+        return None
+    if isinstance(value, (int, float)):
+        return float(value) if value > 0 else None
+    if not isinstance(value, str):
+        return None
+    text = value.strip().lower()
+    try:
+        secs = float(text)
+    except ValueError:
+        parts = _DURATION_PART_RE.findall(text)
+        if not parts or "".join(n + u for n, u in parts) != text:
+            return None
+        secs = sum(float(n) * _DURATION_UNITS[u] for n, u in parts)
+    return secs if secs > 0 else None
+
+
 # ---------------------------------------------------------------------------
 # AgentValidationRunner
 # ---------------------------------------------------------------------------
 
 
 class AgentValidationRunner(ValidationRunner):
-    """Built-in runner for type 'agent_validation'. Delegates to an Agent."""
+    """Built-in runner for type 'agent_validation'. Delegates to an Agent.
+
+    If the validation sets ``args.min_confidence``, a pass reported with a
+    lower confidence (or with no confidence at all) is turned into a fail.
+    The validation's severity still decides whether that fail is an error or
+    a warning.
+
+    ``args.timeout`` (see parse_duration) runs the validation on an agent
+    from *agent_for_timeout* with that timeout instead of the profile's, and
+    ``args.retries`` reruns it that many more times when the agent errors; a
+    fail verdict is not retried, nor is anything once the context's
+    ``may_retry`` says no. Without them the agent's timeout applies and
+    nothing is retried. A value that does not parse is logged as a warning
+    and ignored.
+    """
 
-    def __init__(self, agent: Agent) -> None:
+    uses_agent = True
+
+    def __init__(
+        self,
+        agent: Agent,
+        agent_for_timeout: Callable[[float], Agent] | None = None,
+        log: LogFn | None = None,
+    ) -> None:
         self._agent = agent
+        self._agent_for_timeout = agent_for_timeout
+        self._log = log or (lambda _msg: None)
+
+    def _limits(self, validation: Validation) -> tuple[float | None, int]:
+        """The validation's own timeout (None for the agent's) and retries."""
+        timeout = None
+        raw = validation.args.get("timeout")
+        if raw is not None:
+            timeout = parse_duration(raw)
+            if timeout is None:
+                self._log(
+                    f"    validate: warning: '{validation.name}' timeout {raw!r} "
+                    f"is not a duration, using the agent's"
+                )
+        retries = 0
+        raw = validation.args.get("retries")
+        if raw is not None:
+            if isinstance(raw, int) and not isinstance(raw, bool) and raw >= 0:
+                retries = raw
+            elif isinstance(raw, str) and raw.strip().isdigit():
+                retries = int(raw)
+            else:
+                self._log(
+                    f"    validate: warning: '{validation.name}' retries {raw!r} "
+                    f"is not a count, not retrying"
+                )
+        return timeout, retries
 
     def type(self) -> str:
         return "agent_validation"
@@ -117,15 +255,230 @@ class AgentValidationRunner(ValidationRunner):
             validations=[validation],
         )
 
-        try:
-            response = self._agent.validate(build_ctx, vf)
-            return response
-        except Exception as exc:
-            return ValidationResponse(
-                name=validation.name,
-                status="fail",
-                reason=f"Agent error: {exc}",
+        timeout, retries = self._limits(validation)
+        agent = self._agent
+        if timeout is not None and self._agent_for_timeout is not None:
+            agent = self._agent_for_timeout(timeout)
+
+        for attempt in range(retries + 1):
+            try:
+                response = agent.validate(build_ctx, vf)
+                break
+            except Exception as exc:
+                out_of_budget = (
+                    attempt < retries and ctx.may_retry is not None and not ctx.may_retry()
+                )
+                if out_of_budget:
+                    self._log(
+                        f"    validate: '{validation.name}' agent error, not retrying "
+                        f"(budget exceeded): {exc}"
+                    )
+                if attempt == retries or out_of_budget:
+                    return ValidationResponse(
+                        name=validation.name,
+                        status="fail",
+                        reason=f"Agent error: {exc}",
+                    )
+                self._log(
+                    f"    validate: '{validation.name}' agent error, retrying "
+                    f"({attempt + 1}/{retries}): {exc}"
+                )
+        return _apply_min_confidence(validation, response)
+
+
+def _apply_min_confidence(
+    validation: Validation, response: ValidationResponse
+) -> ValidationResponse:
+    """Fail a pass whose confidence is below the validation's min_confidence."""
+    min_confidence = validation.args.get("min_confidence")
+    if min_confidence is None or response.status != "pass":
+        return response
+
+    if response.confidence is None:
+        reason = f"No confidence reported (minimum {min_confidence})"
+    elif response.confidence < float(min_confidence):
+        reason = (
+            f"Confidence {response.confidence:.2f} is below the minimum "
+            f"{float(min_confidence):.2f}"
+        )
+    else:
+        return response
+
+    return response.model_copy(
+        update={"status": "fail", "reason": f"{reason}: {response.reason}"}
+    )
+
+
+# ---------------------------------------------------------------------------
+# FileCheckRunner
+# ---------------------------------------------------------------------------
+
+
+class FileCheckRunner(ValidationRunner):
+    """Built-in runner for type 'file_check'. Checks the filesystem directly.
+
+    Args (``file`` is relative to the output directory):
+      file      required
+      exists    default true; false asserts the file is absent
+      contains  text, or a list of texts, the file must include
+
+    The first failing assertion is reported.
+    """
+
+    def type(self) -> str:
+        return "file_check"
+
+    def run(self, validation: Validation, ctx: ValidationContext) -> ValidationResponse:
+        def _result(status: str, reason: str) -> ValidationResponse:
+            return ValidationResponse(name=validation.name, status=status, reason=reason)
+
+        args = validation.args
+        file = args.get("file")
+        if not file:
+            return _result("fail", "Missing required arg 'file'")
+
+        path = Path(ctx.output_dir) / str(file)
+        if not args.get("exists", True):
+            if path.exists():
+                return _result("fail", f"File '{file}' exists but should not")
+            return _result("pass", f"File '{file}' does not exist")
+
+        if not path.is_file():
+            return _result("fail", f"File '{file}' does not exist")
+
+        contains = args.get("contains") or []
+        if isinstance(contains, str):
+            contains = [contains]
+        if contains:
+            try:
+                content = path.read_text(encoding="utf-8", errors="replace")
+            except OSError as exc:
+                return _result("fail", f"File '{file}' could not be read: {exc}")
+            for text in contains:
+                if str(text) not in content:
+                    return _result(
+                        "fail", f"File '{file}' does not contain expected text '{text}'"
+                    )
+
+        return _result("pass", f"File '{file}' exists")
+
+
+# ---------------------------------------------------------------------------
+# FolderCheckRunner
+# ---------------------------------------------------------------------------
+
+
+class FolderCheckRunner(ValidationRunner):
+    """Built-in runner for type 'folder_check'. Checks the filesystem directly.
+
+    Args (``folder`` is relative to the output directory):
+      folder         required
+      exists         default true; false asserts the folder is absent
+      recursive      count files in subdirectories too (default false)
+      min_files      minimum number of files
+      max_files      maximum number of files
+      contains_dirs  subdirectories that must be present
+
+    The first failing assertion is reported.
+    """
+
+    def type(self) -> str:
+        return "folder_check"
+
+    def run(self, validation: Validation, ctx: ValidationContext) -> ValidationResponse:
+        def _result(status: str, reason: str) -> ValidationResponse:
+            return ValidationResponse(name=validation.name, status=status, reason=reason)
+
+        args = validation.args
+        folder = args.get("folder")
+        if not folder:
+            return _result("fail", "Missing required arg 'folder'")
+
+        path = Path(ctx.output_dir) / str(folder)
+        if not args.get("exists", True):
+            if path.exists():
+                return _result("fail", f"Folder '{folder}' exists but should not")
+            return _result("pass", f"Folder '{folder}' does not exist")
+
+        if not path.is_dir():
+            return _result("fail", f"Folder '{folder}' does not exist")
+
+        entries = path.rglob("*") if args.get("recursive", False) else path.iterdir()
+        file_count = sum(1 for p in entries if p.is_file())
+
+        min_files = args.get("min_files")
+        if min_files is not None and file_count < int(min_files):
+            return _result(
+                "fail",
+                f"Folder '{folder}' has {file_count} files, expected at least {min_files}",
             )
+        max_files = args.get("max_files")
+        if max_files is not None and file_count > int(max_files):
+            return _result(
+                "fail",
+                f"Folder '{folder}' has {file_count} files, expected at most {max_files}",
+            )
+
+        for sub in args.get("contains_dirs") or []:
+            if not (path / str(sub)).is_dir():
+                return _result(
+                    "fail", f"Folder '{folder}' is missing subdirectory '{sub}'"
+                )
+
+        return _result("pass", f"Folder '{folder}' has {file_count} files")
+
+
+# ---------------------------------------------------------------------------
+# WebCheckRunner
+# ---------------------------------------------------------------------------
+
+
+class WebCheckRunner(ValidationRunner):
+    """Built-in runner for type 'web_check'. Sends an HTTP GET itself.
+
+    Args:
+      url          required
+      status_code  expected response status (default 200)
+      contains     text the response body must include
+      timeout      time to wait for the response (default 10s; see parse_duration)
+
+    A web check with a natural-language ``check`` arg is not run here: the
+    suite hands it to the agent runner instead.
+    """
+
+    def __init__(self, timeout: float = 10.0) -> None:
+        self._timeout = timeout
+
+    def type(self) -> str:
+        return "web_check"
+
+    def run(self, validation: Validation, ctx: ValidationContext) -> ValidationResponse:
+        def _result(status: str, reason: str) -> ValidationResponse:
+            return ValidationResponse(name=validation.name, status=status, reason=reason)
+
+        args = validation.args
+        url = args.get("url")
+        if not url:
+            return _result("fail", "Missing required arg 'url'")
+        expected = int(args.get("status_code", 200))
+        timeout = parse_duration(args.get("timeout")) or self._timeout
+
+        try:
+            with urllib.request.urlopen(str(url), timeout=timeout) as resp:
+                status, body = resp.status, resp.read()
+        except urllib.error.HTTPError as exc:
+            # 4xx/5xx still carry a status (and body) to check
+            status, body = exc.code, exc.read()
+        except (urllib.error.URLError, OSError, ValueError) as exc:
+            reason = getattr(exc, "reason", exc)
+            return _result("fail", f"GET {url} failed: {reason}")
+
+        if status != expected:
+            return _result("fail", f"GET {url} returned {status}, expected {expected}")
+        contains = args.get("contains")
+        if contains and str(contains) not in body.decode("utf-8", errors="replace"):
+            return _result("fail", f"GET {url} response does not contain '{contains}'")
+        return _result("pass", f"GET {url} returned {status}")
 
 
 # ---------------------------------------------------------------------------
@@ -133,8 +486,83 @@ class AgentValidationRunner(ValidationRunner):
 # ---------------------------------------------------------------------------
 
 
+class AgentBudget:
+    """How many agent-backed validations may still run.
+
+    One budget can be shared by several suites so the cap holds across a
+    whole ``intentc validate`` invocation. Once allotted (see allot), only
+    the validations it granted may take from it. Every retry of an agent
+    call costs one more unit (see take_retry).
+    """
+
+    def __init__(self, limit: int) -> None:
+        self._remaining = limit
+        self._granted: set[tuple[str, str]] | None = None
+        self._taken: set[tuple[str, str]] = set()
+        self._lock = threading.Lock()
+
+    @property
+    def remaining(self) -> int:
+        return self._remaining
+
+    def allot(self, pending: list[tuple[str, Validation]]) -> None:
+        """Grant the budget to the best-ranked of *pending* (target, validation) pairs.
+
+        Error-severity validations rank first, then the order given, so a
+        warning in one feature cannot use up the budget an error in a later
+        feature needs.
+        """
+        ranked = sorted(
+            range(len(pending)),
+            key=lambda i: (pending[i][1].severity != Severity.ERROR, i),
+        )
+        with self._lock:
+            self._granted = {
+                (pending[i][0], pending[i][1].name) for i in ranked[: self._remaining]
+            }
+
+    def take(self, target: str = "", name: str = "") -> bool:
+        """Spend one unit of the budget on validation *name* of *target*.
+
+        False if the budget is used up, or was allotted to other validations.
+        """
+        with self._lock:
+            if self._remaining <= 0:
+                return False
+            if self._granted is not None and (target, name) not in self._granted:
+                return False
+            self._remaining -= 1
+            self._taken.add((target, name))
+            return True
+
+    def take_retry(self) -> bool:
+        """Spend one unit of the budget on retrying an agent call.
+
+        False if that would leave too little for the granted validations
+        that have not run yet.
+        """
+        with self._lock:
+            reserved = len(self._granted - self._taken) if self._granted is not None else 0
+            if self._remaining - reserved <= 0:
+                return False
+            self._remaining -= 1
+            return True
+
+
 class ValidationSuite:
-    """Core orchestrator for running validations."""
+    """Core orchestrator for running validations.
+
+    *parallelism* caps how many validations run at once (0 means one worker
+    per CPU). Agent-backed validations additionally hold one of
+    *agent_slots* while they run, so pass the semaphore the build agents use
+    to cap both together; without it the suite allows
+    *max_agent_concurrency* at a time. *limiter* paces the validation
+    agent's requests (see RateLimiter).
+    With *agent_budget*, agent-backed validations past the budget are
+    skipped; error-severity ones get the budget first, then declared order.
+    validate_project and validate_features rank every feature's validations
+    together before any of them runs.
+    """
 
     def __init__(
         self,
@@ -145,20 +573,55 @@ class ValidationSuite:
         val_response_dir: Path | None = None,
         storage_backend: "StorageBackend | None" = None,
         log: Callable[[str], None] | None = None,
+        parallelism: int = 0,
+        limiter: RateLimiter | None = None,
+        agent_budget: AgentBudget | None = None,
+        max_agent_concurrency: int = 1,
+        generation_id: str = "",
+        agent_slots: threading.Semaphore | None = None,
     ) -> None:
         self._project = project
+        self._agent_slots = agent_slots or threading.BoundedSemaphore(
+            max(max_agent_concurrency, 1)
+        )
+        self._agent_budget = agent_budget
+        self._parallelism = parallelism if parallelism > 0 else (os.cpu_count() or 1)
         self._agent_profile = agent_profile
         self._output_dir = output_dir
         self._val_response_dir = val_response_dir
         self._storage_backend = storage_backend
+        # target -> generation this suite files its validation results under
+        self._generation_id = generation_id
+        self._generations: dict[str, str] = {}
+        self._generations_lock = threading.Lock()
+        # Tells this suite's results apart from earlier runs in the same generation
+        self._run_id = secrets.token_hex(8)
         self._log = log or (lambda _msg: None)
 
-        # Create agent and default runner
-        agent = create_from_profile(agent_profile, log=self._log)
-        default_runner = AgentValidationRunner(agent)
+        # Create agent and default runners
+        agent = create_from_profile(agent_profile, log=self._log, limiter=limiter)
+        default_runner = AgentValidationRunner(
+            agent,
+            # A validation's own timeout can shorten the agent's, which may
+            # already be cut to the time left before the deadline, never extend it
+            agent_for_timeout=lambda timeout: create_from_profile(
+                agent_profile.model_copy(
+                    update={"timeout": min(timeout, agent_profile.timeout)}
+                ),
+                log=self._log,
+                limiter=limiter,
+            ),
+            log=self._log,
+        )
+        file_runner = FileCheckRunner()
+        folder_runner = FolderCheckRunner()
+        web_runner = WebCheckRunner()
 
         self._runners: dict[str, ValidationRunner] = {
             default_runner.type(): default_runner,
+            file_runner.type(): file_runner,
+            folder_runner.type(): folder_runner,
+            web_runner.type(): web_runner,
         }
         if runner_registry:
             self._runners.update(runner_registry)
@@ -176,29 +639,34 @@ class ValidationSuite:
                 summary="Feature not found, no validations to run.",
             )
 
-        node = self._project.features[feature]
-        entries: list[Validation] = []
-        for vf in node.validations:
-            entries.extend(vf.validations)
-
+        entries = self._feature_entries(feature)
         self._log(f"Validating feature '{feature}'... ({len(entries)} validations)")
         return self.validate_entries(feature, entries)
 
+    def validate_features(self, features: list[str]) -> list[ValidationSuiteResult]:
+        """Validate each of *features*, ranking their agent validations together."""
+        self._allot_agent_budget([(f, e) for f in features for e in self._feature_entries(f)])
+        return [self.validate_feature(feature) for feature in features]
+
     def validate_project(self) -> list[ValidationSuiteResult]:
         """Run validations for every feature in topological order, plus assertions."""
         topo = self._project.topological_order()
         self._log(f"Validating project ({len(topo)} features)...")
         results: list[ValidationSuiteResult] = []
 
-        for feature_path in topo:
-            result = self.validate_feature(feature_path)
-            results.append(result)
-
         # Project-level assertions
         assertion_entries: list[Validation] = []
         for vf in self._project.assertions:
             assertion_entries.extend(vf.validations)
 
+        self._allot_agent_budget(
+            [(f, e) for f in topo for e in self._feature_entries(f)]
+            + [("project", e) for e in assertion_entries]
+        )
+        for feature_path in topo:
+            result = self.validate_feature(feature_path)
+            results.append(result)
+
         if assertion_entries:
             self._log(f"Running project-level assertions ({len(assertion_entries)} entries)...")
             assertion_result = self.validate_entries("project", assertion_entries)
@@ -209,7 +677,12 @@ class ValidationSuite:
     def validate_entries(
         self, target: str, entries: list[Validation]
     ) -> ValidationSuiteResult:
-        """Run a specific list of validation entries against a target."""
+        """Run a specific list of validation entries against a target.
+
+        Each runner is responsible for its own pass criteria; for agent
+        validations that includes the ``min_confidence`` threshold, so a
+        low-confidence pass arrives here already marked as a fail.
+        """
         if not entries:
             return ValidationSuiteResult(
                 target=target,
@@ -221,11 +694,10 @@ class ValidationSuite:
 
         # Run in parallel, collect in original order
         results_by_index: dict[int, ValidationResponse] = {}
-
         def _run_one(idx: int, entry: Validation) -> tuple[int, ValidationResponse]:
             self._log(f"  Running validation '{entry.name}' ({entry.type.value})...")
 
-            runner = self._runners.get(entry.type.value)
+            runner = self._runner_for(entry)
             if runner is None:
                 resp = ValidationResponse(
                     name=entry.name,
@@ -241,36 +713,78 @@ class ValidationSuite:
                     feature_intent=ctx_base.feature_intent,
                     output_dir=ctx_base.output_dir,
                     response_file_path=str(response_file),
+                    may_retry=(
+                        self._agent_budget.take_retry
+                        if self._agent_budget is not None
+                        else None
+                    ),
+                )
+                slots = self._agent_slots if runner.uses_agent else None
+                if slots is not None:
+                    slots.acquire()
+                try:
+                    start = time.monotonic()
+                    resp = runner.run(entry, ctx)
+                finally:
+                    if slots is not None:
+                        slots.release()
+                resp = resp.model_copy(
+                    update={"duration_secs": time.monotonic() - start}
                 )
-                resp = runner.run(entry, ctx)
 
                 # Persist to storage if available
                 if self._storage_backend is not None:
-                    self._persist_result(entry, resp, response_file)
+                    self._persist_result(target, entry, resp, response_file)
 
             self._log(f"  Validation '{entry.name}': {resp.status}")
             if resp.status != "pass":
                 self._log(f"    Reason: {resp.reason}")
             return idx, resp
 
-        with ThreadPoolExecutor() as executor:
-            futures = {
-                executor.submit(_run_one, i, entry): i
-                for i, entry in enumerate(entries)
-            }
-            for future in as_completed(futures):
-                idx, resp = future.result()
-                results_by_index[idx] = resp
+        # Run wave by wave so prerequisites finish before their dependents;
+        # entries within a wave run in parallel.
+        waves, unresolved = _dependency_waves(entries)
+        for idx, reason in unresolved.items():
+            results_by_index[idx] = ValidationResponse(
+                name=entries[idx].name, status="fail", reason=reason
+            )
+
+        index_by_name = {entry.name: i for i, entry in enumerate(entries)}
+        for wave in waves:
+            runnable: list[int] = []
+            for idx in wave:
+                dep = entries[idx].depends_on
+                dep_resp = results_by_index.get(index_by_name[dep]) if dep else None
+                if dep_resp is not None and dep_resp.status != "pass":
+                    self._log(f"  Validation '{entries[idx].name}': skipped")
+                    results_by_index[idx] = ValidationResponse(
+                        name=entries[idx].name,
+                        status="skipped",
+                        reason=f"skipped: dependency '{dep}' failed",
+                    )
+                else:
+                    runnable.append(idx)
+            runnable = self._within_agent_budget(target, entries, runnable, results_by_index)
+
+            with ThreadPoolExecutor(max_workers=self._parallelism) as executor:
+                futures = {
+                    executor.submit(_run_one, i, entries[i]): i for i in runnable
+                }
+                for future in as_completed(futures):
+                    idx, resp = future.result()
+                    results_by_index[idx] = resp
 
         # Collect in original order
         ordered_results = [results_by_index[i] for i in range(len(entries))]
 
-        # Compute suite result
+        # Compute suite result. Skipped entries are not counted against the
+        # suite: the prerequisite that caused the skip already was.
         passed_count = sum(1 for r in ordered_results if r.status == "pass")
+        skipped_count = sum(1 for r in ordered_results if r.status == "skipped")
         failed = [
             (r, entries[i])
             for i, r in enumerate(ordered_results)
-            if r.status != "pass"
+            if r.status not in ("pass", "skipped")
         ]
         error_count = sum(1 for _, e in failed if e.severity == Severity.ERROR)
         warning_count = sum(1 for _, e in failed if e.severity == Severity.WARNING)
@@ -278,7 +792,8 @@ class ValidationSuite:
         suite_passed = error_count == 0
         summary = (
             f"{passed_count} passed out of {len(entries)} validations "
-            f"({error_count} errors, {warning_count} warnings)"
+            f"({error_count} errors, {warning_count} warnings"
+            + (f", {skipped_count} skipped)" if skipped_count else ")")
         )
 
         return ValidationSuiteResult(
@@ -290,6 +805,60 @@ class ValidationSuite:
 
     # ---- internal helpers ----
 
+    def _runner_for(self, entry: Validation) -> ValidationRunner | None:
+        """Pick the runner registered for *entry*'s type.
+
+        A web check phrased as a natural-language ``check`` goes to the agent
+        runner, since only an agent can judge it.
+        """
+        if entry.type == ValidationType.WEB_CHECK and entry.args.get("check"):
+            return self._runners.get(ValidationType.AGENT_VALIDATION.value)
+        return self._runners.get(entry.type.value)
+
+    def _needs_agent(self, entry: Validation) -> bool:
+        runner = self._runner_for(entry)
+        return runner is not None and runner.uses_agent
+
+    def _feature_entries(self, feature: str) -> list[Validation]:
+        node = self._project.features.get(feature)
+        return [e for vf in node.validations for e in vf.validations] if node else []
+
+    def _allot_agent_budget(self, pending: list[tuple[str, Validation]]) -> None:
+        """Grant the agent budget among the agent-backed entries of *pending*."""
+        if self._agent_budget is not None:
+            self._agent_budget.allot([(t, e) for t, e in pending if self._needs_agent(e)])
+
+    def _within_agent_budget(
+        self,
+        target: str,
+        entries: list[Validation],
+        runnable: list[int],
+        results_by_index: dict[int, ValidationResponse],
+    ) -> list[int]:
+        """Return the entries of *runnable* the agent budget lets run.
+
+        Agent-backed entries the budget does not cover are recorded as
+        skipped in *results_by_index*.
+        """
+        if self._agent_budget is None:
+            return runnable
+
+        by_priority = sorted(
+            (i for i in runnable if self._needs_agent(entries[i])),
+            key=lambda i: (entries[i].severity != Severity.ERROR, i),
+        )
+        over_budget = {
+            i for i in by_priority if not self._agent_budget.take(target, entries[i].name)
+        }
+        for idx in sorted(over_budget):
+            self._log(f"  Validation '{entries[idx].name}': skipped (budget exceeded)")
+            results_by_index[idx] = ValidationResponse(
+                name=entries[idx].name,
+                status="skipped",
+                reason="skipped: budget exceeded",
+            )
+        return [i for i in runnable if i not in over_budget]
+
     def _build_validation_context(self, target: str) -> ValidationContext:
         """Build a base ValidationContext for the given target."""
         project_intent = self._project.project_intent
@@ -317,6 +886,32 @@ class ValidationSuite:
             response_file_path="",  # placeholder, overridden per validation
         )
 
+    def _generation_for(self, target: str) -> str:
+        """The generation to file *target*'s validation results under.
+
+        Results belong to the build they checked: the suite's
+        *generation_id* while a build runs, else the target's latest build
+        generation. A target with no recorded build gets one ``val-``
+        generation for this suite, so its results still group together.
+        """
+        assert self._storage_backend is not None
+        if self._generation_id:
+            return self._generation_id
+        with self._generations_lock:
+            if target not in self._generations:
+                build = self._storage_backend.get_build_result(target)
+                if build is not None and build.generation_id:
+                    generation_id = build.generation_id
+                else:
+                    generation_id = f"val-{secrets.token_hex(4)}"
+                    # A generation record satisfies the FK on validation_results
+                    self._storage_backend.create_generation(
+                        generation_id=generation_id,
+                        output_dir=self._output_dir,
+                    )
+                self._generations[target] = generation_id
+            return self._generations[target]
+
     def _make_response_path(self, validation_name: str) -> Path:
         """Create a unique response file path for a validation."""
         base_dir = self._val_response_dir or Path(self._output_dir)
@@ -326,6 +921,7 @@ class ValidationSuite:
 
     def _persist_result(
         self,
+        target: str,
         entry: Validation,
         resp: ValidationResponse,
         response_file: Path,
@@ -333,24 +929,19 @@ class ValidationSuite:
         """Save validation result and agent response to storage, then clean up."""
         assert self._storage_backend is not None
 
-        generation_id = f"val-{secrets.token_hex(4)}"
-
-        # Create a generation record so the FK on validation_results is satisfied.
-        self._storage_backend.create_generation(
-            generation_id=generation_id,
-            output_dir=self._output_dir,
-        )
-
+        generation_id = self._generation_for(target)
         val_result_id = self._storage_backend.save_validation_result(
             build_result_id=None,
             generation_id=generation_id,
-            target=entry.name,
+            target=target,
             validation_file_version_id=None,
             name=resp.name,
             type=entry.type.value,
             severity=entry.severity.value,
             status=resp.status,
             reason=resp.reason,
+            duration_secs=resp.duration_secs,
+            run_id=self._run_id,
         )
 
         # Read and persist agent response JSON if file exists
@@ -0,0 +1,107 @@
+"""Watching the intent directory for edits, for rebuild-on-save loops."""
+
+from __future__ import annotations
+
+import time
+from pathlib import Path
+from typing import Callable
+
+from intentc.core.project import Project
+
+# Files whose edits change what a build produces
+WATCHED_SUFFIXES = (".ic", ".icy", ".icv")
+
+# Top-level intent directories shared by every feature
+_SHARED_DIRS = {"implementations", "assertions"}
+
+Snapshot = dict[Path, float]
+
+
+def snapshot(intent_dir: Path) -> Snapshot:
+    """Return the modification time of every watched file under *intent_dir*."""
+    result: Snapshot = {}
+    for path in intent_dir.rglob("*"):
+        if path.suffix not in WATCHED_SUFFIXES or not path.is_file():
+            continue
+        try:
+            result[path] = path.stat().st_mtime
+        except OSError:
+            continue  # Removed between listing and stat
+    return result
+
+
+def changed_files(before: Snapshot, after: Snapshot) -> list[Path]:
+    """Files added, removed or modified between two snapshots, sorted."""
+    return sorted(
+        p for p in before.keys() | after.keys() if before.get(p) != after.get(p)
+    )
+
+
+def affected_features(
+    project: Project, intent_dir: Path, changed: list[Path]
+) -> list[str]:
+    """Features to rebuild after *changed* files, in topological order.
+
+    A file inside a feature's directory affects that feature and everything
+    that depends on it. ``project.ic`` and the shared implementation and
+    assertion files affect every feature. Files in directories that are not
+    (or are no longer) features are ignored.
+    """
+    touched: set[str] = set()
+    for path in changed:
+        rel = path.relative_to(intent_dir)
+        if len(rel.parts) < 2 or rel.parts[0] in _SHARED_DIRS:
+            return project.topological_order()
+        feature = str(rel.parent).replace("\\", "/")
+        if feature in project.features:
+            touched.add(feature)
+    for feature in list(touched):
+        touched |= project.descendants(feature)
+    return [f for f in project.topological_order() if f in touched]
+
+
+class IntentWatcher:
+    """Polls the intent directory and reports edits once they settle.
+
+    Editors often save a file in several steps, and a change to one intent
+    tends to come with changes to its neighbours, so a burst of edits is
+    collected until nothing has changed for *debounce* seconds. *clock* and
+    *sleep* are injectable for tests.
+    """
+
+    def __init__(
+        self,
+        intent_dir: Path,
+        debounce: float = 0.5,
+        interval: float = 0.2,
+        clock: Callable[[], float] = time.monotonic,
+        sleep: Callable[[float], None] = time.sleep,
+    ) -> None:
+        self._intent_dir = intent_dir
+        self._debounce = debounce
+        self._interval = interval
+        self._clock = clock
+        self._sleep = sleep
+        self._snapshot = snapshot(intent_dir)
+
+    @property
+    def intent_dir(self) -> Path:
+        return self._intent_dir
+
+    def wait(self) -> list[Path]:
+        """Block until files change, then return every file changed in the burst."""
+        while True:
+            self._sleep(self._interval)
+            latest = snapshot(self._intent_dir)
+            if not changed_files(self._snapshot, latest):
+                continue
+            settled_at = self._clock()
+            while self._clock() - settled_at < self._debounce:
+                self._sleep(self._interval)
+                current = snapshot(self._intent_dir)
+                if current != latest:
+                    latest, settled_at = current, self._clock()
+            changed = changed_files(self._snapshot, latest)
+            self._snapshot = latest
+            if changed:  # An edit that was undone within the burst is no change
+                return changed
@@ -0,0 +1,216 @@
+"""File writers: where a build's generated files go, and what changed."""
+
+from __future__ import annotations
+
+import abc
+import difflib
+from collections.abc import Iterable
+from dataclasses import dataclass
+from pathlib import Path
+
+# Directories never treated as generated output
+_SKIP_DIRS = {".git", ".intentc"}
+
+
+@dataclass
+class FileChange:
+    """One file a build created, modified or deleted."""
+
+    path: str  # Relative to the writer's root, POSIX separators
+    change: str  # "created", "modified" or "deleted"
+    diff: str  # Unified diff against the previous content
+
+
+def _read_text(path: Path) -> str | None:
+    """Return the file's text, or None if it is missing or not UTF-8."""
+    try:
+        return path.read_text(encoding="utf-8")
+    except (OSError, UnicodeDecodeError):
+        return None
+
+
+def _unified_diff(path: str, before: str | None, after: str | None) -> str:
+    return "".join(
+        difflib.unified_diff(
+            (before or "").splitlines(keepends=True),
+            (after or "").splitlines(keepends=True),
+            fromfile="/dev/null" if before is None else f"a/{path}",
+            tofile="/dev/null" if after is None else f"b/{path}",
+        )
+    )
+
+
+def _compare(path: str, before: str | None, after: str | None) -> FileChange | None:
+    if before == after:
+        return None
+    if before is None:
+        change = "created"
+    elif after is None:
+        change = "deleted"
+    else:
+        change = "modified"
+    return FileChange(path, change, _unified_diff(path, before, after))
+
+
+def is_skipped(rel: str, skip: Iterable[str] = ()) -> bool:
+    """True if the relative POSIX path *rel* is never build output.
+
+    That is anything under ``.git`` or ``.intentc``, or under one of the
+    relative directories in *skip*.
+    """
+    if _SKIP_DIRS.intersection(rel.split("/")):
+        return True
+    return any(rel == s or rel.startswith(f"{s}/") for s in skip)
+
+
+def snapshot_dir(root: Path, skip: Iterable[str] = ()) -> dict[str, str]:
+    """Map every text file under *root* to its content.
+
+    Files that are not UTF-8 are left out, as is everything is_skipped
+    rules out.
+    """
+    if not root.is_dir():
+        return {}
+    skip = tuple(skip)
+    files: dict[str, str] = {}
+    for path in sorted(root.rglob("*")):
+        rel = path.relative_to(root)
+        if not path.is_file() or is_skipped(rel.as_posix(), skip):
+            continue
+        content = _read_text(path)
+        if content is not None:
+            files[rel.as_posix()] = content
+    return files
+
+
+class FileWriter(abc.ABC):
+    """Destination for the files an agent generates under *root*.
+
+    Agents that cooperate read and write through the writer instead of the
+    filesystem, which lets the Builder decide where the content lands.
+    ``changes()`` reports everything written so far relative to the state
+    the writer started from.
+    """
+
+    def __init__(self, root: Path) -> None:
+        self._root = root
+        # Content of each touched path before its first write (None = absent)
+        self._before: dict[str, str | None] = {}
+
+    @property
+    def root(self) -> Path:
+        return self._root
+
+    def read(self, path: str) -> str | None:
+        """Return the current content of *path*, or None if it does not exist."""
+        return _read_text(self._root / path)
+
+    def write(self, path: str, content: str) -> None:
+        self._remember(path)
+        self._store(path, content)
+
+    def delete(self, path: str) -> None:
+        self._remember(path)
+        self._store(path, None)
+
+    def changes(self) -> list[FileChange]:
+        """Files that differ from when the writer started, sorted by path."""
+        found = (
+            _compare(path, before, self.read(path))
+            for path, before in sorted(self._before.items())
+        )
+        return [c for c in found if c is not None]
+
+    def _remember(self, path: str) -> None:
+        if path not in self._before:
+            self._before[path] = self.read(path)
+
+    @abc.abstractmethod
+    def _store(self, path: str, content: str | None) -> None:
+        """Persist *content* at *path*; None removes the file."""
+
+
+class DiskWriter(FileWriter):
+    """Writes straight to disk under the root."""
+
+    def _store(self, path: str, content: str | None) -> None:
+        target = self._root / path
+        if content is None:
+            target.unlink(missing_ok=True)
+            return
+        target.parent.mkdir(parents=True, exist_ok=True)
+        target.write_text(content, encoding="utf-8")
+
+
+class MemoryWriter(FileWriter):
+    """Keeps writes in memory; the root is only ever read.
+
+    *overlay* holds pending content from earlier writers (path -> content,
+    None for deletions), so a sequence of in-memory builds sees each other's
+    output without touching disk.
+    """
+
+    def __init__(
+        self, root: Path, overlay: dict[str, str | None] | None = None
+    ) -> None:
+        super().__init__(root)
+        self._overlay = dict(overlay or {})
+        self._files: dict[str, str | None] = {}
+
+    @property
+    def files(self) -> dict[str, str | None]:
+        """Everything pending, including the overlay it started from."""
+        return {**self._overlay, **self._files}
+
+    def read(self, path: str) -> str | None:
+        if path in self._files:
+            return self._files[path]
+        if path in self._overlay:
+            return self._overlay[path]
+        return super().read(path)
+
+    def _store(self, path: str, content: str | None) -> None:
+        self._files[path] = content
+
+
+class SnapshotWriter(DiskWriter):
+    """A disk writer that also sees changes made behind its back.
+
+    The root is snapshotted when the writer is created, so ``changes()``
+    covers files an opaque agent wrote directly (e.g. from a shell command)
+    as well as those written through the writer. Directories in *skip*
+    (relative to the root) are not part of the snapshot.
+    """
+
+    def __init__(self, root: Path, skip: Iterable[str] = ()) -> None:
+        super().__init__(root)
+        self._skip = tuple(skip)
+        self._before = snapshot_dir(root, self._skip)
+        self._written: set[str] = set()
+
+    def _remember(self, path: str) -> None:
+        # Anything missing from the snapshot did not exist when the build began
+        self._before.setdefault(path, None)
+        self._written.add(path)
+
+    def changes(self, paths: Iterable[str] | None = None) -> list[FileChange]:
+        """Files that differ from the snapshot, sorted by path.
+
+        With *paths* (say, the files the agent reported), only those and
+        the files written through the writer are compared, and the root is
+        not walked again.
+        """
+        if paths is not None:
+            candidates = {p for p in paths if not is_skipped(p, self._skip)}
+            candidates |= self._written
+            found = (
+                _compare(path, self._before.get(path), self.read(path))
+                for path in sorted(candidates)
+            )
+            return [c for c in found if c is not None]
+        after = snapshot_dir(self._root, self._skip)
+        found = (
+            _compare(path, self._before.get(path), after.get(path))
+            for path in sorted(set(self._before) | set(after))
+        )
+        return [c for c in found if c is not None]
@@ -7,7 +7,134 @@ from pathlib import Path
 import yaml
 from pydantic import BaseModel, Field
 
-from intentc.build.agents import AgentProfile
+from intentc.build.agents import (
+    AgentProfile,
+    PromptTemplates,
+    check_prompt_template,
+    load_default_prompts,
+)
+from intentc.core.models import Severity
+
+
+class ProjectConfig(BaseModel):
+    """Settings from the ``project`` section of the config."""
+
+    # Directory, relative to the project root, holding project.ic and the features
+    intent_dir: str = "intent"
+
+
+class BuildConfig(BaseModel):
+    """Build settings from the ``build`` section of the config."""
+
+    # Refuse to build (unless --ignore-disk-space) when the output filesystem has less free space
+    min_free_mb: int = 100
+    # Write a JSONL event stream to .intentc/logs/ for every build
+    events: bool = False
+    # Run each target's validations right after it builds; failures fail the target
+    validate_after_build: bool = True
+    # Build into the git worktree .intentc/worktrees/<name> on branch intentc/<name>
+    worktree: str = ""
+    # Targets built at once when their dependencies are done; 1 builds one after another
+    parallelism: int = 1
+
+
+class ValidationsConfig(BaseModel):
+    """Settings from the ``validations`` section of the config."""
+
+    # Validations run at once; 0 means one per CPU. Agent checks are capped by agents.max_concurrency
+    parallelism: int = 0
+
+
+class LintConfig(BaseModel):
+    """Settings for ``intentc lint`` from the ``lint`` section of the config."""
+
+    # Replaces the built-in technology word list when set
+    terms: list[str] | None = None
+    # Added to the word list (built-in or replaced)
+    extra_terms: list[str] = Field(default_factory=list)
+    # Severity of each finding; errors make lint exit non-zero
+    severity: Severity = Severity.WARNING
+
+
+class GitConfig(BaseModel):
+    """Settings from the ``git`` section of the config."""
+
+    # Commit intent files written by scaffolding commands, with an "intent:" message
+    auto_commit_intent: bool = False
+
+
+class AgentsConfig(BaseModel):
+    """Settings from the ``agents`` section of the config."""
+
+    # Requests per minute for each provider (e.g. {"claude": 50}), shared by
+    # every agent of that provider in one command; unlisted providers are unlimited
+    rate_limits: dict[str, float] = Field(default_factory=dict)
+    # Requests each provider accepts at once (e.g. {"claude": 3}); agent
+    # validations of an unlisted provider run one at a time
+    max_concurrency: dict[str, int] = Field(default_factory=dict)
+    # Profiles besides default_profile, picked for targets whose intents list
+    # requires_capabilities the default profile lacks; the first match wins
+    profiles: list[AgentProfile] = Field(default_factory=list)
+
+
+class LoggingConfig(BaseModel):
+    """Settings from the ``logging`` section of the config."""
+
+    # Regexes whose matches are hidden in log lines and stored agent output.
+    # Replaces the built-in patterns (AWS keys, bearer tokens, API keys) when set
+    redact: list[str] | None = None
+    # Added to the patterns (built-in or replaced)
+    extra_redact: list[str] = Field(default_factory=list)
+
+
+class RetentionConfig(BaseModel):
+    """Build history kept by ``intentc gc``, from the ``retention`` section.
+
+    Each target's latest build result is always kept; 0 turns a rule off.
+    """
+
+    # Keep each target's N newest build results
+    keep_last: int = 0
+    # Keep build results recorded within this many days
+    keep_days: float = 0
+
+
+class PromptsConfig(BaseModel):
+    """Prompt template overrides from the ``prompts`` section of the config.
+
+    Each value names a file under .intentc/templates/; an empty value, or a
+    file that does not exist, keeps the bundled template.
+    """
+
+    build: str = ""
+    validate_template: str = ""
+    plan: str = ""
+    difference: str = ""
+    init: str = ""
+
+
+def load_prompt_templates(project_root: Path, prompts: PromptsConfig) -> PromptTemplates | None:
+    """Return the bundled templates with the configured overrides applied.
+
+    Returns None when no override file exists, so agents keep their own
+    defaults. Raises ValueError naming the config key if a template has
+    malformed braces or a placeholder its prompt is not rendered with.
+    """
+    templates_dir = project_root / ".intentc" / "templates"
+    overrides: dict[str, str] = {}
+    for field, filename in prompts.model_dump().items():
+        path = templates_dir / filename
+        if not filename or not path.is_file():
+            continue
+        text = path.read_text(encoding="utf-8")
+        try:
+            check_prompt_template(field, text)
+        except ValueError as exc:
+            raise ValueError(f"prompts.{field} ({path}): {exc}") from exc
+        overrides[field] = text
+    if not overrides:
+        return None
+    return load_default_prompts().model_copy(update=overrides)
 
 
 class Config(BaseModel):
@@ -22,19 +149,33 @@ class Config(BaseModel):
         )
     )
     default_output_dir: str = "src"
+    project: ProjectConfig = Field(default_factory=ProjectConfig)
+    build: BuildConfig = Field(default_factory=BuildConfig)
+    validations: ValidationsConfig = Field(default_factory=ValidationsConfig)
+    lint: LintConfig = Field(default_factory=LintConfig)
+    git: GitConfig = Field(default_factory=GitConfig)
+    logging: LoggingConfig = Field(default_factory=LoggingConfig)
+    agents: AgentsConfig = Field(default_factory=AgentsConfig)
+    retention: RetentionConfig = Field(default_factory=RetentionConfig)
+    prompts: PromptsConfig = Field(default_factory=PromptsConfig)
 
 
-def load_config(project_root: Path) -> Config:
-    """Load config from .intentc/config.yaml, returning defaults if missing."""
+def _read_raw_config(project_root: Path) -> dict:
+    """Return the raw YAML mapping from the config file, or {} if unusable."""
     config_path = project_root / ".intentc" / "config.yaml"
     if not config_path.exists():
-        return Config()
-
+        return {}
     try:
         with open(config_path, "r", encoding="utf-8") as f:
             data = yaml.safe_load(f) or {}
     except (yaml.YAMLError, OSError):
-        return Config()
+        return {}
+    return data if isinstance(data, dict) else {}
+
+
+def load_config(project_root: Path) -> Config:
+    """Load config from .intentc/config.yaml, returning defaults if missing."""
+    data = _read_raw_config(project_root)
 
     profile_data = data.get("default_profile")
     if profile_data and isinstance(profile_data, dict):
@@ -44,7 +185,115 @@ def load_config(project_root: Path) -> Config:
 
     output_dir = data.get("default_output_dir", "src")
 
-    return Config(default_profile=profile, default_output_dir=output_dir)
+    project_data = data.get("project")
+    project = (
+        ProjectConfig(**project_data) if isinstance(project_data, dict) else ProjectConfig()
+    )
+
+    build_data = data.get("build")
+    build = BuildConfig(**build_data) if isinstance(build_data, dict) else BuildConfig()
+
+    validations_data = data.get("validations")
+    validations = (
+        ValidationsConfig(**validations_data)
+        if isinstance(validations_data, dict)
+        else ValidationsConfig()
+    )
+
+    lint_data = data.get("lint")
+    lint = LintConfig(**lint_data) if isinstance(lint_data, dict) else LintConfig()
+
+    git_data = data.get("git")
+    git = GitConfig(**git_data) if isinstance(git_data, dict) else GitConfig()
+
+    logging_data = data.get("logging")
+    logging = (
+        LoggingConfig(**logging_data)
+        if isinstance(logging_data, dict)
+        else LoggingConfig()
+    )
+
+    agents_data = data.get("agents")
+    agents = AgentsConfig(**agents_data) if isinstance(agents_data, dict) else AgentsConfig()
+
+    retention_data = data.get("retention")
+    retention = (
+        RetentionConfig(**retention_data)
+        if isinstance(retention_data, dict)
+        else RetentionConfig()
+    )
+
+    prompts_data = data.get("prompts")
+    prompts = PromptsConfig(**prompts_data) if isinstance(prompts_data, dict) else PromptsConfig()
+
+    return Config(
+        default_profile=profile,
+        default_output_dir=output_dir,
+        project=project,
+        build=build,
+        validations=validations,
+        lint=lint,
+        git=git,
+        logging=logging,
+        agents=agents,
+        retention=retention,
+        prompts=prompts,
+    )
+
+
+def _flatten(data: dict, prefix: str = "") -> dict[str, object]:
+    """Flatten nested mappings into dotted keys."""
+    flat: dict[str, object] = {}
+    for key, value in data.items():
+        dotted = f"{prefix}{key}"
+        if isinstance(value, dict):
+            flat.update(_flatten(value, f"{dotted}."))
+        else:
+            flat[dotted] = value
+    return flat
+
+
+def config_sources(project_root: Path) -> dict[str, str]:
+    """Map each dotted config key to where its value came from.
+
+    A key is ``"file"`` if .intentc/config.yaml sets it and ``"default"``
+    otherwise. Callers that apply command-line overrides mark those keys
+    ``"flag"`` themselves.
+    """
+    in_file = _flatten(_read_raw_config(project_root))
+    return {
+        key: "file" if key in in_file else "default"
+        for key in _flatten(Config().model_dump(mode="json"))
+    }
+
+
+def _yaml_scalar(value: object) -> str:
+    text = yaml.safe_dump(value, default_flow_style=True, width=float("inf"))
+    return text.removesuffix("\n...\n").strip()
+
+
+def dump_config(config: Config, sources: dict[str, str] | None = None) -> str:
+    """Render *config* as YAML.
+
+    With *sources* (see config_sources), every value is followed by a
+    comment naming its source.
+    """
+    lines: list[str] = []
+
+    def _emit(data: dict, prefix: str, indent: str) -> None:
+        for key, value in data.items():
+            dotted = f"{prefix}{key}"
+            if isinstance(value, dict):
+                lines.append(f"{indent}{key}:")
+                _emit(value, f"{dotted}.", indent + "  ")
+                continue
+            line = f"{indent}{key}: {_yaml_scalar(value)}"
+            if sources is not None:
+                line += f"  # {sources.get(dotted, 'default')}"
+            lines.append(line)
+
+    _emit(config.model_dump(mode="json"), "", "")
+    return "\n".join(lines) + "\n"
 
 
 def save_config(config: Config, project_root: Path) -> Path:
@@ -61,6 +310,15 @@ def save_config(config: Config, project_root: Path) -> Path:
             "retries": config.default_profile.retries,
         },
         "default_output_dir": config.default_output_dir,
+        "project": config.project.model_dump(),
+        "build": config.build.model_dump(),
+        "validations": config.validations.model_dump(),
+        "lint": config.lint.model_dump(mode="json"),
+        "git": config.git.model_dump(),
+        "logging": config.logging.model_dump(),
+        "agents": config.agents.model_dump(),
+        "retention": config.retention.model_dump(),
+        "prompts": config.prompts.model_dump(),
     }
 
     with open(config_path, "w", encoding="utf-8") as f:
@@ -2,25 +2,64 @@
 
 from __future__ import annotations
 
+import os
+import re
+import shutil
+import subprocess
 import sys
+import time
 from datetime import datetime
 from pathlib import Path
-from typing import Optional
+from typing import Callable, NoReturn, Optional
 
 import typer
-
-from intentc.cli.config import Config, load_config, save_config
+from rich.markup import escape
+
+from intentc.cli.config import (
+    Config,
+    config_sources,
+    dump_config,
+    load_config,
+    load_prompt_templates,
+    save_config,
+)
 from intentc.cli.output import (
+    Verbosity,
+    color_enabled,
     console,
+    get_verbosity,
+    print_debug,
     print_error,
+    print_warning,
     render_build_results,
+    render_build_stats,
+    render_build_state_diff,
     render_compare_results,
     render_diff,
+    render_file_changes,
+    render_generation_diff,
+    render_graph,
+    render_history_table,
+    render_slowest_builds,
     render_init_summary,
+    render_junit_report,
+    render_status_matrix,
     render_status_table,
     render_validation_results,
+    render_validation_summary,
+    reset_color,
+    set_color,
+    set_verbosity,
+)
+from intentc.core.models import (
+    DependencyCycleError,
+    IntentFile,
+    ParseErrors,
+    Severity,
+    UnbuiltDependencyError,
+    Validation,
+    ValidationFile,
 )
-from intentc.core.models import IntentFile, ParseErrors
 from intentc.core.parser import write_intent_file
 from intentc.core.project import Project, blank_project, load_project, write_project
 
@@ -31,39 +70,353 @@ app = typer.Typer(
     no_args_is_help=True,
 )
 
+# time.monotonic() value set by --timeout; commands stop starting agent work after it
+_deadline: float | None = None
+
 
 # ---------------------------------------------------------------------------
 # Helpers
 # ---------------------------------------------------------------------------
 
 
+def _project_root() -> Path:
+    """Find the project root and make it the working directory.
+
+    Walks up from the working directory to the nearest directory holding
+    ``.intentc`` or ``intent/project.ic``, so commands work from anywhere
+    inside a project. Output directories are relative to the root, hence the
+    chdir. Without a match the working directory is returned unchanged and
+    the command reports the missing project as usual.
+    """
+    cwd = Path.cwd()
+    for candidate in (cwd, *cwd.parents):
+        if (candidate / ".intentc").is_dir() or (candidate / "intent" / "project.ic").is_file():
+            if candidate != cwd:
+                os.chdir(candidate)
+            return candidate
+    return cwd
+
+
+def _build_slug(name: str) -> str:
+    """*name* made safe for a ``build-<name>`` directory."""
+    return re.sub(r"[^\w.-]+", "-", name).strip("-") or "project"
+
+
+def _fresh_output_dir(root: Path, name: str) -> str:
+    """Return an unused output directory named ``build-<name>-<timestamp>``."""
+    base = f"build-{_build_slug(name)}-{datetime.now().strftime('%Y%m%d-%H%M%S')}"
+    candidate, n = base, 1
+    while (root / candidate).exists() or (root / ".intentc" / "state" / candidate).exists():
+        n += 1
+        candidate = f"{base}-{n}"
+    return candidate
+
+
+def _prune_empty_dirs(path: Path) -> None:
+    """Remove *path* and the directories under it that hold no files."""
+    if not path.is_dir():
+        return
+    for dirpath, _, _ in sorted(os.walk(path), key=lambda w: len(w[0]), reverse=True):
+        try:
+            os.rmdir(dirpath)
+        except OSError:
+            pass  # not empty
+
+
+def _intent_dir(root: Path, config: Config | None = None) -> Path:
+    """The project's intent directory (config ``project.intent_dir``).
+
+    Pass *config* when the command has already loaded it.
+    """
+    return root / (config or load_config(root)).project.intent_dir
+
+
 def _load_project_or_exit(intent_dir: Path) -> Project:
-    """Load a project, printing a friendly error and exiting on parse failure."""
+    """Load a project, printing a friendly error and exiting on parse failure.
+
+    Validation entries that loaded but look wrong are printed as warnings.
+    """
     try:
-        return load_project(intent_dir)
+        project = load_project(intent_dir)
     except ParseErrors as exc:
         for err in exc.errors:
-            print_error(
-                f"{err.source_path}:{err.line}: {err.message}"
-                if hasattr(err, "line") and err.line
-                else f"{err.source_path}: {err.message}"
-                if hasattr(err, "source_path") and err.source_path
-                else str(err.message)
-            )
+            print_error(escape(str(err)))
         raise typer.Exit(code=2)
+    validation_files = [*project.assertions]
+    for feature in project.features.values():
+        validation_files.extend(feature.validations)
+    for vf in validation_files:
+        for warning in vf.warnings:
+            print_warning(escape(warning))
+    return project
+
+
+def _exit_with_error(exc: Exception) -> NoReturn:
+    """Print *exc*, with a hint where its type suggests one, and exit.
+
+    Problems with the command or the project (an unknown target, a
+    dependency cycle, an unknown agent provider or profile, no agent with
+    the required capabilities) exit with code 2; anything else, an unbuilt dependency
+    included, is a failed build and exits 1.
+    """
+    from intentc.build.agents import (
+        NoCapableAgentError,
+        UnknownAgentError,
+        UnknownProfileError,
+    )
+
+    if isinstance(exc, DependencyCycleError):
+        print_error(escape(f"{exc}. Remove one of these depends_on entries to break it."))
+        raise typer.Exit(code=2)
+    if isinstance(exc, UnknownAgentError):
+        print_error(escape(f"{exc}. Check the profile's provider in .intentc/config.yaml."))
+        raise typer.Exit(code=2)
+    if isinstance(exc, NoCapableAgentError):
+        print_error(
+            escape(f"{exc}. Declare them in a profile's capabilities under agents.profiles in .intentc/config.yaml.")
+        )
+        raise typer.Exit(code=2)
+    if isinstance(exc, UnknownProfileError):
+        print_error(escape(f"{exc}. Define it under agents.profiles in .intentc/config.yaml."))
+        raise typer.Exit(code=2)
+    if isinstance(exc, KeyError):
+        print_error(escape(exc.args[0]))
+        raise typer.Exit(code=2)
+    if isinstance(exc, UnbuiltDependencyError):
+        print_error(escape(f"{exc} Build '{exc.dependency}' first."))
+        raise typer.Exit(code=1)
+    print_error(escape(str(exc)))
+    raise typer.Exit(code=1)
+
+
+def _load_config(root: Path, templates: bool = False) -> Config:
+    """Load the config and apply its process-wide settings (secret redaction).
+
+    With *templates*, for the commands that run agents, the prompt template
+    files named in the ``prompts`` section are loaded, checked and given to
+    every configured profile that does not set its own.
+    """
+    from intentc.build.redact import DEFAULT_REDACT_PATTERNS, set_redact_patterns
+
+    config = load_config(root)
+    patterns = config.logging.redact
+    patterns = DEFAULT_REDACT_PATTERNS if patterns is None else patterns
+    try:
+        set_redact_patterns([*patterns, *config.logging.extra_redact])
+    except re.error as exc:
+        print_error(escape(f"Invalid logging.redact pattern: {exc}"))
+        raise typer.Exit(code=2)
+    if not templates:
+        return config
+    try:
+        prompt_templates = load_prompt_templates(root, config.prompts)
+    except (ValueError, OSError) as exc:
+        print_error(escape(f"Invalid prompt template {exc}"))
+        raise typer.Exit(code=2)
+    if prompt_templates is not None:
+        for profile in (config.default_profile, *config.agents.profiles):
+            if profile.prompt_templates is None:
+                profile.prompt_templates = prompt_templates
+    return config
 
 
 def _make_log_callback():
-    """Create a timestamped log callback using Rich."""
+    """Create a timestamped log callback using Rich.
+
+    In quiet mode progress logging is suppressed entirely; command results
+    and errors are still printed.
+    """
+    if get_verbosity() == Verbosity.QUIET:
+        return lambda _msg: None
+
+    from intentc.build.redact import redact
+
     def _log(msg: str) -> None:
         ts = datetime.now().strftime("%H:%M:%S")
-        console.print(f"[dim]{ts}[/dim] {msg}")
+        console.print(f"[dim]{ts}[/dim] {redact(msg)}")
     return _log
 
 
+def _is_interactive() -> bool:
+    """True when stdin is a terminal a user can answer prompts on."""
+    return sys.stdin.isatty()
+
+
+def _is_terminal_output() -> bool:
+    """True when stdout is a terminal that can be cleared and redrawn."""
+    return sys.stdout.isatty()
+
+
+def _confirm_destructive(summary: str, items: list[str], force: bool) -> None:
+    """List what is about to be removed and ask before continuing.
+
+    Skipped with --force or when not attached to a terminal. Exits with
+    code 1 if the user declines.
+    """
+    if force or not items or not _is_interactive():
+        return
+    console.print(summary)
+    for item in items[:20]:
+        console.print(f"  {item}")
+    if len(items) > 20:
+        console.print(f"  ... and {len(items) - 20} more")
+    if not typer.confirm("Continue?", default=False):
+        console.print("Aborted.")
+        raise typer.Exit(code=1)
+
+
+def _filter_validations(
+    project: Project, skip: list[str], only: list[str]
+) -> int:
+    """Drop validations excluded by --skip/--only from the project, in place.
+
+    A kept validation whose prerequisite was dropped runs unconditionally.
+    Returns the number of validations dropped. Exits with code 2 if a name
+    matches no validation.
+    """
+    files = [vf for node in project.features.values() for vf in node.validations]
+    files.extend(project.assertions)
+
+    known = {v.name for vf in files for v in vf.validations}
+    unknown = [name for name in [*skip, *only] if name not in known]
+    if unknown:
+        print_error(f"No validation named {', '.join(repr(n) for n in unknown)}.")
+        raise typer.Exit(code=2)
+
+    dropped = 0
+    for vf in files:
+        dropped += _keep_validations(
+            vf, lambda v: v.name not in skip and (not only or v.name in only)
+        )
+    return dropped
+
+
+def _keep_validations(vf: ValidationFile, keep: Callable[[Validation], bool]) -> int:
+    """Drop the validations of *vf* that *keep* rejects; return how many.
+
+    A kept validation whose prerequisite was dropped runs unconditionally.
+    """
+    kept = [v for v in vf.validations if keep(v)]
+    kept_names = {v.name for v in kept}
+    dropped = len(vf.validations) - len(kept)
+    vf.validations = [
+        v
+        if v.depends_on is None or v.depends_on in kept_names
+        else v.model_copy(update={"depends_on": None})
+        for v in kept
+    ]
+    return dropped
+
+
+def _drop_passed_validations(
+    project: Project, statuses: dict[tuple[str, str], str]
+) -> int:
+    """Drop validations whose last recorded run passed, in place.
+
+    *statuses* maps (feature, validation name) to the last status, with
+    project assertions under the ``project`` target. Validations that never
+    ran are kept. Returns the number dropped.
+    """
+    return sum(
+        _keep_validations(
+            vf,
+            lambda v, target=target: statuses.get((target, v.name)) != "pass",
+        )
+        for target, vf in _validation_suites(project)
+    )
+
+
+def _validation_suites(project: Project) -> list[tuple[str, ValidationFile]]:
+    """Every validation file with the target its results are recorded under."""
+    suites = [
+        (feature, vf)
+        for feature, node in project.features.items()
+        for vf in node.validations
+    ]
+    suites.extend(("project", vf) for vf in project.assertions)
+    return suites
+
+
+def _validation_report_json(project: Project, results: list) -> str:
+    """Render validation results as a JSON array, one object per validation.
+
+    ``type`` is the validation's type in the project, or null when the
+    result does not match a declared validation.
+    """
+    import json
+
+    types = {
+        (target_name, v.name): v.type.value
+        for target_name, vf in _validation_suites(project)
+        for v in vf.validations
+    }
+    records = [
+        {
+            "target": suite_result.target,
+            "name": vr.name,
+            "type": types.get((suite_result.target, vr.name)),
+            "status": vr.status,
+            "passed": vr.status == "pass",
+            "message": vr.reason,
+        }
+        for suite_result in results
+        for vr in suite_result.results
+    ]
+    return json.dumps(records, indent=2) + "\n"
+
+
+def _commit_intent(cwd: Path, paths: list[str], summary: str) -> None:
+    """Commit intent files written by a scaffolding command as ``intent: <summary>``.
+
+    Does nothing outside a git repository.
+    """
+    from intentc.build.state import GitVersionControl
+
+    try:
+        commit_id = GitVersionControl(repo_dir=cwd).commit_paths(paths, f"intent: {summary}")
+    except subprocess.CalledProcessError as exc:
+        print_error(f"Could not commit intent files: {(exc.stderr or '').strip() or exc}")
+        raise typer.Exit(code=1)
+    if commit_id:
+        console.print(f"[dim]Committed intent files as {commit_id[:8]}.[/dim]")
+
+
+def _find_generation(results: list, prefix: str, target: str):
+    """Return the build result whose generation ID starts with *prefix*.
+
+    Exits 2 when no generation, or more than one, matches.
+    """
+    matches = {
+        r.generation_id: r
+        for r in results
+        if r.generation_id and r.generation_id.startswith(prefix)
+    }
+    if len(matches) != 1:
+        reason = "is ambiguous" if matches else "was not found"
+        print_error(f"Generation '{prefix}' {reason} for target '{target}'.")
+        raise typer.Exit(code=2)
+    return next(iter(matches.values()))
+
+
 def _resolve_output_dir(output_dir: str | None, config: Config) -> str:
     """Resolve the output directory from flag or config default."""
-    return output_dir if output_dir else config.default_output_dir
+    resolved = output_dir if output_dir else config.default_output_dir
+    print_debug(f"output dir: {resolved}")
+    return resolved
+
+
+def _named_output_dir(output_dir: str | None, build_name: str | None, config: Config) -> str:
+    """Resolve the output directory from --output-dir or --build-name.
+
+    ``--build-name NAME`` stands for the ``build-NAME`` directory; giving
+    both flags is a usage error.
+    """
+    if build_name and output_dir:
+        print_error("Specify either --output-dir or --build-name, not both.")
+        raise typer.Exit(code=2)
+    if build_name:
+        return f"build-{_build_slug(build_name)}"
+    return _resolve_output_dir(output_dir, config)
 
 
 def _resolve_profile(profile_name: str | None, config: Config):
@@ -71,13 +424,52 @@ def _resolve_profile(profile_name: str | None, config: Config):
     from intentc.build.agents import AgentProfile
 
     if profile_name:
-        return AgentProfile(
+        profile = AgentProfile(
             name=profile_name,
             provider=config.default_profile.provider,
             timeout=config.default_profile.timeout,
             retries=config.default_profile.retries,
+            retry_delay=config.default_profile.retry_delay,
+            backoff_multiplier=config.default_profile.backoff_multiplier,
+            max_backoff=config.default_profile.max_backoff,
+            prompt_templates=config.default_profile.prompt_templates,
         )
-    return config.default_profile
+    else:
+        profile = config.default_profile
+    print_debug(
+        f"agent profile: {profile.name} (provider={profile.provider}, "
+        f"timeout={profile.timeout}, retries={profile.retries})"
+    )
+    return profile
+
+
+# ---------------------------------------------------------------------------
+# Global options
+# ---------------------------------------------------------------------------
+
+
+@app.callback()
+def main(
+    ctx: typer.Context,
+    quiet: bool = typer.Option(False, "--quiet", "-q", help="Only print errors and command results"),
+    verbose: bool = typer.Option(False, "--verbose", "-v", help="Print debug output"),
+    timeout: Optional[float] = typer.Option(None, "--timeout", min=0, help="Stop agent work after this many seconds; the running target is marked failed"),
+    no_color: bool = typer.Option(False, "--no-color", help="Disable colored output (also off when NO_COLOR is set or stdout is not a terminal)"),
+) -> None:
+    """A compiler of intent — transforms specs into working code using AI agents."""
+    global _deadline
+    _deadline = time.monotonic() + timeout if timeout is not None else None
+    set_color(color_enabled(not no_color))
+    ctx.call_on_close(reset_color)
+    if quiet and verbose:
+        print_error("--quiet and --verbose cannot be used together.")
+        raise typer.Exit(code=2)
+    if quiet:
+        set_verbosity(Verbosity.QUIET)
+    elif verbose:
+        set_verbosity(Verbosity.VERBOSE)
+    else:
+        set_verbosity(Verbosity.NORMAL)
 
 
 # ---------------------------------------------------------------------------
@@ -90,15 +482,23 @@ def init(
     name: Optional[str] = typer.Argument(None, help="Project name (default: current directory name)"),
     no_interactive: bool = typer.Option(False, "--no-interactive", help="Skip agent dialog and generate minimal skeleton"),
     prompt: Optional[str] = typer.Option(None, "-P", "--prompt", help="Project description for single-shot init"),
+    commit: bool = typer.Option(False, "--commit", help="Commit the new intent files (default: git.auto_commit_intent)"),
+    intent_dir_name: Optional[str] = typer.Option(None, "--intent-dir", help="Directory for the intent files (default: project.intent_dir, 'intent')"),
 ) -> None:
     """Create a new intentc project in the current directory."""
     from intentc.build.agents import AgentProfile, create_from_profile
 
     cwd = Path.cwd()
-    intent_dir = cwd / "intent"
+    # Keep settings from a config that was put in place before init
+    config = _load_config(cwd)
+    if intent_dir_name:
+        config.project.intent_dir = intent_dir_name
+    intent_dir = cwd / config.project.intent_dir
 
     if (intent_dir / "project.ic").exists():
-        print_error("Project already exists (intent/project.ic found). Aborting.")
+        print_error(
+            f"Project already exists ({config.project.intent_dir}/project.ic found). Aborting."
+        )
         raise typer.Exit(code=2)
 
     project_name = name or cwd.name
@@ -119,10 +519,9 @@ def init(
             load_project(intent_dir)
         except ParseErrors as exc:
             for err in exc.errors:
-                print_error(str(err))
+                print_error(escape(str(err)))
             raise typer.Exit(code=1)
 
-    config = Config()
     config_path = save_config(config, cwd)
 
     # Collect created files for summary
@@ -134,140 +533,776 @@ def init(
 
     render_init_summary(created_files)
 
+    if commit or config.git.auto_commit_intent:
+        rel_intent_dir = intent_dir.relative_to(cwd)
+        intent_files = [f for f in created_files if Path(f).is_relative_to(rel_intent_dir)]
+        _commit_intent(cwd, intent_files, f"initialize project {project_name}")
+
 
 @app.command()
 def build(
-    target: Optional[str] = typer.Argument(None, help="Feature path to build (omit for all)"),
+    target: Optional[str] = typer.Argument(None, help="Feature path or feature:subtarget to build (omit for all)"),
     force: bool = typer.Option(False, "--force", "-f", help="Rebuild even if already built"),
+    force_agent: bool = typer.Option(False, "--force-agent", help="Call the agent even if the intent and its dependencies are unchanged since the last build (implies --force)"),
+    ignore_disk_space: bool = typer.Option(False, "--ignore-disk-space", help="Build even if free disk space is below build.min_free_mb"),
     dry_run: bool = typer.Option(False, "--dry-run", "-n", help="Print the build plan without executing"),
+    print_plan: bool = typer.Option(False, "--print-plan", help="Print the build plan as JSON and exit (implies --dry-run)"),
+    preview: bool = typer.Option(False, "--preview", help="Run the agents without touching the output and show the changes they would make (implies --dry-run)"),
     output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
     profile: Optional[str] = typer.Option(None, "--profile", "-p", help="Agent profile name override"),
     implementation: Optional[str] = typer.Option(None, "--implementation", "-i", help="Implementation name"),
+    events_file: Optional[Path] = typer.Option(None, "--events-file", help="Append a JSONL event stream to this file"),
+    resume: bool = typer.Option(False, "--resume", help="Continue an interrupted build where it stopped"),
+    retry_failed: bool = typer.Option(False, "--retry-failed", help="Only re-attempt targets whose last build failed"),
+    no_cascade: bool = typer.Option(False, "--no-cascade", help="Leave dependents of rebuilt targets marked built"),
+    tag: Optional[str] = typer.Option(None, "--tag", help="Build every feature with this tag, plus its dependencies"),
+    validate_after: Optional[bool] = typer.Option(None, "--validate/--no-validate", help="Run each target's validations after it builds (default: build.validate_after_build)"),
+    parallel_validations: Optional[int] = typer.Option(None, "--parallel-validations", min=1, help="Run at most this many validations at once (default: validations.parallelism)"),
+    parallel: Optional[int] = typer.Option(None, "--parallel", "-j", min=1, help="Build up to this many independent targets at once (default: build.parallelism)"),
+    fresh: bool = typer.Option(False, "--fresh", help="Build from scratch into a new build-<name>-<timestamp> directory"),
+    discard: bool = typer.Option(False, "--discard", help="With --fresh, remove the directory after building (its file list stays in the build state)"),
+    select_deps: str = typer.Option("transitive", "--select-deps", help="Dependencies built with a target or tag: transitive, direct or none"),
+    target_status: bool = typer.Option(False, "--target-status", help="Exit 0 if every target was built, 3 if some were already up to date, 4 if any failed"),
+    stats: bool = typer.Option(False, "--stats", help="Print target counts, durations and agent attempts after the build"),
+    stats_file: Optional[Path] = typer.Option(None, "--stats-file", help="Write the build stats to this file as JSON"),
+    report_file: Optional[Path] = typer.Option(None, "--report-file", help="Write a JSON report of every target's status, files and duration to this file"),
 ) -> None:
-    """Build features using the configured agent."""
-    from intentc.build.builder import Builder, BuildOptions
+    """Build features using the configured agent.
+
+    With --target-status the exit code summarizes the outcome for CI: 0
+    when every selected target was built, 3 when some (or all) were
+    already up to date, 4 when any target failed. These stay clear of 1
+    (error) and 2 (usage error).
+    """
+    from intentc.build.builder import Builder, BuildOptions, free_disk_mb
+    from intentc.build.events import EventLog, default_events_path
     from intentc.build.state import GitVersionControl, StateManager
 
-    cwd = Path.cwd()
-    project = _load_project_or_exit(cwd / "intent")
-    config = load_config(cwd)
+    if target and tag:
+        print_error("Specify either a target or --tag, not both.")
+        raise typer.Exit(code=2)
+    if fresh and output_dir:
+        print_error("--fresh picks its own output directory; do not combine it with --output-dir.")
+        raise typer.Exit(code=2)
+    if discard and not fresh:
+        print_error("--discard requires --fresh.")
+        raise typer.Exit(code=2)
+    if select_deps not in ("transitive", "direct", "none"):
+        print_error(f"Unknown --select-deps '{select_deps}'. Use 'transitive', 'direct' or 'none'.")
+        raise typer.Exit(code=2)
 
-    resolved_output = _resolve_output_dir(output_dir, config)
+    # Relative to where the user ran the command, not the project root
+    events_file = events_file.resolve() if events_file else None
+    stats_file = stats_file.resolve() if stats_file else None
+    report_file = report_file.resolve() if report_file else None
+    root = _project_root()
+    config = _load_config(root, templates=True)
+    project = _load_project_or_exit(_intent_dir(root, config))
+
+    if fresh:
+        resolved_output = _fresh_output_dir(
+            root, target or tag or project.project_intent.name
+        )
+        console.print(f"Building into fresh directory {resolved_output}")
+    else:
+        resolved_output = _resolve_output_dir(output_dir, config)
     resolved_profile = _resolve_profile(profile, config)
     log = _make_log_callback()
+    print_debug(f"free disk space: {free_disk_mb(resolved_output):.0f} MB")
 
-    state_manager = StateManager(base_dir=cwd, output_dir=resolved_output)
-    vc = GitVersionControl(repo_dir=cwd)
+    events: EventLog | None = None
+    if events_file is None and config.build.events:
+        events_file = default_events_path(root)
+    if events_file is not None:
+        events = EventLog(events_file)
+        print_debug(f"events file: {events_file}")
+
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+    vc = GitVersionControl(repo_dir=root)
     builder = Builder(
         project=project,
         state_manager=state_manager,
         version_control=vc,
         agent_profile=resolved_profile,
         log=log,
+        events=events,
+        validation_parallelism=parallel_validations or config.validations.parallelism,
+        deadline=_deadline,
+        rate_limits=config.agents.rate_limits,
+        max_concurrency=config.agents.max_concurrency,
+        agent_profiles=config.agents.profiles,
     )
 
     opts = BuildOptions(
         target=target or "",
-        force=force,
-        dry_run=dry_run,
+        tag=tag or "",
+        force=force or force_agent,
+        force_agent=force_agent,
+        dry_run=dry_run or print_plan or preview,
+        preview=preview,
         output_dir=resolved_output,
         profile_override=profile or "",
         implementation=implementation or "",
+        min_free_mb=config.build.min_free_mb,
+        ignore_disk_space=ignore_disk_space,
+        resume=resume,
+        retry_failed=retry_failed,
+        cascade=not no_cascade,
+        select_deps=select_deps,
+        run_validations=(
+            config.build.validate_after_build if validate_after is None else validate_after
+        ),
+        worktree=config.build.worktree,
+        parallelism=parallel or config.build.parallelism,
+        report_path=str(report_file) if report_file else "",
     )
 
-    results, error = builder.build(opts)
-    render_build_results(results)
+    if print_plan:
+        import json
+
+        try:
+            plan = builder.build_plan(opts)
+        except (KeyError, DependencyCycleError, UnbuiltDependencyError) as exc:
+            _exit_with_error(exc)
+        sys.stdout.write(json.dumps([p.model_dump() for p in plan], indent=2) + "\n")
+        return
+
+    try:
+        results, error = builder.build(opts)
+    except KeyError as exc:
+        _exit_with_error(exc)
+    except KeyboardInterrupt:
+        print_error("Build interrupted. Run the same build command again to continue.")
+        raise typer.Exit(code=130)
+    finally:
+        if events is not None:
+            events.close()
+        if discard:
+            shutil.rmtree(root / resolved_output, ignore_errors=True)
+            vc.commit_removal(resolved_output, f"intentc: discard {resolved_output}")
+        if fresh:
+            _prune_empty_dirs(root / resolved_output)
+            _prune_empty_dirs(root / ".intentc" / "state" / resolved_output)
+    if preview:
+        render_file_changes(results)
+    else:
+        render_build_results(results)
+    if discard:
+        console.print(
+            f"[dim]Discarded {resolved_output}; its file list is kept in the build state.[/dim]"
+        )
+    if (stats or stats_file) and not opts.dry_run:
+        build_stats = builder.stats(results)
+        if stats:
+            render_build_stats(build_stats)
+        if stats_file:
+            stats_file.parent.mkdir(parents=True, exist_ok=True)
+            stats_file.write_text(build_stats.model_dump_json(indent=2) + "\n", encoding="utf-8")
 
     if error:
-        raise typer.Exit(code=1)
+        try:
+            _exit_with_error(error)
+        except typer.Exit:
+            if target_status:
+                raise typer.Exit(code=4)
+            raise
+    if target_status and not opts.dry_run and (builder.skipped or not results):
+        console.print(f"{len(builder.skipped)} target(s) already up to date.")
+        raise typer.Exit(code=3)
+
+
+@app.command()
+def watch(
+    output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
+    build_name: Optional[str] = typer.Option(None, "--build-name", help="Build into the build-<name> directory"),
+    profile: Optional[str] = typer.Option(None, "--profile", "-p", help="Agent profile name override"),
+    implementation: Optional[str] = typer.Option(None, "--implementation", "-i", help="Implementation name"),
+    debounce: float = typer.Option(0.5, "--debounce", min=0.0, help="Seconds to wait for edits to settle before rebuilding"),
+) -> None:
+    """Rebuild features whenever their intent or validation files change.
+
+    Each burst of edits rebuilds the features whose files changed and every
+    feature that depends on them. Press Ctrl-C to stop; a build in progress
+    is interrupted and can be continued with ``intentc build --resume``.
+    """
+    from intentc.build.builder import Builder, BuildOptions
+    from intentc.build.state import GitVersionControl, StateManager, TargetStatus
+    from intentc.build.watch import IntentWatcher, affected_features
+
+    root = _project_root()
+    config = _load_config(root, templates=True)
+    intent_dir = _intent_dir(root, config)
+    _load_project_or_exit(intent_dir)
+    resolved_output = _named_output_dir(output_dir, build_name, config)
+    resolved_profile = _resolve_profile(profile, config)
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+    vc = GitVersionControl(repo_dir=root)
+    watcher = IntentWatcher(intent_dir, debounce=debounce)
+
+    console.print(f"Watching {intent_dir} (building into {resolved_output}). Press Ctrl-C to stop.")
+    building = False
+    try:
+        while True:
+            changed = watcher.wait()
+            names = ", ".join(str(p.relative_to(intent_dir)) for p in changed)
+            console.print(f"\n[bold]Changed:[/bold] {escape(names)}")
+            try:
+                project = load_project(intent_dir)
+            except ParseErrors as exc:
+                for err in exc.errors:
+                    print_error(escape(str(err)))
+                console.print("[dim]Fix the errors above; watching for the next change.[/dim]")
+                continue
+            try:
+                affected = affected_features(project, intent_dir, changed)
+            except DependencyCycleError as exc:
+                print_error(escape(str(exc)))
+                continue
+            if not affected:
+                console.print("[dim]No features affected.[/dim]")
+                continue
+
+            for feature in affected:
+                if state_manager.get_status(feature) == TargetStatus.BUILT:
+                    state_manager.set_status(feature, TargetStatus.OUTDATED)
+            # Building the features nothing else affected depends on brings
+            # the rest along as their dependencies
+            leaves = [
+                f for f in affected
+                if not any(c in affected for c in project.children(f))
+            ]
+            builder = Builder(
+                project=project,
+                state_manager=state_manager,
+                version_control=vc,
+                agent_profile=resolved_profile,
+                log=_make_log_callback(),
+                validation_parallelism=config.validations.parallelism,
+                rate_limits=config.agents.rate_limits,
+                max_concurrency=config.agents.max_concurrency,
+                agent_profiles=config.agents.profiles,
+            )
+            console.print(f"Rebuilding {len(affected)} feature(s): {escape(', '.join(affected))}")
+            started = time.monotonic()
+            results: list = []
+            errors: list[Exception] = []
+            building = True
+            for leaf in leaves:
+                leaf_results, error = builder.build(BuildOptions(
+                    target=leaf,
+                    output_dir=resolved_output,
+                    profile_override=profile or "",
+                    implementation=implementation or "",
+                    min_free_mb=config.build.min_free_mb,
+                    run_validations=config.build.validate_after_build,
+                    parallelism=config.build.parallelism,
+                ))
+                results.extend(leaf_results)
+                if error is not None:
+                    errors.append(error)
+            building = False
+
+            built = sum(1 for r in results if r.status == "built")
+            failed = sum(1 for r in results if r.status == "failed")
+            console.print(
+                f"{built} built, {failed} failed in {time.monotonic() - started:.1f}s"
+            )
+            for error in errors:
+                print_error(escape(str(error)))
+    except KeyboardInterrupt:
+        if building:
+            print_error("Build interrupted. Run 'intentc build --resume' to continue it.")
+            raise typer.Exit(code=130)
+        console.print("\nStopped watching.")
 
 
 @app.command()
 def validate(
     target: Optional[str] = typer.Argument(None, help="Feature to validate (omit for all)"),
+    patterns: Optional[list[str]] = typer.Option(None, "--target", "-t", help="Target name or wildcard pattern (repeatable)"),
     output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
     profile: Optional[str] = typer.Option(None, "--profile", "-p", help="Agent profile override"),
     implementation: Optional[str] = typer.Option(None, "--implementation", "-i", help="Implementation name"),
+    output: str = typer.Option("text", "--output", help="Result format: text, junit or json"),
+    as_json: bool = typer.Option(False, "--json", help="Same as --output json"),
+    report_file: Optional[Path] = typer.Option(None, "--report-file", help="Write the report to this file instead of stdout"),
+    skip: Optional[list[str]] = typer.Option(None, "--skip", help="Do not run the validation with this name (repeatable)"),
+    only: Optional[list[str]] = typer.Option(None, "--only", help="Run only the validation with this name (repeatable)"),
+    parallel_validations: Optional[int] = typer.Option(None, "--parallel-validations", min=1, help="Run at most this many validations at once (default: validations.parallelism)"),
+    failed: bool = typer.Option(False, "--failed", help="Run only validations that did not pass last time"),
+    baseline: bool = typer.Option(False, "--baseline", help="Fail only on regressions against .intentc/validation-baseline.json"),
+    update_baseline: bool = typer.Option(False, "--update-baseline", help="Record this run's results as the new baseline"),
+    agent_budget: Optional[int] = typer.Option(None, "--agent-budget", min=0, help="Run at most this many agent-backed validations; the rest are skipped"),
 ) -> None:
     """Run validations independently of the build pipeline."""
     from intentc.build.builder import Builder
-    from intentc.build.state import GitVersionControl, StateManager
-    from intentc.build.validations import ValidationSuiteResult
+    from intentc.build.state import (
+        GitVersionControl,
+        StateManager,
+        ValidationBaseline,
+        default_baseline_path,
+    )
+    from intentc.build.validations import AgentBudget, ValidationSuiteResult
+
+    if as_json:
+        if output not in ("text", "json"):
+            print_error(f"--json cannot be combined with --output {output}.")
+            raise typer.Exit(code=2)
+        output = "json"
+    if output not in ("text", "junit", "json"):
+        print_error(f"Unknown output format '{output}'. Use 'text', 'junit' or 'json'.")
+        raise typer.Exit(code=2)
+    if report_file is not None and output == "text":
+        print_error("--report-file requires --output junit or json.")
+        raise typer.Exit(code=2)
 
-    cwd = Path.cwd()
-    project = _load_project_or_exit(cwd / "intent")
-    config = load_config(cwd)
+    # Relative to where the user ran the command, not the project root
+    report_file = report_file.resolve() if report_file else None
+    root = _project_root()
+    config = _load_config(root, templates=True)
+    project = _load_project_or_exit(_intent_dir(root, config))
+
+    snapshot = ValidationBaseline(default_baseline_path(root))
+    if baseline and not snapshot.exists():
+        print_error("No validation baseline yet. Record one with --update-baseline.")
+        raise typer.Exit(code=2)
 
     if implementation:
         project.resolve_implementation(implementation)
 
+    skipped = _filter_validations(project, skip or [], only or []) if skip or only else 0
+
     resolved_output = _resolve_output_dir(output_dir, config)
     resolved_profile = _resolve_profile(profile, config)
-    log = _make_log_callback()
-
-    state_manager = StateManager(base_dir=cwd, output_dir=resolved_output)
-    vc = GitVersionControl(repo_dir=cwd)
+    # Machine-readable output on stdout must not be interleaved with progress logs
+    report_to_stdout = output != "text" and report_file is None
+    log = (lambda _msg: None) if report_to_stdout else _make_log_callback()
+
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+    previously_passed = (
+        _drop_passed_validations(
+            project, state_manager.backend.latest_validation_statuses()
+        )
+        if failed
+        else 0
+    )
+    vc = GitVersionControl(repo_dir=root)
     builder = Builder(
         project=project,
         state_manager=state_manager,
         version_control=vc,
         agent_profile=resolved_profile,
         log=log,
+        validation_parallelism=parallel_validations or config.validations.parallelism,
+        deadline=_deadline,
+        rate_limits=config.agents.rate_limits,
+        max_concurrency=config.agents.max_concurrency,
     )
 
-    result = builder.validate(target, resolved_output)
+    budget = AgentBudget(agent_budget) if agent_budget is not None else None
+    requested = ([target] if target else []) + list(patterns or [])
+    try:
+        if requested:
+            # Validations belong to features, so sub-targets share their feature's suite
+            features: list[str] = []
+            for name in builder.resolve_targets(requested):
+                feature, _ = project.split_target(name)
+                if feature not in features:
+                    features.append(feature)
+            outcome = builder.validate(features, resolved_output, agent_budget=budget)
+        else:
+            outcome = builder.validate(None, resolved_output, agent_budget=budget)
+    except KeyError as exc:
+        _exit_with_error(exc)
 
     # Normalize to list
-    if isinstance(result, ValidationSuiteResult):
-        results = [result]
+    if isinstance(outcome, ValidationSuiteResult):
+        outcome = [outcome]
+    results: list[ValidationSuiteResult] = []
+    for item in outcome:
+        results.extend(item if isinstance(item, list) else [item])
+
+    if output != "text":
+        if output == "junit":
+            report = render_junit_report(results)
+        else:
+            report = _validation_report_json(project, results)
+        if report_file is None:
+            sys.stdout.write(report)
+        else:
+            report_file.parent.mkdir(parents=True, exist_ok=True)
+            report_file.write_text(report, encoding="utf-8")
+            render_validation_results(results)
+            kind = "JUnit" if output == "junit" else "JSON"
+            console.print(f"{kind} report written to {report_file}")
     else:
-        results = result
+        render_validation_results(results)
+        if requested and len(results) > 1:
+            render_validation_summary(results)
+
+    if skipped and not report_to_stdout:
+        console.print(f"[dim]{skipped} validation(s) skipped by --skip/--only.[/dim]")
+    if failed and not report_to_stdout:
+        console.print(
+            f"[dim]{previously_passed} previously-passing validation(s) skipped.[/dim]"
+        )
 
-    render_validation_results(results)
+    statuses = {r.target: {v.name: v.status for v in r.results} for r in results}
+    if baseline:
+        regressions, others = snapshot.compare(statuses)
+        if not report_to_stdout:
+            for target_name, name in regressions:
+                console.print(f"[red]Regression:[/red] {target_name}: {name} passed in the baseline")
+            for target_name, name in others:
+                console.print(f"[yellow]Warning:[/yellow] {target_name}: {name} fails (not passing in the baseline)")
+    if update_baseline:
+        snapshot.update(statuses)
+        if not report_to_stdout:
+            console.print(f"Validation baseline written to {snapshot.path}")
+
+    if baseline:
+        # Only regressions of error-severity validations fail the run
+        severities = {
+            (target_name, v.name): v.severity
+            for target_name, vf in _validation_suites(project)
+            for v in vf.validations
+        }
+        if any(severities.get(key) != Severity.WARNING for key in regressions):
+            raise typer.Exit(code=1)
+        return
 
-    # Exit 1 if any error-severity validation failed
+    # Exit 1 if any error-severity validation failed; JSON output is read by
+    # CI, so there a failed warning counts too
     for suite_result in results:
         if not suite_result.passed:
             raise typer.Exit(code=1)
+    if output == "json" and any(
+        vr.status not in ("pass", "skipped") for r in results for vr in r.results
+    ):
+        raise typer.Exit(code=1)
+
+
+@app.command()
+def lint() -> None:
+    """Flag concrete technology names in intents (they belong in implementations)."""
+    from intentc.core.lint import DEFAULT_TECH_TERMS, lint_project
+    from intentc.core.models import Severity
+
+    root = _project_root()
+    config = _load_config(root)
+    project = _load_project_or_exit(_intent_dir(root, config))
+
+    terms = config.lint.terms if config.lint.terms is not None else DEFAULT_TECH_TERMS
+    terms = [*terms, *config.lint.extra_terms]
+    print_debug(f"lint terms: {len(terms)}, severity: {config.lint.severity.value}")
+
+    findings = lint_project(project, terms=terms, severity=config.lint.severity)
+    for finding in findings:
+        console.print(str(finding), markup=False, highlight=False)
+
+    if not findings:
+        console.print("[green]No technology mentions found.[/green]")
+        return
+    console.print(f"{len(findings)} finding(s)")
+    if any(f.severity == Severity.ERROR for f in findings):
+        raise typer.Exit(code=1)
+
+
+@app.command()
+def check() -> None:
+    """Parse every intent and validation file and report problems, without building."""
+    from intentc.core.project import find_dangling_validations
+
+    root = _project_root()
+    project = _load_project_or_exit(_intent_dir(root))
+    try:
+        project.topological_order()
+    except DependencyCycleError as exc:
+        _exit_with_error(exc)
+
+    dangling = find_dangling_validations(project)
+    if dangling:
+        for problem in dangling:
+            print_error(escape(str(problem)))
+        raise typer.Exit(code=2)
+
+    validation_files = sum(len(node.validations) for node in project.features.values())
+    console.print(
+        f"[green]OK:[/green] {len(project.features)} feature(s), "
+        f"{validation_files} validation file(s)"
+    )
+
+
+def _print_clean_plan(
+    output_dirs: list[str],
+    targets: list[str],
+    files: list[str],
+    build_dirs: list[str],
+    as_json: bool,
+) -> None:
+    """Print what a clean would do, for --dry-run."""
+    if as_json:
+        import json
+
+        plan = {
+            "output_directories": output_dirs,
+            "targets": targets,
+            "files": files,
+            "build_directories": build_dirs,
+        }
+        sys.stdout.write(json.dumps(plan, indent=2) + "\n")
+        return
+    console.print(
+        f"Dry run: would reset {len(targets)} target(s) in {', '.join(output_dirs) or '(none)'}, "
+        f"remove {len(files)} file(s) and {len(build_dirs)} build director(ies)."
+    )
+    for label, items in (("target", targets), ("file", files), ("build dir", build_dirs)):
+        for item in items:
+            console.print(f"  {label}: {item}", markup=False, highlight=False)
+
+
+def _clean_all_builds(force: bool, dry_run: bool = False, as_json: bool = False) -> None:
+    """Reset the state of every output directory and remove build worktrees."""
+    from intentc.build.state import (
+        GitVersionControl,
+        StateManager,
+        recorded_output_dirs,
+    )
+
+    root = _project_root()
+    output_dirs = recorded_output_dirs(root)
+    worktrees_dir = root / ".intentc" / "worktrees"
+    worktrees = (
+        sorted(p for p in worktrees_dir.iterdir() if p.is_dir())
+        if worktrees_dir.is_dir()
+        else []
+    )
+    if dry_run:
+        _print_clean_plan(
+            output_dirs, [], [], [str(p.relative_to(root)) for p in worktrees], as_json
+        )
+        return
+    _confirm_destructive(
+        f"This will reset the build state of {len(output_dirs)} output "
+        f"director(ies) and remove {len(worktrees)} worktree(s):",
+        output_dirs + [str(p.relative_to(root)) for p in worktrees],
+        force,
+    )
+
+    for name in output_dirs:
+        StateManager(base_dir=root, output_dir=name).reset_all()
+    vc = GitVersionControl(repo_dir=root)
+    for path in worktrees:
+        try:
+            vc.remove_worktree(path)
+        except subprocess.CalledProcessError as exc:
+            print_error(f"Could not remove worktree '{path}': {exc.stderr.strip()}")
+            raise typer.Exit(code=1)
+    console.print(
+        f"[green]Reset {len(output_dirs)} output director(ies) and "
+        f"removed {len(worktrees)} worktree(s).[/green]"
+    )
+
+
+@app.command()
+def graph(
+    fmt: str = typer.Option("text", "--format", help="Output format: text, dot or mermaid"),
+    affected: Optional[str] = typer.Option(None, "--affected", help="Highlight (text: list only) the features affected by changing this target"),
+    show_status: bool = typer.Option(False, "--status", help="Color dot and mermaid nodes by build status (built, pending, failed, outdated)"),
+    output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="With --status, the output directory whose build state to show"),
+    build_name: Optional[str] = typer.Option(None, "--build-name", help="With --status, show the build-<name> directory's state"),
+    output_file: Optional[Path] = typer.Option(None, "--output-file", help="Write the dot or mermaid graph to this file instead of stdout"),
+) -> None:
+    """Show the feature dependency graph, or what a change to one target affects."""
+    if fmt not in ("text", "dot", "mermaid"):
+        print_error(f"Unknown format '{fmt}'. Use 'text', 'dot' or 'mermaid'.")
+        raise typer.Exit(code=2)
+    if fmt == "text" and (show_status or output_file):
+        print_error("--status and --output-file need --format dot or mermaid.")
+        raise typer.Exit(code=2)
+    if output_file:
+        # Relative to where the user ran the command, not the project root
+        output_file = output_file.resolve()
+
+    root = _project_root()
+    config = _load_config(root)
+    project = _load_project_or_exit(_intent_dir(root, config))
+    try:
+        order = project.topological_order()
+        hit: set[str] | None = None
+        if affected:
+            feature, _ = project.split_target(affected)
+            hit = {feature} | project.descendants(feature)
+    except (KeyError, DependencyCycleError) as exc:
+        _exit_with_error(exc)
+
+    if fmt != "text":
+        statuses: dict[str, str] | None = None
+        if show_status:
+            from intentc.build.state import StateManager
+
+            resolved_output = _named_output_dir(output_dir, build_name, config)
+            if build_name and not (root / ".intentc" / "state" / resolved_output).is_dir():
+                print_error(f"No build state recorded for output directory '{resolved_output}'.")
+                raise typer.Exit(code=2)
+            state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+            statuses = {t: s.value for t, s in state_manager.list_targets()}
+        features = {f: project.features[f].depends_on for f in order}
+        rendered = render_graph(features, fmt, hit, statuses)
+        if output_file:
+            output_file.parent.mkdir(parents=True, exist_ok=True)
+            output_file.write_text(rendered, encoding="utf-8")
+            console.print(f"Wrote {fmt} graph to {output_file}")
+        else:
+            sys.stdout.write(rendered)
+        return
+
+    if hit is not None:
+        # Everything that needs rebuilding or revalidating, in build order
+        for feature in order:
+            if feature in hit:
+                console.print(feature, markup=False, highlight=False)
+        return
+    for feature in order:
+        deps = project.features[feature].depends_on
+        line = f"{feature} <- {', '.join(deps)}" if deps else feature
+        console.print(line, markup=False, highlight=False)
 
 
 @app.command()
 def clean(
-    target: Optional[str] = typer.Argument(None, help="Feature path to clean"),
+    target: Optional[str] = typer.Argument(None, help="Feature path or pattern to clean"),
+    patterns: Optional[list[str]] = typer.Option(None, "--target", "-t", help="Target name or wildcard pattern (repeatable)"),
     all_targets: bool = typer.Option(False, "--all", help="Reset all state"),
+    all_builds: bool = typer.Option(False, "--all-builds", help="Reset state for every output directory and remove build worktrees"),
     output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
+    force: bool = typer.Option(False, "--force", "-f", help="Do not ask for confirmation"),
+    dry_run: bool = typer.Option(False, "--dry-run", "-n", help="Show what would be removed without changing anything"),
+    json_output: bool = typer.Option(False, "--json", help="With --dry-run, print the plan as JSON"),
+    cascade: bool = typer.Option(False, "--cascade", help="Also remove the generated files of every feature that depends on the targets"),
 ) -> None:
-    """Revert a target's generated code and reset its state."""
+    """Revert a target's generated code and reset its state.
+
+    Features that depend on a cleaned target are marked outdated; with
+    --cascade they are cleaned as well.
+    """
     from intentc.build.builder import Builder
     from intentc.build.state import GitVersionControl, StateManager
 
-    if not all_targets and not target:
+    if json_output and not dry_run:
+        print_error("--json requires --dry-run.")
+        raise typer.Exit(code=2)
+    requested = ([target] if target else []) + list(patterns or [])
+    if all_builds:
+        if requested or all_targets:
+            print_error("--all-builds cannot be combined with targets or --all.")
+            raise typer.Exit(code=2)
+        _clean_all_builds(force, dry_run=dry_run, as_json=json_output)
+        return
+    if not all_targets and not requested:
         print_error("Specify a target or use --all to clean everything.")
         raise typer.Exit(code=2)
 
-    cwd = Path.cwd()
-    project = _load_project_or_exit(cwd / "intent")
-    config = load_config(cwd)
+    root = _project_root()
+    config = _load_config(root)
+    project = _load_project_or_exit(_intent_dir(root, config))
 
     resolved_output = _resolve_output_dir(output_dir, config)
     log = _make_log_callback()
 
-    state_manager = StateManager(base_dir=cwd, output_dir=resolved_output)
-    vc = GitVersionControl(repo_dir=cwd)
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+    vc = GitVersionControl(repo_dir=root)
     builder = Builder(
         project=project,
         state_manager=state_manager,
         version_control=vc,
         agent_profile=config.default_profile,
         log=log,
+        deadline=_deadline,
+        rate_limits=config.agents.rate_limits,
+        max_concurrency=config.agents.max_concurrency,
     )
 
     if all_targets:
+        tracked = [name for name, _ in state_manager.list_targets()]
+        if dry_run:
+            _print_clean_plan([resolved_output], sorted(tracked), [], [], json_output)
+            return
+        _confirm_destructive(
+            f"This will reset the build state of {len(tracked)} target(s) in '{resolved_output}':",
+            tracked,
+            force,
+        )
         builder.clean_all(resolved_output)
         console.print("[green]All state reset.[/green]")
     else:
-        builder.clean(target, resolved_output)
-        console.print(f"[green]Cleaned target '{target}'.[/green]")
+        try:
+            targets = builder.resolve_targets(requested)
+        except KeyError as exc:
+            _exit_with_error(exc)
+        if cascade:
+            targets = list(
+                dict.fromkeys(t for name in targets for t in builder.clean_targets(name, cascade=True))
+            )
+
+        files = [
+            path
+            for name in targets
+            for path in state_manager.ownership.files_for(name, resolved_output)
+        ]
+        if dry_run:
+            _print_clean_plan([resolved_output], targets, files, [], json_output)
+            return
+        _confirm_destructive(
+            f"This will remove {len(files)} file(s) and reset {len(targets)} target(s):",
+            files or targets,
+            force,
+        )
+        for name in targets:
+            builder.clean(name, resolved_output)
+        for name in targets:
+            console.print(f"[green]Cleaned target '{name}'.[/green]")
+
+
+@app.command()
+def gc(
+    output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
+    keep_last: Optional[int] = typer.Option(None, "--keep-last", min=0, help="Keep each target's N newest build results (default: retention.keep_last)"),
+    keep_days: Optional[float] = typer.Option(None, "--keep-days", min=0, help="Keep build results from the last N days (default: retention.keep_days)"),
+    dry_run: bool = typer.Option(False, "--dry-run", "-n", help="Show what would be pruned without changing anything"),
+) -> None:
+    """Prune old build results from the state; each target keeps its latest."""
+    from intentc.build.state import StateManager
+
+    root = _project_root()
+    config = _load_config(root)
+    keep_last = config.retention.keep_last if keep_last is None else keep_last
+    keep_days = config.retention.keep_days if keep_days is None else keep_days
+    if not keep_last and not keep_days:
+        print_error(
+            "No retention policy. Set retention.keep_last or retention.keep_days "
+            "in the config, or pass --keep-last or --keep-days."
+        )
+        raise typer.Exit(code=2)
+
+    resolved_output = _resolve_output_dir(output_dir, config)
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+    pruned = state_manager.backend.prune_build_results(
+        keep_last=keep_last, keep_days=keep_days, dry_run=dry_run
+    )
+
+    targets = {r.target for r in pruned}
+    verb = "Would prune" if dry_run else "Pruned"
+    console.print(
+        f"{verb} {len(pruned)} build result(s) from {len(targets)} target(s) in '{resolved_output}'."
+    )
+    if dry_run:
+        for r in pruned:
+            console.print(
+                f"  {r.target}: {r.generation_id or '-'} ({r.timestamp})",
+                markup=False,
+                highlight=False,
+            )
 
 
 @app.command()
@@ -279,11 +1314,16 @@ def plan(
     implementation: Optional[str] = typer.Option(None, "--implementation", "-i", help="Implementation name"),
 ) -> None:
     """Enter interactive planning mode with the agent for a specific feature."""
-    from intentc.build.agents import AgentProfile, BuildContext, create_from_profile
+    from intentc.build.agents import (
+        AgentProfile,
+        BuildContext,
+        UnknownAgentError,
+        create_from_profile,
+    )
 
-    cwd = Path.cwd()
-    project = _load_project_or_exit(cwd / "intent")
-    config = load_config(cwd)
+    root = _project_root()
+    config = _load_config(root, templates=True)
+    project = _load_project_or_exit(_intent_dir(root, config))
 
     resolved_output = _resolve_output_dir(output_dir, config)
     resolved_profile = _resolve_profile(profile, config)
@@ -295,12 +1335,12 @@ def plan(
     if target not in project.features:
         from intentc.core.project import FeatureNode
 
-        intent_dir = cwd / "intent"
+        intent_dir = _intent_dir(root, config)
         feature_name = target.rsplit("/", 1)[-1]
         intent = IntentFile(name=feature_name)
         ic_path = intent_dir / target / f"{feature_name}.ic"
         write_intent_file(intent, ic_path)
-        console.print(f"[green]Created new feature:[/green] {ic_path.relative_to(cwd)}")
+        console.print(f"[green]Created new feature:[/green] {ic_path.relative_to(root)}")
 
         node = FeatureNode(path=target, intents=[intent], validations=[])
         project.features[target] = node
@@ -324,7 +1364,10 @@ def plan(
         seed_prompt=prompt,
     )
 
-    agent = create_from_profile(resolved_profile)
+    try:
+        agent = create_from_profile(resolved_profile)
+    except UnknownAgentError as exc:
+        _exit_with_error(exc)
     agent.plan(ctx)
 
 
@@ -332,74 +1375,318 @@ def plan(
 def status(
     output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
     outdated: bool = typer.Option(False, "--outdated", help="Check for outdated targets"),
+    tag: Optional[str] = typer.Option(None, "--tag", help="Only show features with this tag"),
+    watch: bool = typer.Option(False, "--watch", "-w", help="Keep refreshing until interrupted"),
+    interval: float = typer.Option(2.0, "--interval", min=0.1, help="Seconds between refreshes with --watch"),
+    all_builds: bool = typer.Option(False, "--all-builds", help="Show every output directory with build state side by side"),
+    as_json: bool = typer.Option(False, "--json", help="Print {output_dir: {target: status}} as JSON"),
+    order: str = typer.Option("name", "--order", help="List targets by name, or topo for build order (dependencies first)"),
 ) -> None:
     """Show the build state for all tracked targets."""
     from intentc.build.builder import Builder
-    from intentc.build.state import GitVersionControl, StateManager
+    from intentc.build.state import GitVersionControl, StateManager, recorded_output_dirs
 
-    cwd = Path.cwd()
-    project = _load_project_or_exit(cwd / "intent")
-    config = load_config(cwd)
+    if watch and not _is_terminal_output():
+        print_error("--watch needs a terminal; run status without it when piping output.")
+        raise typer.Exit(code=2)
+    if watch and as_json:
+        print_error("--watch cannot be combined with --json.")
+        raise typer.Exit(code=2)
+    if all_builds and (outdated or output_dir):
+        print_error("--all-builds cannot be combined with --outdated or --output-dir.")
+        raise typer.Exit(code=2)
+    if order not in ("name", "topo"):
+        print_error(f"Unknown order '{order}'. Use 'name' or 'topo'.")
+        raise typer.Exit(code=2)
+
+    root = _project_root()
+    config = _load_config(root)
+    project = _load_project_or_exit(_intent_dir(root, config))
     resolved_output = _resolve_output_dir(output_dir, config)
 
-    state_manager = StateManager(base_dir=cwd, output_dir=resolved_output)
-    db_targets = dict(state_manager.list_targets())
+    sort_key: Callable[[str], object] | None = None
+    if order == "topo":
+        try:
+            position = {f: i for i, f in enumerate(project.topological_order())}
+        except DependencyCycleError as exc:
+            _exit_with_error(exc)
+
+        def sort_key(name: str) -> object:
+            # Sub-targets follow their feature; targets no longer in the project go last
+            return (position.get(name.partition(":")[0], len(position)), name)
+
+    if all_builds:
+        output_dirs = sorted(set(recorded_output_dirs(root)) | {resolved_output})
+    else:
+        output_dirs = [resolved_output]
+    state_managers = {d: StateManager(base_dir=root, output_dir=d) for d in output_dirs}
+    state_manager = state_managers[resolved_output]
 
     # Merge project features with build state — features from the project
     # graph that have no build state yet are shown as PENDING.
     from intentc.build.storage.backend import TargetStatus as TS
 
-    all_target_names = set(db_targets.keys()) | set(project.features.keys())
-    targets: list[tuple[str, TS]] = [
-        (name, db_targets.get(name, TS.PENDING))
-        for name in sorted(all_target_names)
-    ]
-
-    # Collect build results for display
-    build_results = {}
-    for target_name, _ in targets:
-        result = state_manager.get_build_result(target_name)
-        if result:
-            build_results[target_name] = result
-
-    outdated_list: list[str] = []
-    if outdated:
-        vc = GitVersionControl(repo_dir=cwd)
-        builder = Builder(
-            project=project,
-            state_manager=state_manager,
-            version_control=vc,
-            agent_profile=config.default_profile,
+    def _statuses(sm: StateManager) -> dict[str, TS]:
+        db_targets = dict(sm.list_targets())
+        all_target_names = set(db_targets.keys()) | set(project.features.keys())
+        if tag is not None:
+            all_target_names = set(project.features_with_tag(tag))
+        return {
+            name: db_targets.get(name, TS.PENDING)
+            for name in sorted(all_target_names, key=sort_key)
+        }
+
+    if as_json:
+        import json
+
+        matrix = {
+            d: {name: st.value for name, st in _statuses(sm).items()}
+            for d, sm in state_managers.items()
+        }
+        sys.stdout.write(json.dumps(matrix, indent=2) + "\n")
+        return
+
+    def _render() -> None:
+        if all_builds:
+            render_status_matrix(
+                output_dirs,
+                {d: _statuses(sm) for d, sm in state_managers.items()},
+                sort_key=sort_key,
+            )
+            return
+
+        targets: list[tuple[str, TS]] = list(_statuses(state_manager).items())
+
+        # Collect build results, and how their validations last went, for display
+        build_results = {}
+        validation_runs = {}
+        for target_name, _ in targets:
+            result = state_manager.get_build_result(target_name)
+            if result:
+                build_results[target_name] = result
+                validation_runs[target_name] = state_manager.validation_results(target_name)
+
+        outdated_list: list[str] = []
+        if outdated:
+            vc = GitVersionControl(repo_dir=root)
+            builder = Builder(
+                project=project,
+                state_manager=state_manager,
+                version_control=vc,
+                agent_profile=config.default_profile,
+            )
+            outdated_list = builder.detect_outdated()
+
+        render_status_table(
+            targets,
+            build_results=build_results,
+            outdated=outdated_list,
+            manual=[f for f, node in project.features.items() if node.manual],
+            validations=validation_runs,
         )
-        outdated_list = builder.detect_outdated()
 
-    render_status_table(targets, build_results=build_results, outdated=outdated_list)
+    if not watch:
+        _render()
+        return
+
+    try:
+        while True:
+            console.clear()
+            _render()
+            console.print(
+                f"[dim]Refreshing every {interval:g}s — press Ctrl+C to stop.[/dim]"
+            )
+            time.sleep(interval)
+    except KeyboardInterrupt:
+        pass
 
 
 @app.command()
 def diff(
     target: str = typer.Argument(..., help="Feature path"),
     output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
+    from_gen: Optional[str] = typer.Option(None, "--from", help="Compare from this generation ID (prefix ok); requires --to"),
+    to_gen: Optional[str] = typer.Option(None, "--to", help="Compare to this generation ID (prefix ok); requires --from"),
 ) -> None:
     """Show the diff of what was generated for a target."""
     from intentc.build.state import GitVersionControl, StateManager
 
-    cwd = Path.cwd()
-    config = load_config(cwd)
+    if (from_gen is None) != (to_gen is None):
+        print_error("--from and --to must be given together.")
+        raise typer.Exit(code=2)
+
+    root = _project_root()
+    config = _load_config(root)
     resolved_output = _resolve_output_dir(output_dir, config)
 
-    state_manager = StateManager(base_dir=cwd, output_dir=resolved_output)
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+
+    if from_gen is not None and to_gen is not None:
+        history = state_manager.get_build_history(target)
+        old = _find_generation(history, from_gen, target)
+        new = _find_generation(history, to_gen, target)
+        for r in (old, new):
+            if not r.commit_id:
+                print_error(f"Generation '{r.generation_id}' has no checkpoint commit.")
+                raise typer.Exit(code=2)
+
+        vc = GitVersionControl(repo_dir=root)
+        # Compare only this target's files that either generation wrote, not
+        # unrelated commits in between or other files in the same checkpoint
+        prefix = f"{Path(resolved_output).as_posix()}/" if resolved_output else ""
+        owned = set(state_manager.ownership.files_for(target, resolved_output))
+        owned.update(prefix + p for r in (old, new) for p in r.file_hashes)
+        changed = set(vc.changed_files(old.commit_id)) | set(vc.changed_files(new.commit_id))
+        paths = sorted(changed & owned)
+        render_generation_diff(
+            target,
+            old.generation_id or "",
+            new.generation_id or "",
+            vc.file_changes(old.commit_id, new.commit_id, paths),
+            vc.diff(old.commit_id, new.commit_id, paths),
+        )
+        return
+
     result = state_manager.get_build_result(target)
 
     if result is None or not result.commit_id:
         print_error(f"No build result found for target '{target}'.")
         raise typer.Exit(code=2)
 
-    vc = GitVersionControl(repo_dir=cwd)
+    vc = GitVersionControl(repo_dir=root)
     diff_text = vc.diff(f"{result.commit_id}~1", result.commit_id)
     render_diff(diff_text)
 
 
+@app.command()
+def history(
+    target: Optional[str] = typer.Argument(None, help="Feature path (optional with --slowest)"),
+    output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
+    show_output: Optional[str] = typer.Option(None, "--show-output", help="Print the raw agent output of this generation ID (prefix ok)"),
+    slowest: Optional[int] = typer.Option(None, "--slowest", min=1, help="Show the N slowest builds (latest per target when no target is given)"),
+) -> None:
+    """Show a target's build history."""
+    from intentc.build.state import StateManager
+
+    if target is None and slowest is None:
+        print_error("Specify a target, or use --slowest N to rank all targets.")
+        raise typer.Exit(code=2)
+
+    root = _project_root()
+    config = _load_config(root)
+    resolved_output = _resolve_output_dir(output_dir, config)
+
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+
+    if slowest is not None:
+        if target is not None:
+            candidates = state_manager.get_build_history(target)
+        else:
+            candidates = [
+                r
+                for name, _ in state_manager.list_targets()
+                if (r := state_manager.get_build_result(name)) is not None
+            ]
+        ranked = sorted(candidates, key=lambda r: r.total_duration_secs, reverse=True)
+        render_slowest_builds(ranked[:slowest])
+        return
+
+    results = state_manager.get_build_history(target)
+
+    if show_output is None:
+        render_history_table(target, results)
+        return
+
+    generation_id = _find_generation(results, show_output, target).generation_id or ""
+    path = state_manager.output_log_path(target, generation_id)
+    if not path.exists():
+        print_error(f"No agent output recorded for generation '{generation_id}'.")
+        raise typer.Exit(code=2)
+    sys.stdout.write(path.read_text(encoding="utf-8"))
+
+
+@app.command()
+def replay(
+    target: str = typer.Argument(..., help="Target whose generation to replay"),
+    generation: str = typer.Argument(..., help="Generation ID (prefix ok)"),
+    output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Override output directory"),
+    force: bool = typer.Option(False, "--force", "-f", help="Replay even when files do not match the hashes recorded at build time"),
+) -> None:
+    """Write a recorded generation's files back without running the agent."""
+    from intentc.build.builder import Builder
+    from intentc.build.state import GitVersionControl, StateManager
+
+    root = _project_root()
+    config = _load_config(root)
+    project = _load_project_or_exit(_intent_dir(root, config))
+    resolved_output = _resolve_output_dir(output_dir, config)
+
+    state_manager = StateManager(base_dir=root, output_dir=resolved_output)
+    result = _find_generation(state_manager.get_build_history(target), generation, target)
+    builder = Builder(
+        project=project,
+        state_manager=state_manager,
+        version_control=GitVersionControl(repo_dir=root),
+        agent_profile=config.default_profile,
+        log=_make_log_callback(),
+    )
+
+    try:
+        mismatched = builder.replay(result, resolved_output, force=force)
+    except ValueError as exc:
+        print_error(escape(str(exc)))
+        raise typer.Exit(code=2)
+    except subprocess.CalledProcessError as exc:
+        print_error(escape(f"Could not read the checkpoint: {(exc.stderr or b'').decode().strip()}"))
+        raise typer.Exit(code=1)
+
+    for path in mismatched:
+        console.print(
+            f"[yellow]Warning:[/yellow] {escape(path)} does not match the hash recorded at build time"
+        )
+    if mismatched and not force:
+        print_error("Nothing was replayed; use --force to write the files anyway.")
+        raise typer.Exit(code=1)
+    console.print(
+        f"[green]Replayed {len(result.file_hashes)} file(s) of generation "
+        f"{result.generation_id} into {resolved_output}.[/green]"
+    )
+
+
+@app.command("diff-builds")
+def diff_builds(
+    dir_a: str = typer.Argument(..., help="First output directory"),
+    dir_b: str = typer.Argument(..., help="Second output directory"),
+    as_json: bool = typer.Option(False, "--json", help="Print the differences as JSON"),
+) -> None:
+    """Compare the recorded build state of two output directories."""
+    import dataclasses
+    import json
+
+    from intentc.build.state import StateManager, diff_build_states
+
+    root = _project_root()
+    for d in (dir_a, dir_b):
+        if not (root / ".intentc" / "state" / d).is_dir():
+            print_error(f"No build state recorded for output directory '{d}'.")
+            raise typer.Exit(code=2)
+
+    drifts = diff_build_states(
+        StateManager(base_dir=root, output_dir=dir_a),
+        StateManager(base_dir=root, output_dir=dir_b),
+    )
+
+    if as_json:
+        sys.stdout.write(
+            json.dumps([dataclasses.asdict(d) for d in drifts], indent=2) + "\n"
+        )
+    else:
+        render_build_state_diff(dir_a, dir_b, drifts)
+
+    if drifts:
+        raise typer.Exit(code=1)
+
+
 @app.command()
 def compare(
     dir_a: str = typer.Argument(..., help="Path to the reference output directory"),
@@ -410,9 +1697,12 @@ def compare(
     """Evaluate functional equivalence between two output directories."""
     from intentc.differencing import run_differencing
 
-    cwd = Path.cwd()
-    project = _load_project_or_exit(cwd / "intent")
-    config = load_config(cwd)
+    # Relative to where the user ran the command, not the project root
+    dir_a = str(Path(dir_a).resolve())
+    dir_b = str(Path(dir_b).resolve())
+    root = _project_root()
+    config = _load_config(root, templates=True)
+    project = _load_project_or_exit(_intent_dir(root, config))
 
     # Validate directories exist
     if not Path(dir_a).is_dir():
@@ -436,3 +1726,34 @@ def compare(
 
     if response.status != "equivalent":
         raise typer.Exit(code=1)
+
+
+config_app = typer.Typer(help="Inspect the resolved configuration.")
+app.add_typer(config_app, name="config")
+
+
+@config_app.command("dump")
+def config_dump(
+    annotated: bool = typer.Option(False, "--annotated", help="Mark each value as coming from default, file, or flag"),
+    output_dir: Optional[str] = typer.Option(None, "--output-dir", "-o", help="Apply an output directory override, as build would"),
+    profile: Optional[str] = typer.Option(None, "--profile", "-p", help="Apply an agent profile override, as build would"),
+) -> None:
+    """Print the effective configuration as YAML."""
+    root = _project_root()
+    # The prompts section names the template files; their text is left out
+    config = _load_config(root)
+    sources = config_sources(root)
+
+    if output_dir:
+        config.default_output_dir = _resolve_output_dir(output_dir, config)
+        sources["default_output_dir"] = "flag"
+    if profile:
+        previous = config.default_profile.model_dump(mode="json")
+        config.default_profile = _resolve_profile(profile, config)
+        # The override keeps only provider, timeout and retry settings from the file
+        for key, value in config.default_profile.model_dump(mode="json").items():
+            if value != previous[key]:
+                sources[f"default_profile.{key}"] = "default"
+        sources["default_profile.name"] = "flag"
+
+    sys.stdout.write(dump_config(config, sources if annotated else None))
@@ -2,9 +2,12 @@
 
 from __future__ import annotations
 
+import enum
+import os
 import sys
+import xml.etree.ElementTree as ET
 from pathlib import Path
-from typing import TYPE_CHECKING
+from typing import TYPE_CHECKING, Any, Callable
 
 from rich.console import Console
 from rich.syntax import Syntax
@@ -12,11 +15,58 @@ from rich.table import Table
 
 if TYPE_CHECKING:
     from intentc.build.agents import DifferencingResponse
-    from intentc.build.state import BuildResult, TargetStatus
+    from intentc.build.builder import BuildStats
+    from intentc.build.state import BuildResult, TargetDrift, TargetStatus
     from intentc.build.validations import ValidationSuiteResult
 
 console = Console()
 error_console = Console(stderr=True)
+# Each console's own color setting, restored by reset_color()
+_default_no_color = [(c, c.no_color) for c in (console, error_console)]
+
+
+class Verbosity(int, enum.Enum):
+    QUIET = 0
+    NORMAL = 1
+    VERBOSE = 2
+
+
+_verbosity = Verbosity.NORMAL
+
+
+def set_verbosity(level: Verbosity) -> None:
+    """Set the process-wide verbosity used by progress and debug output."""
+    global _verbosity
+    _verbosity = level
+
+
+def get_verbosity() -> Verbosity:
+    return _verbosity
+
+
+def is_debug_enabled() -> bool:
+    return _verbosity >= Verbosity.VERBOSE
+
+
+def color_enabled(requested: bool = True) -> bool:
+    """Decide whether output should be colored.
+
+    Color is off when not *requested* (``--no-color``), when ``NO_COLOR`` is
+    set to a non-empty value, or when stdout is not a terminal.
+    """
+    return requested and not os.environ.get("NO_COLOR") and console.is_terminal
+
+
+def set_color(enabled: bool) -> None:
+    """Turn color on or off for both the stdout and the stderr console."""
+    for c in (console, error_console):
+        c.no_color = not enabled
+
+
+def reset_color() -> None:
+    """Undo set_color, so one invocation's choice does not outlive it."""
+    for c, no_color in _default_no_color:
+        c.no_color = no_color
 
 
 def print_error(message: str) -> None:
@@ -24,6 +74,17 @@ def print_error(message: str) -> None:
     error_console.print(f"[bold red]Error:[/bold red] {message}")
 
 
+def print_warning(message: str) -> None:
+    """Print a warning message to stderr."""
+    error_console.print(f"[yellow]Warning:[/yellow] {message}")
+
+
+def print_debug(message: str) -> None:
+    """Print a debug message to stderr. Only shown in verbose mode."""
+    if is_debug_enabled():
+        error_console.print(f"[dim]debug:[/dim] {message}")
+
+
 def render_init_summary(files: list[str]) -> None:
     """Print a summary of files created during init."""
     console.print("[bold green]Project initialized![/bold green]")
@@ -58,6 +119,91 @@ def render_build_results(results: list[BuildResult]) -> None:
         )
 
     console.print(table)
+    total = sum(r.total_duration_secs for r in results)
+    console.print(f"[dim]Total build time: {total:.1f}s[/dim]")
+
+
+def render_build_stats(stats: BuildStats) -> None:
+    """Print a build's aggregate metrics and the time each target took."""
+    console.print(
+        f"Targets: {stats.total_targets} ({stats.built} built, "
+        f"{stats.skipped} skipped, {stats.failed} failed)"
+    )
+    console.print(
+        f"Agent attempts: {stats.attempts} ({stats.retries} retr"
+        f"{'y' if stats.retries == 1 else 'ies'})"
+    )
+    console.print(f"Total duration: {stats.total_duration_secs:.1f}s")
+    if not stats.targets:
+        return
+
+    table = Table(title="Build Stats")
+    table.add_column("Target", style="cyan")
+    table.add_column("Status")
+    table.add_column("Duration", justify="right")
+    table.add_column("Attempts", justify="right")
+    for t in stats.targets:
+        status_style = "green" if t.status == "built" else "red"
+        table.add_row(
+            t.target,
+            f"[{status_style}]{t.status}[/{status_style}]",
+            f"{t.duration_secs:.1f}s",
+            str(t.attempts),
+        )
+    console.print(table)
+
+
+def render_history_table(target: str, history: list[BuildResult]) -> None:
+    """Print a target's build history, newest first."""
+    if not history:
+        console.print(f"[dim]No builds recorded for '{target}'.[/dim]")
+        return
+
+    table = Table(title=f"Build History: {target}")
+    table.add_column("Generation", style="cyan")
+    table.add_column("Status")
+    table.add_column("Timestamp")
+    table.add_column("Duration", justify="right")
+    table.add_column("Commit")
+
+    for r in history:
+        status_style = "green" if r.status == "built" else "red"
+        duration = f"{r.total_duration_secs:.1f}s" if r.total_duration_secs else "-"
+        table.add_row(
+            r.generation_id or "-",
+            f"[{status_style}]{r.status}[/{status_style}]",
+            r.timestamp or "-",
+            duration,
+            r.commit_id[:8] if r.commit_id else "-",
+        )
+
+    console.print(table)
+
+
+def render_slowest_builds(results: list[BuildResult]) -> None:
+    """Print build results sorted slowest first."""
+    if not results:
+        console.print("[dim]No builds recorded.[/dim]")
+        return
+
+    table = Table(title="Slowest Builds")
+    table.add_column("Target", style="cyan")
+    table.add_column("Duration", justify="right")
+    table.add_column("Status")
+    table.add_column("Generation")
+    table.add_column("Timestamp")
+
+    for r in results:
+        status_style = "green" if r.status == "built" else "red"
+        table.add_row(
+            r.target,
+            f"{r.total_duration_secs:.1f}s",
+            f"[{status_style}]{r.status}[/{status_style}]",
+            r.generation_id or "-",
+            r.timestamp or "-",
+        )
+
+    console.print(table)
 
 
 def render_validation_results(results: list[ValidationSuiteResult]) -> None:
@@ -65,6 +211,7 @@ def render_validation_results(results: list[ValidationSuiteResult]) -> None:
     total_passed = 0
     total_errors = 0
     total_warnings = 0
+    total_skipped = 0
 
     for suite_result in results:
         console.print(f"\n[bold]{suite_result.target}[/bold]")
@@ -72,6 +219,9 @@ def render_validation_results(results: list[ValidationSuiteResult]) -> None:
             if vr.status == "pass":
                 console.print(f"  [green]✓[/green] {vr.name}: {vr.reason}")
                 total_passed += 1
+            elif vr.status == "skipped":
+                console.print(f"  [yellow]-[/yellow] {vr.name}: {vr.reason}")
+                total_skipped += 1
             else:
                 console.print(f"  [red]✗[/red] {vr.name}: {vr.reason}")
                 total_errors += 1
@@ -80,6 +230,180 @@ def render_validation_results(results: list[ValidationSuiteResult]) -> None:
     console.print(
         f"{total_passed}/{total_passed + total_errors} passed, "
         f"{total_errors} error(s), {total_warnings} warning(s)"
+        + (f", {total_skipped} skipped" if total_skipped else "")
+    )
+
+
+def render_validation_summary(results: list[ValidationSuiteResult]) -> None:
+    """Print one pass/fail line per validated target and an overall verdict."""
+    failed = 0
+    console.print()
+    for suite_result in results:
+        passed = sum(1 for vr in suite_result.results if vr.status == "pass")
+        counted = sum(1 for vr in suite_result.results if vr.status != "skipped")
+        mark = "[green]✓[/green]" if suite_result.passed else "[red]✗[/red]"
+        console.print(f"  {mark} {suite_result.target}: {passed}/{counted} passed")
+        if not suite_result.passed:
+            failed += 1
+
+    if failed:
+        console.print(f"[red]Overall: FAILED[/red] ({failed} of {len(results)} target(s) failed)")
+    else:
+        console.print(f"[green]Overall: PASSED[/green] ({len(results)} target(s))")
+
+
+# Fill colors of graph nodes by target status
+_STATUS_COLORS = {
+    "built": "#90be6d",
+    "pending": "#e9ecef",
+    "building": "#8ecae6",
+    "failed": "#f94144",
+    "outdated": "#f9c74f",
+}
+
+
+def render_graph(
+    features: dict[str, list[str]],
+    fmt: str,
+    affected: set[str] | None = None,
+    statuses: dict[str, str] | None = None,
+) -> str:
+    """Render the feature graph as Graphviz DOT (``dot``) or Mermaid (``mermaid``).
+
+    *features* maps each feature to its dependencies, in topological order.
+    Edges point from a dependency to the feature that needs it. Features in
+    *affected* are filled in a distinct color. With *statuses* (feature ->
+    status value) every node is filled by its status instead, and affected
+    features get a thick outline.
+    """
+    affected = affected or set()
+    if fmt == "dot":
+        lines = ["digraph intentc {", "  rankdir=LR;"]
+        for feature in features:
+            attrs: list[str] = []
+            if statuses is not None:
+                status = statuses.get(feature, "pending")
+                color = _STATUS_COLORS.get(status, _STATUS_COLORS["pending"])
+                attrs += ["style=filled", f'fillcolor="{color}"', f'tooltip="{status}"']
+                if feature in affected:
+                    attrs.append("penwidth=3")
+            elif feature in affected:
+                attrs += ["style=filled", 'fillcolor="#f4a261"']
+            suffix = f" [{', '.join(attrs)}]" if attrs else ""
+            lines.append(f'  "{feature}"{suffix};')
+        for feature, deps in features.items():
+            lines.extend(f'  "{dep}" -> "{feature}";' for dep in deps)
+        lines.append("}")
+        return "\n".join(lines) + "\n"
+
+    # Mermaid ids cannot contain "/", so nodes get positional ids and labels
+    ids = {feature: f"n{i}" for i, feature in enumerate(features)}
+    lines = ["graph LR"]
+    lines.extend(f'  {ids[feature]}["{feature}"]' for feature in features)
+    for feature, deps in features.items():
+        lines.extend(f"  {ids[dep]} --> {ids[feature]}" for dep in deps if dep in ids)
+    if statuses is not None:
+        for status, color in _STATUS_COLORS.items():
+            marked = ",".join(
+                ids[f] for f in features if statuses.get(f, "pending") == status
+            )
+            if marked:
+                lines.append(f"  classDef {status} fill:{color}")
+                lines.append(f"  class {marked} {status}")
+        if affected:
+            lines.append("  classDef affected stroke-width:3px")
+            marked = ",".join(ids[f] for f in features if f in affected)
+            lines.append(f"  class {marked} affected")
+    elif affected:
+        lines.append("  classDef affected fill:#f4a261")
+        marked = ",".join(ids[f] for f in features if f in affected)
+        lines.append(f"  class {marked} affected")
+    return "\n".join(lines) + "\n"
+
+
+def render_junit_report(results: list[ValidationSuiteResult]) -> str:
+    """Render validation results as a JUnit XML report.
+
+    Each target becomes a ``<testsuite>`` and each validation a ``<testcase>``;
+    failed validations carry their reason as the failure message and skipped
+    ones are reported as ``<skipped>``.
+    """
+    root = ET.Element("testsuites", name="intentc")
+    total_tests = 0
+    total_failures = 0
+    total_time = 0.0
+
+    for suite_result in results:
+        suite = ET.SubElement(root, "testsuite", name=suite_result.target)
+        suite_failures = 0
+        suite_skipped = 0
+        suite_time = 0.0
+        for vr in suite_result.results:
+            duration = vr.duration_secs or 0.0
+            case = ET.SubElement(
+                suite,
+                "testcase",
+                name=vr.name,
+                classname=suite_result.target,
+                time=f"{duration:.3f}",
+            )
+            if vr.status == "skipped":
+                ET.SubElement(case, "skipped", message=vr.reason)
+                suite_skipped += 1
+            elif vr.status != "pass":
+                failure = ET.SubElement(case, "failure", message=vr.reason, type=vr.status)
+                failure.text = vr.reason
+                suite_failures += 1
+            suite_time += duration
+        suite.set("tests", str(len(suite_result.results)))
+        suite.set("failures", str(suite_failures))
+        suite.set("skipped", str(suite_skipped))
+        suite.set("time", f"{suite_time:.3f}")
+        total_tests += len(suite_result.results)
+        total_failures += suite_failures
+        total_time += suite_time
+
+    root.set("tests", str(total_tests))
+    root.set("failures", str(total_failures))
+    root.set("time", f"{total_time:.3f}")
+    ET.indent(root)
+    return '<?xml version="1.0" encoding="UTF-8"?>\n' + ET.tostring(root, encoding="unicode") + "\n"
+
+
+_STATUS_GLYPHS = {
+    "built": "[green]✓[/green]",
+    "failed": "[red]✗[/red]",
+    "building": "[yellow]…[/yellow]",
+    "outdated": "[yellow]~[/yellow]",
+    "pending": "[dim]·[/dim]",
+}
+
+
+def render_status_matrix(
+    output_dirs: list[str],
+    statuses: dict[str, dict[str, TargetStatus]],
+    sort_key: Callable[[str], Any] | None = None,
+) -> None:
+    """Print targets (rows) against output directories (columns) as status glyphs.
+
+    Rows are sorted by target name unless *sort_key* says otherwise.
+    """
+    table = Table(title="Build Status (all builds)")
+    table.add_column("Target", style="cyan")
+    for output_dir in output_dirs:
+        table.add_column(output_dir, justify="center")
+
+    targets = sorted({t for per_dir in statuses.values() for t in per_dir}, key=sort_key)
+    for target in targets:
+        cells = []
+        for output_dir in output_dirs:
+            status = statuses.get(output_dir, {}).get(target)
+            cells.append(_STATUS_GLYPHS.get(status.value, "?") if status else "[dim]-[/dim]")
+        table.add_row(target, *cells)
+
+    console.print(table)
+    console.print(
+        "[dim]✓ built  ✗ failed  ~ outdated  … building  · pending  - not tracked[/dim]"
     )
 
 
@@ -87,13 +411,25 @@ def render_status_table(
     targets: list[tuple[str, TargetStatus]],
     build_results: dict[str, BuildResult] | None = None,
     outdated: list[str] | None = None,
+    manual: list[str] | None = None,
+    validations: dict[str, tuple[str, dict[str, str]]] | None = None,
 ) -> None:
-    """Print status table for all tracked targets."""
+    """Print status table for all tracked targets.
+
+    Targets in *manual* are marked, as a build of all targets leaves them out.
+    *validations* maps a target to the generation its validations last ran
+    under and their statuses; when any target has some, a column shows how
+    many passed.
+    """
+    validations = {t: v for t, v in (validations or {}).items() if v[1]}
     table = Table(title="Build Status")
     table.add_column("Target", style="cyan")
     table.add_column("Status")
     table.add_column("Last Build", justify="right")
+    table.add_column("Duration", justify="right")
     table.add_column("Generation ID")
+    if validations:
+        table.add_column("Validations")
 
     if outdated is None:
         outdated = []
@@ -107,6 +443,11 @@ def render_status_table(
 
         result = build_results.get(target)
         timestamp = result.timestamp if result else "-"
+        duration = (
+            f"{result.total_duration_secs:.1f}s"
+            if result and result.total_duration_secs
+            else "-"
+        )
         gen_id = result.generation_id[:8] if result and result.generation_id else "-"
 
         status_style = {
@@ -117,16 +458,33 @@ def render_status_table(
             "outdated": "yellow",
         }.get(status.value, "white")
 
-        table.add_row(
-            target,
+        row = [
+            f"{target} [dim](manual)[/dim]" if target in (manual or []) else target,
             f"[{status_style}]{status_str}[/{status_style}]",
             timestamp or "-",
+            duration,
             gen_id,
-        )
+        ]
+        if validations:
+            row.append(_validation_summary(validations.get(target)))
+        table.add_row(*row)
 
     console.print(table)
 
 
+def _validation_summary(run: tuple[str, dict[str, str]] | None) -> str:
+    """``3/4 passing as of <generation>`` for a status table cell."""
+    if run is None:
+        return "-"
+    generation_id, statuses = run
+    passed = sum(1 for s in statuses.values() if s == "pass")
+    style = "green" if passed == len(statuses) else "red"
+    return (
+        f"[{style}]{passed}/{len(statuses)} passing[/{style}] "
+        f"[dim]as of {generation_id[:12]}[/dim]"
+    )
+
+
 def render_diff(diff_text: str) -> None:
     """Print a diff with syntax highlighting."""
     if not diff_text:
@@ -136,6 +494,83 @@ def render_diff(diff_text: str) -> None:
     console.print(syntax)
 
 
+def render_generation_diff(
+    target: str,
+    from_gen: str,
+    to_gen: str,
+    changes: list[tuple[str, str]],
+    diff_text: str,
+) -> None:
+    """Print the files that changed between two generations of a target, then the diff."""
+    console.print(f"[bold]{target}[/bold]: {from_gen[:8]} -> {to_gen[:8]}")
+    if not changes:
+        console.print("[green]No changes between the two generations.[/green]")
+        return
+
+    labels = {"A": ("added", "green"), "D": ("removed", "red"), "M": ("modified", "yellow")}
+    for status, path in changes:
+        label, color = labels.get(status, ("changed", "yellow"))
+        console.print(f"  [{color}]{label:<8}[/{color}] {path}")
+    console.print()
+    render_diff(diff_text)
+
+
+def render_file_changes(results: list[BuildResult]) -> None:
+    """Print the files each previewed target would change, with their diffs."""
+    if not results:
+        console.print("[dim]No targets to preview.[/dim]")
+        return
+
+    labels = {"created": "green", "deleted": "red", "modified": "yellow"}
+    for r in results:
+        changes = getattr(r, "_file_changes", [])
+        console.print(f"\n[bold]{r.target}[/bold]")
+        if r.status == "failed":
+            summary = r.steps[-1].summary if r.steps else ""
+            console.print(f"  [red]failed[/red] {summary}")
+            continue
+        if not changes:
+            console.print("  [dim]No file changes.[/dim]")
+            continue
+        for change in changes:
+            color = labels.get(change.change, "yellow")
+            console.print(f"  [{color}]{change.change:<8}[/{color}] {change.path}")
+        render_diff("".join(c.diff for c in changes))
+
+
+def render_build_state_diff(
+    dir_a: str, dir_b: str, drifts: list[TargetDrift]
+) -> None:
+    """Print per-target differences between two output directories' state."""
+    if not drifts:
+        console.print(f"[green]No differences between '{dir_a}' and '{dir_b}'.[/green]")
+        return
+
+    table = Table(title=f"Build State: {dir_a} vs {dir_b}")
+    table.add_column("Target", style="cyan")
+    table.add_column(f"A: {dir_a}")
+    table.add_column(f"B: {dir_b}")
+    table.add_column("Difference")
+
+    def _side(status: str | None, generation: str | None, only: list[str]) -> str:
+        if status is None:
+            return "[dim]-[/dim]"
+        text = f"{status} ({generation[:8]})" if generation else status
+        if only:
+            text += f"\n+{len(only)} file(s)"
+        return text
+
+    for d in drifts:
+        table.add_row(
+            d.target,
+            _side(d.status_a, d.generation_a, d.files_only_a),
+            _side(d.status_b, d.generation_b, d.files_only_b),
+            d.description,
+        )
+
+    console.print(table)
+
+
 def render_compare_results(response: DifferencingResponse) -> None:
     """Print differencing results: dimension table + summary."""
     table = Table(title="Differencing Results")
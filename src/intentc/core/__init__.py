@@ -8,17 +8,25 @@ from intentc.core.models import (
     Severity,
     ParseError,
     ParseErrors,
+    TargetNotFoundError,
+    DependencyCycleError,
+    UnbuiltDependencyError,
 )
 from intentc.core.parser import (
+    extract_dependencies_section,
     extract_file_references,
+    extract_sections,
+    extract_target_sections,
     parse_intent_file,
     parse_validation_file,
+    remove_validations_section,
     write_intent_file,
     write_validation_file,
 )
 from intentc.core.project import (
     FeatureNode,
     Project,
+    find_dangling_validations,
     load_project,
     write_project,
     blank_project,
@@ -32,15 +40,23 @@ __all__ = [
     "Validation",
     "ValidationType",
     "Severity",
+    "extract_dependencies_section",
     "extract_file_references",
+    "extract_sections",
+    "extract_target_sections",
     "ParseError",
     "ParseErrors",
+    "TargetNotFoundError",
+    "DependencyCycleError",
+    "UnbuiltDependencyError",
     "parse_intent_file",
     "parse_validation_file",
+    "remove_validations_section",
     "write_intent_file",
     "write_validation_file",
     "FeatureNode",
     "Project",
+    "find_dangling_validations",
     "load_project",
     "write_project",
     "blank_project",
@@ -0,0 +1,114 @@
+"""Intent linting: keep intents free of concrete technology choices."""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+from pathlib import Path
+
+from intentc.core.models import Severity
+from intentc.core.project import Project
+
+# Technology names that belong in an implementation, not in an intent.
+# Matched case-sensitively as whole words.
+DEFAULT_TECH_TERMS: list[str] = [
+    # Languages
+    "Python", "JavaScript", "TypeScript", "Java", "Kotlin", "Golang", "Rust",
+    "C++", "C#", "Ruby", "PHP", "Swift",
+    # Frameworks and runtimes
+    "Express.js", "Express", "Node.js", "React", "Vue", "Angular", "Svelte",
+    "Next.js", "Django", "Flask", "FastAPI", "Rails", "Spring", "Laravel",
+    # Data stores and infrastructure
+    "PostgreSQL", "Postgres", "MySQL", "SQLite", "MongoDB", "Redis", "Kafka",
+    "Docker", "Kubernetes",
+]
+
+# Marker that suppresses findings on its line.
+ALLOW_MARKER = "intentc:allow"
+
+
+@dataclass
+class LintFinding:
+    """A technology mention found in an intent."""
+
+    path: Path
+    line: int
+    term: str
+    severity: Severity
+
+    def __str__(self) -> str:
+        return (
+            f"{self.path}:{self.line}: {self.severity.value}: "
+            f"mentions '{self.term}'; describe the behavior, not the technology"
+        )
+
+
+def _term_pattern(terms: list[str]) -> re.Pattern[str]:
+    # Longest first so "Express.js" wins over "Express"
+    alternation = "|".join(re.escape(t) for t in sorted(terms, key=len, reverse=True))
+    return re.compile(rf"(?<![\w.])(?:{alternation})(?![\w+#])")
+
+
+def _body_start(lines: list[str]) -> int:
+    """Index of the first body line, skipping ``---`` frontmatter."""
+    if not lines or lines[0].strip() != "---":
+        return 0
+    for i in range(1, len(lines)):
+        if lines[i].strip() == "---":
+            return i + 1
+    return 0
+
+
+def lint_intent_text(
+    text: str,
+    path: Path,
+    terms: list[str],
+    severity: Severity = Severity.WARNING,
+) -> list[LintFinding]:
+    """Find technology mentions in the body of an intent file's *text*.
+
+    Frontmatter is not checked. Lines containing ``intentc:allow`` (for
+    example ``// intentc:allow``) are skipped.
+    """
+    if not terms:
+        return []
+    pattern = _term_pattern(terms)
+    lines = text.splitlines()
+    findings: list[LintFinding] = []
+    for idx in range(_body_start(lines), len(lines)):
+        line = lines[idx]
+        if ALLOW_MARKER in line:
+            continue
+        for match in pattern.finditer(line):
+            findings.append(LintFinding(path, idx + 1, match.group(0), severity))
+    return findings
+
+
+def lint_project(
+    project: Project,
+    terms: list[str] | None = None,
+    severity: Severity = Severity.WARNING,
+) -> list[LintFinding]:
+    """Lint the project intent and every feature intent.
+
+    Implementations are skipped: naming technologies is their job.
+    """
+    terms = DEFAULT_TECH_TERMS if terms is None else terms
+    paths: list[Path] = []
+    if project.project_intent.source_path is not None:
+        paths.append(project.project_intent.source_path)
+    for feature in sorted(project.features):
+        paths.extend(
+            intent.source_path
+            for intent in project.features[feature].intents
+            if intent.source_path is not None
+        )
+
+    findings: list[LintFinding] = []
+    for path in paths:
+        try:
+            text = path.read_text(encoding="utf-8")
+        except OSError:
+            continue
+        findings.extend(lint_intent_text(text, path, terms, severity))
+    return findings
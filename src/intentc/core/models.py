@@ -10,6 +10,9 @@ from pydantic import BaseModel, Field
 
 class ValidationType(str, enum.Enum):
     AGENT_VALIDATION = "agent_validation"
+    FILE_CHECK = "file_check"
+    FOLDER_CHECK = "folder_check"
+    WEB_CHECK = "web_check"
 
 
 class Severity(str, enum.Enum):
@@ -24,8 +27,39 @@ class IntentFile(BaseModel):
     authors: list[str] = Field(default_factory=list)
     body: str = ""
     file_references: list[str] = Field(default_factory=list)
+    # Reference files (relative to the .ic file) whose contents go into the prompt
+    context: list[str] = Field(default_factory=list)
+    # Capabilities the building agent's profile must declare (web, decompile, ...)
+    requires_capabilities: list[str] = Field(default_factory=list)
+    # Left out of a build of all targets; built when named or needed as a dependency
+    manual: bool = False
+    # Name of the agent profile (under agents.profiles) that builds this feature
+    agent: str | None = None
+    # Named sub-targets from ``## Target: <name>`` sections, name -> section content
+    targets: dict[str, str] = Field(default_factory=dict)
+    # Every ``## <title>`` section of the body, title -> section content
+    sections: dict[str, str] = Field(default_factory=dict)
+    # Frontmatter keys not modeled above (owner, ticket, ...), kept for reporting
+    metadata: dict[str, object] = Field(default_factory=dict)
+    # Validations declared in a ``## Validations`` section of the body
+    inline_validations: ValidationFile | None = None
     source_path: Path | None = None
 
+    @property
+    def custom_sections(self) -> dict[str, str]:
+        """Sections other than ``Target: <name>``, ``Validations`` and
+        ``Dependencies``.
+
+        These are the project's own conventions (Constraints, Non-Goals,
+        ...); they apply to the whole feature, including each sub-target.
+        """
+        return {
+            title: content
+            for title, content in self.sections.items()
+            if not title.startswith("Target:")
+            and title not in ("Validations", "Dependencies")
+        }
+
 
 class ProjectIntent(BaseModel):
     name: str
@@ -51,6 +85,8 @@ class Validation(BaseModel):
     type: ValidationType = ValidationType.AGENT_VALIDATION
     severity: Severity = Severity.ERROR
     args: dict[str, object] = Field(default_factory=dict)
+    # Name of another validation in the same target that must pass first
+    depends_on: str | None = None
 
 
 class ValidationFile(BaseModel):
@@ -58,20 +94,29 @@ class ValidationFile(BaseModel):
     agent_profile: str | None = None
     validations: list[Validation] = Field(default_factory=list)
     source_path: Path | None = None
+    warnings: list[str] = Field(default_factory=list)  # Suspicious but loadable entries
 
 
 class ParseError:
     """A single parse error with location context."""
 
-    def __init__(self, path: Path, message: str, field: str | None = None) -> None:
+    def __init__(
+        self,
+        path: Path,
+        message: str,
+        field: str | None = None,
+        line: int | None = None,
+    ) -> None:
         self.path = path
         self.field = field
         self.message = message
+        self.line = line  # 1-based, when known
 
     def __str__(self) -> str:
+        location = f"{self.path}:{self.line}" if self.line else f"{self.path}"
         if self.field:
-            return f"{self.path} [{self.field}]: {self.message}"
-        return f"{self.path}: {self.message}"
+            return f"{location} [{self.field}]: {self.message}"
+        return f"{location}: {self.message}"
 
     def __repr__(self) -> str:
         return f"ParseError({self!s})"
@@ -84,3 +129,37 @@ class ParseErrors(Exception):
         self.errors = errors
         lines = "\n".join(str(e) for e in errors)
         super().__init__(f"{len(errors)} parse error(s):\n{lines}")
+
+
+class TargetNotFoundError(KeyError):
+    """A feature, sub-target, pattern or tag named no known target.
+
+    Subclasses KeyError so existing ``except KeyError`` handlers still apply.
+    """
+
+    def __init__(self, message: str, target: str) -> None:
+        super().__init__(message)
+        self.target = target
+
+    def __str__(self) -> str:
+        # KeyError would quote the message
+        return self.args[0]
+
+
+class DependencyCycleError(ValueError):
+    """The feature graph has a cycle; *cycle* starts and ends with the same feature."""
+
+    def __init__(self, cycle: list[str]) -> None:
+        super().__init__(f"Dependency cycle detected: {' -> '.join(cycle)}")
+        self.cycle = cycle
+
+
+class UnbuiltDependencyError(RuntimeError):
+    """A target was built without its dependency *dependency*, which is not built."""
+
+    def __init__(self, target: str, dependency: str) -> None:
+        super().__init__(
+            f"Cannot build '{target}': dependency '{dependency}' is not built."
+        )
+        self.target = target
+        self.dependency = dependency
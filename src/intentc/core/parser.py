@@ -2,6 +2,7 @@
 
 from __future__ import annotations
 
+import difflib
 import re
 from pathlib import Path
 
@@ -30,11 +31,278 @@ _FILE_REF_RE = re.compile(
 )
 
 
+# Frontmatter keys that map to typed IntentFile fields; anything else is metadata.
+_INTENT_FIELDS = {
+    "name", "depends_on", "tags", "authors", "context", "requires_capabilities",
+    "manual", "agent",
+}
+
+# Suffix of intents written as a single YAML document instead of Markdown.
+YAML_INTENT_SUFFIX = ".icy"
+
+# Keys of a YAML intent that become body sections rather than frontmatter.
+_YAML_BODY_FIELDS = ("description", "targets", "validations")
+
+# Keys of a validation entry that map to typed Validation fields; anything else
+# is an inline argument.
+_VALIDATION_FIELDS = {"name", "type", "severity", "args", "depends_on"}
+
+# Argument types of the built-in validation types, checked when a file is
+# loaded. Arguments not listed here are passed to the runner untouched.
+_ARG_TYPES: dict[ValidationType, dict[str, type]] = {
+    ValidationType.AGENT_VALIDATION: {"rubric": str},
+    ValidationType.FILE_CHECK: {"file": str, "exists": bool, "contains": list},
+    ValidationType.FOLDER_CHECK: {
+        "folder": str,
+        "exists": bool,
+        "recursive": bool,
+        "min_files": int,
+        "max_files": int,
+        "contains_dirs": list,
+    },
+    ValidationType.WEB_CHECK: {
+        "url": str,
+        "status_code": int,
+        "contains": str,
+        "check": str,
+    },
+}
+_REQUIRED_ARGS: dict[ValidationType, set[str]] = {
+    ValidationType.FILE_CHECK: {"file"},
+    ValidationType.FOLDER_CHECK: {"folder"},
+    ValidationType.WEB_CHECK: {"url"},
+}
+# Arguments every built-in validation type accepts besides its own.
+_COMMON_ARGS = {"min_confidence", "timeout", "retries"}
+_TYPE_NAMES = {str: "a string", bool: "true or false", int: "an integer", list: "a list"}
+
+# Matches a sub-target section header like ``## Target: rest-api``.
+_TARGET_HEADER_RE = re.compile(r"^##\s+Target:\s*(?P<name>\S.*?)\s*$")
+
+# Matches the inline validations section header ``## Validations``.
+_VALIDATIONS_HEADER_RE = re.compile(r"^##\s+Validations\s*$")
+
+# Matches any level-2 section header, capturing its title.
+_SECTION_HEADER_RE = re.compile(r"^##\s+(?P<title>\S.*?)\s*$")
+
+# Matches a bullet of the ``## Dependencies`` section, capturing the feature
+# path; text after it (a description) is ignored.
+_DEPENDENCY_ITEM_RE = re.compile(r"^\s*[-*]\s+`?(?P<name>[^\s`:,]+)")
+
+# Matches a bullet marker, capturing the text after it.
+_BULLET_RE = re.compile(r"^\s*[-*]\s+(?P<text>.*)$")
+
+# Matches one feature path of a comma-separated dependency list.
+_DEPENDENCY_NAME_RE = re.compile(r"^`?(?P<name>[^\s`:,]+)`?$")
+
+# Matches a fenced code block, optionally tagged yaml, wrapping a section.
+_FENCED_RE = re.compile(r"^```(?:ya?ml)?\s*\n(?P<body>.*?)\n```\s*$", re.DOTALL)
+
+
 def extract_file_references(text: str) -> list[str]:
     """Extract file references from markdown body text."""
     return _FILE_REF_RE.findall(text)
 
 
+def extract_target_sections(body: str) -> dict[str, str]:
+    """Extract ``## Target: <name>`` sections from markdown body text.
+
+    Each section runs until the next level-1 or level-2 header. Returns a
+    mapping of sub-target name to its section content, in file order.
+    """
+    sections: dict[str, str] = {}
+    current: str | None = None
+    lines: list[str] = []
+
+    for line in body.splitlines():
+        match = _TARGET_HEADER_RE.match(line)
+        is_header = line.startswith("# ") or line.startswith("## ")
+        if current is not None and (match or is_header):
+            sections[current] = "\n".join(lines).strip()
+            current = None
+        if match:
+            current = match.group("name")
+            lines = []
+        elif current is not None:
+            lines.append(line)
+
+    if current is not None:
+        sections[current] = "\n".join(lines).strip()
+    return sections
+
+
+def extract_sections(body: str) -> dict[str, str]:
+    """Extract every ``## <title>`` section from markdown body text.
+
+    Each section runs until the next level-1 or level-2 header. Returns a
+    mapping of title to section content, in file order; a title that
+    appears twice has its contents joined.
+    """
+    sections: dict[str, str] = {}
+    current: str | None = None
+    lines: list[str] = []
+
+    def close() -> None:
+        content = "\n".join(lines).strip()
+        if current in sections:
+            sections[current] = f"{sections[current]}\n\n{content}".strip()
+        else:
+            sections[current] = content
+
+    for line in body.splitlines():
+        match = _SECTION_HEADER_RE.match(line)
+        if current is not None and (match or line.startswith("# ")):
+            close()
+            current = None
+        if match:
+            current = match.group("title")
+            lines = []
+        elif current is not None:
+            lines.append(line)
+
+    if current is not None:
+        close()
+    return sections
+
+
+def _dependency_list(text: str) -> list[str] | None:
+    """Split a comma-separated list of feature paths, or None if *text*
+    is not one (it has a description, say)."""
+    items = [item.strip() for item in text.split(",")]
+    if items and not items[-1]:
+        items.pop()  # Trailing comma
+    matches = [_DEPENDENCY_NAME_RE.match(item) for item in items]
+    if not matches or not all(matches):
+        return None
+    return [m.group("name") for m in matches]
+
+
+def extract_dependencies_section(body: str) -> list[str]:
+    """Return the feature paths listed in the ``## Dependencies`` section.
+
+    Each bullet names one feature, optionally followed by a description, or
+    several separated by commas. An entry continues onto the next line when
+    it ends with a comma or the next line is indented, so a wrapped list
+    keeps all its paths; a blank line or a bullet starts the next entry.
+    Comma-separated paths may also stand on a line of their own; other
+    lines are prose ("None.", "TBD") and name nothing. Returns [] when
+    there is no such section.
+    """
+    content = extract_sections(body).get("Dependencies", "")
+    names: list[str] = []
+    entry: str | None = None
+    entry_is_bullet = False
+    entry_continued = False
+
+    def flush() -> None:
+        if entry is None or not (entry_is_bullet or entry_continued or "," in entry):
+            return
+        listed = _dependency_list(entry)
+        if listed is not None:
+            names.extend(listed)
+        elif entry_is_bullet and (match := _DEPENDENCY_ITEM_RE.match(f"- {entry}")):
+            names.append(match.group("name"))
+
+    for line in content.splitlines():
+        bullet = _BULLET_RE.match(line)
+        stripped = line.strip()
+        continues = entry is not None and (
+            entry.endswith(",") or line[:1].isspace()
+        )
+        if stripped and not bullet and continues:
+            entry = f"{entry} {stripped}"
+            entry_continued = True
+            continue
+        flush()
+        entry = bullet.group("text").strip() if bullet else (stripped or None)
+        entry_is_bullet = bullet is not None
+        entry_continued = False
+    flush()
+    return names
+
+
+def extract_validations_section(body: str) -> str | None:
+    """Return the content of the ``## Validations`` section, or None.
+
+    The section runs until the next level-1 or level-2 header. A fenced
+    code block around the content is unwrapped.
+    """
+    lines: list[str] | None = None
+    for line in body.splitlines():
+        if lines is None:
+            if _VALIDATIONS_HEADER_RE.match(line):
+                lines = []
+        elif line.startswith("# ") or line.startswith("## "):
+            break
+        else:
+            lines.append(line)
+
+    if lines is None:
+        return None
+    content = "\n".join(lines).strip()
+    fenced = _FENCED_RE.match(content)
+    return fenced.group("body") if fenced else content
+
+
+def _remove_sections(body: str) -> str:
+    """Return *body* without its ``## Target:`` and ``## Validations`` sections."""
+    return _drop_sections(body, (_TARGET_HEADER_RE, _VALIDATIONS_HEADER_RE))
+
+
+def remove_validations_section(body: str) -> str:
+    """Return *body* without its ``## Validations`` section."""
+    return _drop_sections(body, (_VALIDATIONS_HEADER_RE,))
+
+
+def _drop_sections(body: str, headers: tuple[re.Pattern[str], ...]) -> str:
+    kept: list[str] = []
+    skipping = False
+    for line in body.splitlines():
+        if any(header.match(line) for header in headers):
+            skipping = True
+            continue
+        if line.startswith("# ") or line.startswith("## "):
+            skipping = False
+        if not skipping:
+            kept.append(line)
+    return "\n".join(kept).strip()
+
+
+def yaml_intent_to_markdown(raw: str, path: Path) -> str:
+    """Convert a YAML intent (.icy) into the equivalent .ic Markdown text.
+
+    ``description`` becomes the body, each entry of the ``targets`` mapping a
+    ``## Target:`` section and ``validations`` a ``## Validations`` section.
+    Every other key is kept as frontmatter.
+    """
+    try:
+        data = yaml.safe_load(raw)
+    except yaml.YAMLError as exc:
+        raise ParseErrors([ParseError(path, f"invalid YAML: {exc}")]) from exc
+    if data is None:
+        data = {}
+    if not isinstance(data, dict):
+        raise ParseErrors([ParseError(path, "expected a YAML mapping at top level")])
+
+    targets = data.get("targets") or {}
+    if not isinstance(targets, dict):
+        raise ParseErrors(
+            [ParseError(path, "targets must be a mapping of name to description", field="targets")]
+        )
+
+    meta = {k: v for k, v in data.items() if k not in _YAML_BODY_FIELDS}
+    sections = [str(data.get("description") or "").strip()]
+    for name, content in targets.items():
+        sections.append(f"## Target: {name}\n\n{str(content or '').strip()}")
+    if data.get("validations"):
+        dumped = yaml.dump(data["validations"], default_flow_style=False, sort_keys=False)
+        sections.append(f"## Validations\n\n```yaml\n{dumped.strip()}\n```")
+
+    body = "\n\n".join(s for s in sections if s)
+    frontmatter = yaml.dump(meta, default_flow_style=False, sort_keys=False).strip()
+    return f"---\n{frontmatter}\n---\n\n{body}\n"
+
+
 def _split_frontmatter(text: str) -> tuple[dict[str, object], str]:
     """Split a .ic file into YAML frontmatter dict and body string.
 
@@ -63,7 +331,7 @@ def parse_intent_file(
     as_project: bool = False,
     as_implementation: bool = False,
 ) -> IntentFile | ProjectIntent | Implementation:
-    """Parse a .ic file and return the appropriate model."""
+    """Parse a .ic (or YAML .icy) file and return the appropriate model."""
     path = Path(path)
     errors: list[ParseError] = []
 
@@ -71,6 +339,8 @@ def parse_intent_file(
         raw = path.read_text(encoding="utf-8")
     except OSError as exc:
         raise ParseErrors([ParseError(path, str(exc))]) from exc
+    if path.suffix == YAML_INTENT_SUFFIX:
+        raw = yaml_intent_to_markdown(raw, path)
 
     meta, body = _split_frontmatter(raw)
 
@@ -94,13 +364,211 @@ def parse_intent_file(
     if as_project:
         return ProjectIntent(**common)
 
-    depends_on = meta.get("depends_on", [])
+    # Frontmatter and ``## Dependencies`` bullets together, first mention first
+    depends_on = list(
+        dict.fromkeys([*meta.get("depends_on", []), *extract_dependencies_section(body)])
+    )
     common["depends_on"] = depends_on
 
     if as_implementation:
         return Implementation(**common)
 
-    return IntentFile(**common)
+    inline_validations: ValidationFile | None = None
+    section = extract_validations_section(body)
+    if section is not None:
+        try:
+            data = yaml.safe_load(section)
+        except yaml.YAMLError as exc:
+            raise ParseErrors(
+                [ParseError(path, f"invalid YAML: {exc}", field="validations")]
+            ) from exc
+        # A bare list of entries is shorthand for ``validations: [...]``
+        if isinstance(data, list):
+            data = {"validations": data}
+        inline_validations = _validation_file_from_data(data, path)
+
+    metadata = {k: v for k, v in meta.items() if k not in _INTENT_FIELDS}
+    return IntentFile(
+        **common,
+        context=meta.get("context", []),
+        requires_capabilities=meta.get("requires_capabilities", []),
+        manual=meta.get("manual", False),
+        agent=meta.get("agent"),
+        targets=extract_target_sections(body),
+        sections=extract_sections(body),
+        metadata=metadata,
+        inline_validations=inline_validations,
+    )
+
+
+def _valid_confidence(value: object) -> bool:
+    """True if *value* is unset or a number in [0, 1]."""
+    if value is None:
+        return True
+    if isinstance(value, bool) or not isinstance(value, (int, float)):
+        return False
+    return 0 <= value <= 1
+
+
+def _coerce_arg(value: object, kind: type) -> tuple[bool, object]:
+    """Coerce *value* to *kind*; returns ``(ok, value)``.
+
+    Quoted scalars are accepted where they are unambiguous (``"3"`` for an
+    integer, ``"yes"`` for a boolean), and a single string stands in for a
+    one-item list.
+    """
+    if kind is int:
+        if isinstance(value, int) and not isinstance(value, bool):
+            return True, value
+        if isinstance(value, str) and re.fullmatch(r"[+-]?\d+", value.strip()):
+            return True, int(value)
+    elif kind is bool:
+        if isinstance(value, bool):
+            return True, value
+        if isinstance(value, str) and value.strip().lower() in ("true", "yes", "false", "no"):
+            return True, value.strip().lower() in ("true", "yes")
+    elif kind is str:
+        if isinstance(value, str):
+            return True, value
+        if isinstance(value, (int, float)) and not isinstance(value, bool):
+            return True, str(value)
+    elif kind is list:
+        if isinstance(value, list):
+            return True, [str(item) for item in value]
+        if isinstance(value, str):
+            return True, [value]
+    return False, value
+
+
+def _check_arg_types(
+    validation: Validation,
+    path: Path,
+    lines: dict[str, int],
+    errors: list[ParseError],
+) -> None:
+    """Coerce *validation*'s args to its type's schema, in place, recording errors."""
+    schema = _ARG_TYPES.get(validation.type, {})
+    for key, kind in schema.items():
+        if key not in validation.args or validation.args[key] is None:
+            continue
+        ok, value = _coerce_arg(validation.args[key], kind)
+        if ok:
+            validation.args[key] = value
+            continue
+        errors.append(
+            ParseError(
+                path,
+                f"validation '{validation.name}' arg '{key}' must be "
+                f"{_TYPE_NAMES[kind]}, got {validation.args[key]!r}",
+                field=key,
+                line=lines.get(key) or lines.get(""),
+            )
+        )
+    for key in sorted(_REQUIRED_ARGS.get(validation.type, set())):
+        if validation.args.get(key) in (None, ""):
+            errors.append(
+                ParseError(
+                    path,
+                    f"validation '{validation.name}' ({validation.type.value}) "
+                    f"requires arg '{key}'",
+                    field=key,
+                    line=lines.get(""),
+                )
+            )
+
+
+def _validation_lines(raw: str) -> list[dict[str, int]]:
+    """Line numbers of each validation entry and of its keys and args.
+
+    Returns one map per entry of the ``validations`` list, keyed by field or
+    argument name, with ``""`` for the entry itself. Lines are 1-based.
+    """
+    try:
+        root = yaml.compose(raw, Loader=yaml.SafeLoader)
+    except yaml.YAMLError:
+        return []
+    entries = None
+    if isinstance(root, yaml.MappingNode):
+        for key, value in root.value:
+            if key.value == "validations" and isinstance(value, yaml.SequenceNode):
+                entries = value
+    elif isinstance(root, yaml.SequenceNode):
+        entries = root
+    if entries is None:
+        return []
+
+    def _keys(node: yaml.Node, into: dict[str, int]) -> None:
+        if isinstance(node, yaml.MappingNode):
+            for key, value in node.value:
+                into.setdefault(str(key.value), key.start_mark.line + 1)
+                if key.value == "args":
+                    _keys(value, into)
+        elif isinstance(node, yaml.SequenceNode):
+            for item in node.value:
+                _keys(item, into)
+
+    result: list[dict[str, int]] = []
+    for entry in entries.value:
+        lines = {"": entry.start_mark.line + 1}
+        _keys(entry, lines)
+        result.append(lines)
+    return result
+
+
+def _unknown_keys(entry: dict, path: Path, lines: dict[str, int]) -> list[str]:
+    """Warn about inline keys that no built-in validation type reads.
+
+    Inline arguments make a misspelled field (``severty: warning``) look
+    like an argument, so keys of built-in types that are neither fields nor
+    arguments of the type are reported. Custom types are not checked.
+    """
+    try:
+        vtype = ValidationType(entry.get("type", "agent_validation"))
+    except ValueError:
+        return []
+    known = _VALIDATION_FIELDS | _COMMON_ARGS | set(_ARG_TYPES.get(vtype, {}))
+    warnings: list[str] = []
+    for key in entry:
+        if key in known:
+            continue
+        message = f"validation '{entry.get('name', '')}' has unknown key '{key}'"
+        close = difflib.get_close_matches(str(key), sorted(known), n=1)
+        if close:
+            message += f" (did you mean '{close[0]}'?)"
+        warnings.append(str(ParseError(path, message, line=lines.get(str(key)))))
+    return warnings
+
+
+def _normalize_args(
+    entry: dict, path: Path, errors: list[ParseError]
+) -> dict[str, object]:
+    """Build a validation's argument map from its entry.
+
+    Arguments may be given as an ``args`` mapping, as an ``args`` list of
+    single-key mappings (``- file: x``), or inline next to ``name``. All three
+    forms produce the same map; explicit ``args`` win over inline keys.
+    """
+    args: dict[str, object] = {
+        k: v for k, v in entry.items() if k not in _VALIDATION_FIELDS
+    }
+    raw = entry.get("args")
+    if raw is None:
+        return args
+    if isinstance(raw, dict):
+        args.update(raw)
+        return args
+    if isinstance(raw, list) and all(isinstance(item, dict) for item in raw):
+        for item in raw:
+            args.update(item)
+        return args
+    errors.append(
+        ParseError(
+            path,
+            f"validation '{entry.get('name', '')}' args must be a mapping or a list of mappings",
+            field="args",
+        )
+    )
+    return args
 
 
 def parse_validation_file(path: Path) -> ValidationFile:
@@ -111,8 +579,16 @@ def parse_validation_file(path: Path) -> ValidationFile:
     except OSError as exc:
         raise ParseErrors([ParseError(path, str(exc))]) from exc
 
-    data = yaml.safe_load(raw)
+    return _validation_file_from_data(yaml.safe_load(raw), path, _validation_lines(raw))
+
 
+def _validation_file_from_data(
+    data: object, path: Path, lines: list[dict[str, int]] | None = None
+) -> ValidationFile:
+    """Build a ValidationFile from parsed .icv YAML, collecting all errors.
+
+    *lines* (see _validation_lines) adds line numbers to per-entry errors.
+    """
     # Empty file is valid
     if data is None:
         return ValidationFile(source_path=path)
@@ -120,8 +596,21 @@ def parse_validation_file(path: Path) -> ValidationFile:
     if not isinstance(data, dict):
         raise ParseErrors([ParseError(path, "expected a YAML mapping at top level")])
 
+    lines = lines or []
     validations: list[Validation] = []
-    for v in data.get("validations", []):
+    errors: list[ParseError] = []
+    warnings: list[str] = []
+    for idx, v in enumerate(data.get("validations") or []):
+        entry_lines = lines[idx] if idx < len(lines) else {}
+        if not isinstance(v, dict):
+            errors.append(
+                ParseError(
+                    path,
+                    f"expected a mapping for validation entry, got {v!r}",
+                    line=entry_lines.get(""),
+                )
+            )
+            continue
         vtype = v.get("type", "agent_validation")
         try:
             vtype_enum = ValidationType(vtype)
@@ -134,25 +623,50 @@ def parse_validation_file(path: Path) -> ValidationFile:
         except ValueError:
             sev_enum = Severity.ERROR
 
-        validations.append(
-            Validation(
-                name=v.get("name", ""),
-                type=vtype_enum,
-                severity=sev_enum,
-                args=v.get("args", {}),
-            )
+        validation = Validation(
+            name=v.get("name", ""),
+            type=vtype_enum,
+            severity=sev_enum,
+            args=_normalize_args(v, path, errors),
+            depends_on=v.get("depends_on"),
+        )
+        _check_arg_types(validation, path, entry_lines, errors)
+        warnings.extend(_unknown_keys(v, path, entry_lines))
+        validations.append(validation)
+
+    names = {v.name for v in validations}
+    errors.extend(
+        ParseError(
+            path,
+            f"validation '{v.name}' depends on unknown validation '{v.depends_on}'",
+            field="depends_on",
+        )
+        for v in validations
+        if v.depends_on is not None and v.depends_on not in names
+    )
+    errors.extend(
+        ParseError(
+            path,
+            f"validation '{v.name}' min_confidence must be a number between 0 and 1",
+            field="min_confidence",
         )
+        for v in validations
+        if not _valid_confidence(v.args.get("min_confidence"))
+    )
+    if errors:
+        raise ParseErrors(errors)
 
     return ValidationFile(
         target=data.get("target", ""),
         agent_profile=data.get("agent_profile"),
         validations=validations,
         source_path=path,
+        warnings=warnings,
     )
 
 
-def _intent_to_frontmatter(intent: IntentFile | ProjectIntent | Implementation) -> str:
-    """Serialize an intent object back to .ic file content."""
+def _intent_meta(intent: IntentFile | ProjectIntent | Implementation) -> dict[str, object]:
+    """Return the frontmatter fields of an intent object."""
     meta: dict[str, object] = {"name": intent.name}
 
     if hasattr(intent, "depends_on") and intent.depends_on:
@@ -161,7 +675,37 @@ def _intent_to_frontmatter(intent: IntentFile | ProjectIntent | Implementation)
         meta["tags"] = intent.tags
     if intent.authors:
         meta["authors"] = intent.authors
+    if getattr(intent, "context", None):
+        meta["context"] = intent.context
+    if getattr(intent, "requires_capabilities", None):
+        meta["requires_capabilities"] = intent.requires_capabilities
+    if getattr(intent, "manual", False):
+        meta["manual"] = True
+    if getattr(intent, "agent", None):
+        meta["agent"] = intent.agent
+    for key, value in getattr(intent, "metadata", {}).items():
+        meta.setdefault(key, value)
+    return meta
+
+
+def _intent_to_yaml(intent: IntentFile | ProjectIntent | Implementation) -> str:
+    """Serialize an intent object to .icy (YAML) file content."""
+    data = _intent_meta(intent)
+    description = _remove_sections(intent.body)
+    if description:
+        data["description"] = description
+    targets = getattr(intent, "targets", {})
+    if targets:
+        data["targets"] = dict(targets)
+    section = extract_validations_section(intent.body)
+    if section:
+        data["validations"] = yaml.safe_load(section)
+    return yaml.dump(data, default_flow_style=False, sort_keys=False)
 
+
+def _intent_to_frontmatter(intent: IntentFile | ProjectIntent | Implementation) -> str:
+    """Serialize an intent object back to .ic file content."""
+    meta = _intent_meta(intent)
     yaml_str = yaml.dump(meta, default_flow_style=False, sort_keys=False).strip()
     parts = ["---", yaml_str, "---"]
     if intent.body:
@@ -174,13 +718,19 @@ def write_intent_file(
     intent: IntentFile | ProjectIntent | Implementation,
     path: Path | None = None,
 ) -> Path:
-    """Write an intent object to a .ic file. Returns the path written to."""
+    """Write an intent object to a .ic file. Returns the path written to.
+
+    A path ending in ``.icy`` is written in the YAML intent format.
+    """
     out = Path(path) if path is not None else intent.source_path
     if out is None:
         raise ValueError("No path provided and source_path is not set")
     out = Path(out)
     out.parent.mkdir(parents=True, exist_ok=True)
-    out.write_text(_intent_to_frontmatter(intent), encoding="utf-8")
+    if out.suffix == YAML_INTENT_SUFFIX:
+        out.write_text(_intent_to_yaml(intent), encoding="utf-8")
+    else:
+        out.write_text(_intent_to_frontmatter(intent), encoding="utf-8")
     return out
 
 
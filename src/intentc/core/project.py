@@ -2,7 +2,9 @@
 
 from __future__ import annotations
 
+import difflib
 import fnmatch
+import posixpath
 import shutil
 from collections import deque
 from pathlib import Path
@@ -12,9 +14,11 @@ from pydantic import BaseModel, Field
 from intentc.core.models import (
     Implementation,
     IntentFile,
+    DependencyCycleError,
     ParseError,
     ParseErrors,
     ProjectIntent,
+    TargetNotFoundError,
     ValidationFile,
 )
 from intentc.core.parser import (
@@ -25,6 +29,35 @@ from intentc.core.parser import (
 )
 
 
+def _did_you_mean(suggestions: list[str]) -> str:
+    """Format close matches as a sentence fragment, or '' when there are none."""
+    if not suggestions:
+        return ""
+    return f" Did you mean: {', '.join(suggestions)}?"
+
+
+def _resolve_dependency(dep: str, feature_path: str, all_paths: set[str]) -> str:
+    """Map a dependency name written in *feature_path* to a feature path.
+
+    ``./x`` and ``../x`` are resolved against the feature's own directory.
+    Otherwise an exact feature path wins; failing that, the name is looked
+    up relative to each enclosing directory, nearest first, so a feature in
+    ``payments/api`` can depend on ``ledger`` to mean ``payments/ledger``.
+    Names that resolve to nothing are returned unchanged.
+    """
+    if dep.startswith(("./", "../")):
+        return posixpath.normpath(posixpath.join(feature_path, dep))
+    if dep in all_paths:
+        return dep
+    parent = posixpath.dirname(feature_path)
+    while parent:
+        candidate = f"{parent}/{dep}"
+        if candidate in all_paths:
+            return candidate
+        parent = posixpath.dirname(parent)
+    return dep
+
+
 class FeatureNode(BaseModel):
     """A feature in the project DAG."""
 
@@ -44,6 +77,52 @@ class FeatureNode(BaseModel):
                     result.append(dep)
         return result
 
+    @property
+    def tags(self) -> list[str]:
+        """Combined tags from all intent files, deduplicated, order-preserving."""
+        return list(dict.fromkeys(t for intent in self.intents for t in intent.tags))
+
+    @property
+    def required_capabilities(self) -> list[str]:
+        """Agent capabilities any intent file requires, deduplicated."""
+        return list(
+            dict.fromkeys(
+                c for intent in self.intents for c in intent.requires_capabilities
+            )
+        )
+
+    @property
+    def agent(self) -> str | None:
+        """The agent profile named by the first intent file that names one."""
+        return next((i.agent for i in self.intents if i.agent), None)
+
+    @property
+    def manual(self) -> bool:
+        """True if any intent file marks the feature as manual."""
+        return any(intent.manual for intent in self.intents)
+
+    @property
+    def subtargets(self) -> list[str]:
+        """Names of all ``## Target:`` sections across this feature's intents."""
+        return [name for intent in self.intents for name in intent.targets]
+
+    def subtarget_intent(self, name: str) -> IntentFile | None:
+        """Return an intent whose body is the named sub-target's section.
+
+        The intent's custom sections (Constraints, Non-Goals, ...) follow
+        the sub-target's own text, so they reach the agent too.
+        """
+        for intent in self.intents:
+            if name in intent.targets:
+                parts = [intent.targets[name]]
+                parts += [
+                    f"## {title}\n\n{content}"
+                    for title, content in intent.custom_sections.items()
+                ]
+                body = "\n\n".join(p for p in parts if p)
+                return intent.model_copy(update={"body": body})
+        return None
+
 
 class Project(BaseModel):
     """The full intentc project loaded into memory."""
@@ -79,14 +158,45 @@ class Project(BaseModel):
             f"Specify which one to use."
         )
 
+    def suggest_features(self, name: str, limit: int = 3) -> list[str]:
+        """Feature paths that look like a mistyped *name*, closest first."""
+        return difflib.get_close_matches(name, list(self.features), n=limit)
+
     def _require_feature(self, feature_path: str) -> None:
-        """Raise KeyError if feature_path not in features."""
+        """Raise TargetNotFoundError if feature_path not in features."""
         if feature_path not in self.features:
-            raise KeyError(
-                f"Feature '{feature_path}' not found. "
-                f"Available: {', '.join(sorted(self.features)) or '(none)'}"
+            raise TargetNotFoundError(
+                f"Feature '{feature_path}' not found."
+                f"{_did_you_mean(self.suggest_features(feature_path))} "
+                f"Available: {', '.join(sorted(self.features)) or '(none)'}",
+                feature_path,
             )
 
+    def split_target(self, target: str) -> tuple[str, str | None]:
+        """Split a build target of the form ``feature`` or ``feature:subtarget``.
+
+        Raises TargetNotFoundError (a KeyError) if the feature or the named
+        sub-target does not exist.
+        """
+        feature_path, sep, subtarget = target.partition(":")
+        self._require_feature(feature_path)
+        if not sep:
+            return feature_path, None
+        node = self.features[feature_path]
+        if subtarget not in node.subtargets:
+            suggestions = difflib.get_close_matches(subtarget, node.subtargets, n=3)
+            raise TargetNotFoundError(
+                f"Sub-target '{subtarget}' not found in feature '{feature_path}'."
+                f"{_did_you_mean(suggestions)} "
+                f"Available: {', '.join(node.subtargets) or '(none)'}",
+                target,
+            )
+        return feature_path, subtarget
+
+    def features_with_tag(self, tag: str) -> list[str]:
+        """Feature paths carrying *tag*, sorted."""
+        return sorted(fp for fp, node in self.features.items() if tag in node.tags)
+
     def parents(self, feature_path: str) -> list[str]:
         """Direct dependencies of a feature."""
         self._require_feature(feature_path)
@@ -131,7 +241,7 @@ class Project(BaseModel):
     def topological_order(self) -> list[str]:
         """Return feature paths in dependency-first topological order.
 
-        Raises ValueError on cycle.
+        Raises DependencyCycleError (a ValueError) on cycle.
         """
         # Kahn's algorithm
         in_degree: dict[str, int] = {fp: 0 for fp in self.features}
@@ -153,12 +263,42 @@ class Project(BaseModel):
                     queue.append(child)
 
         if len(result) != len(self.features):
-            missing = set(self.features) - set(result)
-            raise ValueError(
-                f"Dependency cycle detected involving: {', '.join(sorted(missing))}"
-            )
+            raise DependencyCycleError(self.find_cycle())
         return result
 
+    def find_cycle(self) -> list[str]:
+        """Return the first dependency cycle found as a path, or [] if acyclic.
+
+        The path starts and ends with the same feature, e.g. ``[a, b, a]``.
+        Features are visited in sorted order so the result is deterministic.
+        """
+        visiting: list[str] = []
+        done: set[str] = set()
+
+        def _visit(fp: str) -> list[str]:
+            if fp in visiting:
+                return visiting[visiting.index(fp):] + [fp]
+            if fp in done or fp not in self.features:
+                return []
+            visiting.append(fp)
+            for dep in self.features[fp].depends_on:
+                cycle = _visit(dep)
+                if cycle:
+                    return cycle
+            visiting.pop()
+            done.add(fp)
+            return []
+
+        for fp in sorted(self.features):
+            cycle = _visit(fp)
+            if cycle:
+                return cycle
+        return []
+
+
+# Top-level directories of intent/ that hold something other than features
+_SPECIAL_DIRS = {"implementations", "assertions"}
+
 
 def load_project(intent_dir: Path) -> Project:
     """Load the full project from an intent/ directory. Raises ParseErrors on failure."""
@@ -181,7 +321,7 @@ def load_project(intent_dir: Path) -> Project:
     implementations: dict[str, Implementation] = {}
     impl_dir = intent_dir / "implementations"
     if impl_dir.is_dir():
-        for ic_file in sorted(impl_dir.glob("*.ic")):
+        for ic_file in sorted([*impl_dir.glob("*.ic"), *impl_dir.glob("*.icy")]):
             try:
                 impl = parse_intent_file(ic_file, as_implementation=True)
                 assert isinstance(impl, Implementation)
@@ -203,9 +343,9 @@ def load_project(intent_dir: Path) -> Project:
     # Discover features: any directory under intent_dir that contains .ic files,
     # excluding top-level special dirs and files
     features: dict[str, FeatureNode] = {}
-    skip_dirs = {"implementations", "assertions"}
+    skip_dirs = _SPECIAL_DIRS
 
-    for ic_file in sorted(intent_dir.rglob("*.ic")):
+    for ic_file in sorted([*intent_dir.rglob("*.ic"), *intent_dir.rglob("*.icy")]):
         rel = ic_file.relative_to(intent_dir)
         # Skip top-level project.ic and files in special dirs
         if len(rel.parts) < 2:
@@ -223,6 +363,9 @@ def load_project(intent_dir: Path) -> Project:
             intent = parse_intent_file(ic_file)
             assert isinstance(intent, IntentFile)
             features[feature_path].intents.append(intent)
+            # A ``## Validations`` section counts alongside the feature's .icv files
+            if intent.inline_validations is not None:
+                features[feature_path].validations.append(intent.inline_validations)
         except ParseErrors as exc:
             errors.extend(exc.errors)
 
@@ -247,9 +390,9 @@ def load_project(intent_dir: Path) -> Project:
         except ParseErrors as exc:
             errors.extend(exc.errors)
 
-    # Wildcard dependency expansion
+    # Wildcard and relative dependency expansion
     all_feature_paths = set(features.keys())
-    for node in features.values():
+    for feature_path, node in features.items():
         for intent in node.intents:
             expanded: list[str] = []
             for dep in intent.depends_on:
@@ -268,7 +411,9 @@ def load_project(intent_dir: Path) -> Project:
                         )
                     expanded.extend(matches)
                 else:
-                    expanded.append(dep)
+                    expanded.append(
+                        _resolve_dependency(dep, feature_path, all_feature_paths)
+                    )
             intent.depends_on = expanded
 
     if errors:
@@ -283,6 +428,49 @@ def load_project(intent_dir: Path) -> Project:
     )
 
 
+def find_dangling_validations(project: Project) -> list[ParseError]:
+    """Report validation files whose checks would be silently ignored.
+
+    That is every ``.icv`` file in a directory without an intent (load_project
+    skips those), and every validation file whose ``target`` names neither a
+    feature, a ``feature:subtarget`` nor ``project``.
+    """
+    problems: list[ParseError] = []
+    if project.intent_dir is not None:
+        intent_dir = Path(project.intent_dir)
+        for icv_file in sorted(intent_dir.rglob("*.icv")):
+            rel = icv_file.relative_to(intent_dir)
+            if len(rel.parts) < 2 or rel.parts[0] in _SPECIAL_DIRS:
+                continue
+            if rel.parent.as_posix() not in project.features:
+                problems.append(
+                    ParseError(
+                        icv_file,
+                        f"no intent (.ic) in '{rel.parent.as_posix()}'; "
+                        f"these validations never run",
+                    )
+                )
+
+    validation_files = [
+        *project.assertions,
+        *(vf for fp in sorted(project.features) for vf in project.features[fp].validations),
+    ]
+    for vf in validation_files:
+        if not vf.target or vf.target == "project":
+            continue
+        try:
+            project.split_target(vf.target)
+        except TargetNotFoundError as exc:
+            problems.append(
+                ParseError(
+                    vf.source_path or Path("<unknown>"),
+                    f"validation target does not exist. {exc}",
+                    field="target",
+                )
+            )
+    return problems
+
+
 def write_project(project: Project, dest_dir: Path) -> Path:
     """Write a project to a new directory. Returns the dest_dir path."""
     dest_dir = Path(dest_dir)
@@ -315,7 +503,10 @@ def write_project(project: Project, dest_dir: Path) -> Path:
                 ic_path = feature_dir / f"{intent.name}.ic"
             write_intent_file(intent, ic_path)
 
+        inline = [i.inline_validations for i in node.intents]
         for vf in node.validations:
+            if any(vf is v for v in inline):
+                continue  # Written as part of its .ic body
             if vf.source_path:
                 icv_path = feature_dir / vf.source_path.name
             else:
@@ -0,0 +1,67 @@
+"""Tests for intent linting."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+from intentc.core.lint import DEFAULT_TECH_TERMS, lint_intent_text, lint_project
+from intentc.core.models import Severity
+from intentc.core.project import load_project
+
+
+def _write_file(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content)
+
+
+class TestLintIntentText:
+    def test_reports_terms_with_line_numbers(self):
+        text = (
+            "---\n"
+            "name: api\n"
+            "tags: [React]\n"
+            "---\n"
+            "Serve the catalog over HTTP.\n"
+            "Use Express.js with a PostgreSQL database.\n"
+        )
+        findings = lint_intent_text(text, Path("api.ic"), DEFAULT_TECH_TERMS)
+
+        assert [(f.line, f.term) for f in findings] == [
+            (6, "Express.js"),
+            (6, "PostgreSQL"),
+        ]
+        assert findings[0].severity == Severity.WARNING
+        assert str(findings[0]).startswith("api.ic:6: warning: mentions 'Express.js'")
+
+    def test_whole_words_and_case_sensitive(self):
+        text = "Users react to the expressive UI.\nReactive streams.\n"
+        assert lint_intent_text(text, Path("x.ic"), ["React", "Express"]) == []
+
+    def test_allow_marker_suppresses_line(self):
+        text = "Must interoperate with Kafka. // intentc:allow\nAlso Redis.\n"
+        findings = lint_intent_text(text, Path("x.ic"), DEFAULT_TECH_TERMS)
+        assert [f.term for f in findings] == ["Redis"]
+
+    def test_symbols_in_terms(self):
+        findings = lint_intent_text("Written in C++ or C#.\n", Path("x.ic"), ["C++", "C#"])
+        assert [f.term for f in findings] == ["C++", "C#"]
+
+
+class TestLintProject:
+    def test_skips_implementations(self, tmp_path: Path):
+        intent_dir = tmp_path / "intent"
+        _write_file(intent_dir / "project.ic", "---\nname: p\n---\nA shop.\n")
+        _write_file(
+            intent_dir / "implementations" / "default.ic",
+            "---\nname: default\n---\nPython with Django.\n",
+        )
+        _write_file(
+            intent_dir / "web" / "web.ic",
+            "---\nname: web\n---\nA React storefront.\n",
+        )
+        project = load_project(intent_dir)
+
+        findings = lint_project(project, severity=Severity.ERROR)
+
+        assert [(f.path.name, f.term) for f in findings] == [("web.ic", "React")]
+        assert findings[0].severity == Severity.ERROR
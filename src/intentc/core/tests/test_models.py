@@ -70,6 +70,11 @@ def test_parse_error_str_with_field():
     assert str(e) == "foo.ic [name]: missing"
 
 
+def test_parse_error_str_with_line():
+    e = ParseError(Path("foo.icv"), "bad", field="min_files", line=4)
+    assert str(e) == "foo.icv:4 [min_files]: bad"
+
+
 def test_parse_errors_message():
     errors = [
         ParseError(Path("a.ic"), "err1"),
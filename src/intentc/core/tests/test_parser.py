@@ -15,9 +15,13 @@ from intentc.core.models import (
 )
 from intentc.core.parser import (
     extract_file_references,
+    extract_sections,
+    extract_target_sections,
+    extract_validations_section,
     parse_intent_file,
     parse_validation_file,
     write_intent_file,
+    yaml_intent_to_markdown,
     write_validation_file,
 )
 
@@ -43,6 +47,190 @@ def test_extract_file_references_empty():
     assert extract_file_references("No references here") == []
 
 
+# --- extract_target_sections ---
+
+_API_GATEWAY_BODY = (
+    "# API Gateway\n"
+    "\n"
+    "Routes external traffic to internal services.\n"
+    "\n"
+    "## Target: rest-api\n"
+    "\n"
+    "Expose REST endpoints under /v1.\n"
+    "\n"
+    "### Endpoints\n"
+    "- GET /v1/users\n"
+    "\n"
+    "## Target: graphql-api\n"
+    "\n"
+    "Expose a GraphQL schema at /graphql.\n"
+    "\n"
+    "## Notes\n"
+    "\n"
+    "Shared auth middleware.\n"
+)
+
+
+def test_extract_target_sections():
+    sections = extract_target_sections(_API_GATEWAY_BODY)
+    assert list(sections) == ["rest-api", "graphql-api"]
+    assert sections["rest-api"] == (
+        "Expose REST endpoints under /v1.\n\n### Endpoints\n- GET /v1/users"
+    )
+    # Section ends at the next level-2 header
+    assert sections["graphql-api"] == "Expose a GraphQL schema at /graphql."
+
+
+def test_extract_target_sections_none():
+    assert extract_target_sections("# Feature\n\n## Details\nNothing here.") == {}
+
+
+def test_extract_sections():
+    sections = extract_sections(_API_GATEWAY_BODY)
+    assert list(sections) == ["Target: rest-api", "Target: graphql-api", "Notes"]
+    assert sections["Notes"] == "Shared auth middleware."
+    # Level-3 headers stay inside their section
+    assert "### Endpoints" in sections["Target: rest-api"]
+
+
+def test_extract_sections_joins_repeated_titles():
+    body = "## Constraints\nOne.\n\n## Notes\nN.\n\n## Constraints\nTwo.\n"
+    assert extract_sections(body)["Constraints"] == "One.\n\nTwo."
+
+
+def test_parse_intent_file_sections(tmp_path: Path):
+    ic = tmp_path / "api.ic"
+    ic.write_text(
+        "---\nname: api\n---\n\nServe the API.\n\n"
+        "## Non-Goals\n\nNo GraphQL.\n\n## Validations\n\n- name: builds\n"
+    )
+    result = parse_intent_file(ic)
+    assert list(result.sections) == ["Non-Goals", "Validations"]
+    assert result.custom_sections == {"Non-Goals": "No GraphQL."}
+
+
+def test_parse_intent_file_targets(tmp_path: Path):
+    ic = tmp_path / "api_gateway.ic"
+    ic.write_text("---\nname: api_gateway\n---\n\n" + _API_GATEWAY_BODY)
+    result = parse_intent_file(ic)
+    assert isinstance(result, IntentFile)
+    assert sorted(result.targets) == ["graphql-api", "rest-api"]
+
+
+def test_extract_validations_section():
+    body = (
+        "# Feature\n\n## Validations\n\n```yaml\n- name: builds\n```\n\n## Notes\nMore."
+    )
+    assert extract_validations_section(body) == "- name: builds"
+    assert extract_validations_section("## Validations\nvalidations: []\n") == "validations: []"
+    assert extract_validations_section("# Feature\nNo section.") is None
+
+
+def test_parse_intent_file_inline_validations(tmp_path: Path):
+    ic = tmp_path / "api.ic"
+    ic.write_text(
+        "---\nname: api\n---\n\nServe the API.\n\n## Validations\n\n"
+        "```yaml\n"
+        "- name: has-routes\n"
+        "  type: folder_check\n"
+        "  folder: routes\n"
+        "- name: responds\n"
+        "  severity: warning\n"
+        "  depends_on: has-routes\n"
+        "```\n"
+    )
+    result = parse_intent_file(ic)
+    assert isinstance(result, IntentFile)
+    vf = result.inline_validations
+    assert vf is not None
+    assert vf.source_path == ic
+    assert [v.name for v in vf.validations] == ["has-routes", "responds"]
+    assert vf.validations[0].type == ValidationType.FOLDER_CHECK
+    assert vf.validations[0].args == {"folder": "routes"}
+    assert vf.validations[1].severity == Severity.WARNING
+
+
+def test_parse_intent_file_inline_validations_errors(tmp_path: Path):
+    ic = tmp_path / "api.ic"
+    ic.write_text(
+        "---\nname: api\n---\n## Validations\n- name: a\n  depends_on: missing\n"
+    )
+    with pytest.raises(ParseErrors, match="unknown validation 'missing'"):
+        parse_intent_file(ic)
+
+
+def test_parse_intent_file_without_validations_section(tmp_path: Path):
+    ic = tmp_path / "api.ic"
+    ic.write_text("---\nname: api\n---\nNo validations here.\n")
+    assert parse_intent_file(ic).inline_validations is None
+
+
+_YAML_INTENT = (
+    "name: api_gateway\n"
+    "depends_on: [core]\n"
+    "tags: [web]\n"
+    "owner: platform\n"
+    "description: |\n"
+    "  Route traffic to services.\n"
+    "targets:\n"
+    "  rest-api: Expose REST endpoints under /v1.\n"
+    "  graphql-api: Expose a GraphQL schema at /graphql.\n"
+    "validations:\n"
+    "  - name: has-routes\n"
+    "    type: folder_check\n"
+    "    folder: routes\n"
+)
+
+
+def test_parse_yaml_intent_matches_markdown(tmp_path: Path):
+    icy = tmp_path / "api_gateway.icy"
+    icy.write_text(_YAML_INTENT)
+    ic = tmp_path / "api_gateway.ic"
+    ic.write_text(yaml_intent_to_markdown(_YAML_INTENT, icy))
+
+    from_yaml = parse_intent_file(icy)
+    from_markdown = parse_intent_file(ic)
+
+    assert isinstance(from_yaml, IntentFile)
+    assert from_yaml.name == "api_gateway"
+    assert from_yaml.depends_on == ["core"]
+    assert from_yaml.metadata == {"owner": "platform"}
+    assert from_yaml.body.startswith("Route traffic to services.")
+    assert from_yaml.targets == {
+        "rest-api": "Expose REST endpoints under /v1.",
+        "graphql-api": "Expose a GraphQL schema at /graphql.",
+    }
+    assert [v.name for v in from_yaml.inline_validations.validations] == ["has-routes"]
+    assert from_yaml.model_dump(exclude={"source_path", "inline_validations"}) == (
+        from_markdown.model_dump(exclude={"source_path", "inline_validations"})
+    )
+
+
+def test_parse_yaml_intent_errors(tmp_path: Path):
+    icy = tmp_path / "bad.icy"
+    icy.write_text("- just\n- a list\n")
+    with pytest.raises(ParseErrors, match="expected a YAML mapping"):
+        parse_intent_file(icy)
+
+    icy.write_text("description: no name\n")
+    with pytest.raises(ParseErrors, match="missing required field"):
+        parse_intent_file(icy)
+
+
+def test_round_trip_yaml_intent(tmp_path: Path):
+    icy = tmp_path / "api_gateway.icy"
+    icy.write_text(_YAML_INTENT)
+    original = parse_intent_file(icy)
+
+    out = write_intent_file(original, tmp_path / "copy" / "api_gateway.icy")
+    reloaded = parse_intent_file(out)
+
+    assert "description:" in out.read_text()
+    assert reloaded.body == original.body
+    assert reloaded.targets == original.targets
+    assert reloaded.metadata == original.metadata
+
+
 # --- parse_intent_file ---
 
 def test_parse_intent_file_basic(tmp_path: Path):
@@ -71,6 +259,111 @@ def test_parse_intent_file_basic(tmp_path: Path):
     assert result.source_path == ic
 
 
+def test_parse_intent_file_metadata(tmp_path: Path):
+    ic = tmp_path / "feature.ic"
+    ic.write_text(
+        "---\n"
+        "name: checkout\n"
+        "tags: [frontend]\n"
+        "owner: payments-team\n"
+        "ticket: PAY-123\n"
+        "---\n"
+        "\n"
+        "# Checkout\n"
+        "\n"
+        "## Target: cart\n"
+        "Cart page.\n"
+    )
+    result = parse_intent_file(ic)
+    assert result.metadata == {"owner": "payments-team", "ticket": "PAY-123"}
+    assert result.tags == ["frontend"]
+    assert result.body.startswith("# Checkout")
+    assert result.targets == {"cart": "Cart page."}
+
+
+def test_parse_intent_file_context(tmp_path: Path):
+    ic = tmp_path / "feature.ic"
+    ic.write_text(
+        "---\n"
+        "name: api\n"
+        "context:\n"
+        "  - openapi.yaml\n"
+        "  - ../docs/style.md\n"
+        "---\n"
+        "Body\n"
+    )
+    result = parse_intent_file(ic)
+    assert result.context == ["openapi.yaml", "../docs/style.md"]
+    assert "context" not in result.metadata
+
+    path = write_intent_file(result, tmp_path / "rt.ic")
+    assert parse_intent_file(path).context == result.context
+
+
+def test_parse_intent_file_requires_capabilities(tmp_path: Path):
+    ic = tmp_path / "scraper.ic"
+    ic.write_text("---\nname: scraper\nrequires_capabilities: [web]\n---\nBody\n")
+    result = parse_intent_file(ic)
+    assert result.requires_capabilities == ["web"]
+    assert "requires_capabilities" not in result.metadata
+
+    path = write_intent_file(result, tmp_path / "rt.ic")
+    assert parse_intent_file(path).requires_capabilities == ["web"]
+
+
+def test_parse_intent_file_manual(tmp_path: Path):
+    ic = tmp_path / "e2e.ic"
+    ic.write_text("---\nname: e2e\nmanual: true\n---\nBody\n")
+    result = parse_intent_file(ic)
+    assert result.manual is True
+    assert "manual" not in result.metadata
+
+    path = write_intent_file(result, tmp_path / "rt.ic")
+    assert parse_intent_file(path).manual is True
+
+
+def test_parse_intent_file_agent(tmp_path: Path):
+    ic = tmp_path / "engine.ic"
+    ic.write_text("---\nname: engine\nagent: heavy\n---\nBody\n")
+    result = parse_intent_file(ic)
+    assert result.agent == "heavy"
+    assert "agent" not in result.metadata
+
+    path = write_intent_file(result, tmp_path / "rt.ic")
+    assert parse_intent_file(path).agent == "heavy"
+
+
+@pytest.mark.parametrize(
+    "frontmatter,section,expected",
+    [
+        ("depends_on: [core, auth]\n", "", ["core", "auth"]),
+        ("", "## Dependencies\n\n- core\n- `auth` for session tokens\n", ["core", "auth"]),
+        (
+            "depends_on: [core, auth]\n",
+            "## Dependencies\n\n- auth: issues tokens\n* billing\n\n## Notes\n\n- not-a-dep\n",
+            ["core", "auth", "billing"],
+        ),
+        (
+            "",
+            "## Dependencies\n\nauth,\n  database\n- billing, `audit`\n- search: full text\n",
+            ["auth", "database", "billing", "audit", "search"],
+        ),
+        ("", "## Dependencies\n\nauth, database\n", ["auth", "database"]),
+        ("", "## Dependencies\n\nNone.\n\nTBD\n", []),
+    ],
+    ids=["frontmatter-only", "section-only", "both", "wrapped-inline", "one-line", "prose"],
+)
+def test_parse_intent_file_dependencies(tmp_path: Path, frontmatter, section, expected):
+    ic = tmp_path / "api.ic"
+    ic.write_text(f"---\nname: api\n{frontmatter}---\nThe API.\n\n{section}")
+    result = parse_intent_file(ic)
+    assert result.depends_on == expected
+    assert "Dependencies" not in result.custom_sections
+
+    path = write_intent_file(result, tmp_path / "rt.ic")
+    assert parse_intent_file(path).depends_on == expected
+
+
 def test_parse_intent_file_missing_name(tmp_path: Path):
     ic = tmp_path / "bad.ic"
     ic.write_text("---\ntags: [x]\n---\nBody\n")
@@ -156,6 +449,220 @@ def test_parse_validation_file_with_agent_profile(tmp_path: Path):
     assert result.agent_profile == "gpt4"
 
 
+def test_parse_validation_file_depends_on(tmp_path: Path):
+    icv = tmp_path / "deps.icv"
+    icv.write_text(
+        "target: feat\n"
+        "validations:\n"
+        "  - name: binary-exists\n"
+        "  - name: endpoint\n"
+        "    depends_on: binary-exists\n"
+    )
+    result = parse_validation_file(icv)
+    assert result.validations[0].depends_on is None
+    assert result.validations[1].depends_on == "binary-exists"
+
+
+def test_parse_validation_file_unknown_depends_on(tmp_path: Path):
+    icv = tmp_path / "deps.icv"
+    icv.write_text(
+        "target: feat\n"
+        "validations:\n"
+        "  - name: endpoint\n"
+        "    depends_on: missing\n"
+    )
+    with pytest.raises(ParseErrors) as exc_info:
+        parse_validation_file(icv)
+    assert "unknown validation 'missing'" in str(exc_info.value)
+
+
+def test_parse_validation_file_min_confidence(tmp_path: Path):
+    icv = tmp_path / "conf.icv"
+    icv.write_text(
+        "target: feat\n"
+        "validations:\n"
+        "  - name: review\n"
+        "    args:\n"
+        "      min_confidence: 0.75\n"
+    )
+    result = parse_validation_file(icv)
+    assert result.validations[0].args["min_confidence"] == 0.75
+
+
+@pytest.mark.parametrize("value", ["1.5", "high", "-0.1"])
+def test_parse_validation_file_invalid_min_confidence(tmp_path: Path, value: str):
+    icv = tmp_path / "conf.icv"
+    icv.write_text(
+        "target: feat\n"
+        "validations:\n"
+        "  - name: review\n"
+        "    args:\n"
+        f"      min_confidence: {value}\n"
+    )
+    with pytest.raises(ParseErrors) as exc_info:
+        parse_validation_file(icv)
+    assert "min_confidence must be a number between 0 and 1" in str(exc_info.value)
+
+
+def test_parse_validation_file_messy_but_valid(tmp_path: Path):
+    icv = tmp_path / "messy.icv"
+    icv.write_text(
+        "# Validations for the api feature\n"
+        "target: api\n"
+        "\n"
+        "validations:\n"
+        "  # mapping form\n"
+        "  - name: mapping\n"
+        "    args:\n"
+        "      file: main.py\n"
+        "\n"
+        "      exists: true\n"
+        "\n"
+        "  # bullet form\n"
+        "  - name: bullets\n"
+        "    args:\n"
+        "      - file: main.py\n"
+        "      # stray comment between parameters\n"
+        "\n"
+        "      - exists: true\n"
+        "  - name: inline\n"
+        "    file: main.py\n"
+        "    exists: true\n"
+    )
+    result = parse_validation_file(icv)
+    assert [v.name for v in result.validations] == ["mapping", "bullets", "inline"]
+    for v in result.validations:
+        assert v.args == {"file": "main.py", "exists": True}
+
+
+def test_parse_validation_file_explicit_args_win(tmp_path: Path):
+    icv = tmp_path / "mixed.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: check\n"
+        "    file: inline.py\n"
+        "    args:\n"
+        "      file: explicit.py\n"
+    )
+    result = parse_validation_file(icv)
+    assert result.validations[0].args == {"file": "explicit.py"}
+
+
+def test_parse_validation_file_warns_on_unknown_keys(tmp_path: Path):
+    icv = tmp_path / "typo.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: check\n"
+        "    type: file_check\n"
+        "    file: main.py\n"
+        "    severty: warning\n"
+        "    timeout: 30s\n"
+        "  - name: custom\n"
+        "    type: my_runner\n"
+        "    anything: goes\n"
+    )
+    result = parse_validation_file(icv)
+    assert result.validations[0].severity == Severity.ERROR
+    assert result.warnings == [
+        f"{icv}:5: validation 'check' has unknown key 'severty' (did you mean 'severity'?)"
+    ]
+
+
+def test_parse_validation_file_coerces_folder_check_args(tmp_path: Path):
+    icv = tmp_path / "folders.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: src-layout\n"
+        "    type: folder_check\n"
+        "    folder: src\n"
+        "    min_files: \"2\"\n"
+        "    recursive: yes\n"
+        "    contains_dirs: models\n"
+    )
+    args = parse_validation_file(icv).validations[0].args
+    assert args == {
+        "folder": "src",
+        "min_files": 2,
+        "recursive": True,
+        "contains_dirs": ["models"],
+    }
+
+
+def test_parse_validation_file_web_check_args(tmp_path: Path):
+    icv = tmp_path / "web.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: health\n"
+        "    type: web_check\n"
+        "    url: http://localhost:8080/health\n"
+        "    status_code: \"200\"\n"
+        "  - name: no-url\n"
+        "    type: web_check\n"
+    )
+    with pytest.raises(ParseErrors) as exc_info:
+        parse_validation_file(icv)
+    assert "requires arg 'url'" in str(exc_info.value)
+
+    icv.write_text("\n".join(icv.read_text().splitlines()[:5]) + "\n")
+    validation = parse_validation_file(icv).validations[0]
+    assert validation.type == ValidationType.WEB_CHECK
+    assert validation.args == {"url": "http://localhost:8080/health", "status_code": 200}
+
+
+def test_parse_validation_file_coerces_file_check_args(tmp_path: Path):
+    icv = tmp_path / "files.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: entrypoint\n"
+        "    type: file_check\n"
+        "    file: src/main.py\n"
+        "    contains: def main\n"
+    )
+    validation = parse_validation_file(icv).validations[0]
+    assert validation.type == ValidationType.FILE_CHECK
+    assert validation.args == {"file": "src/main.py", "contains": ["def main"]}
+
+
+def test_parse_validation_file_arg_type_errors_have_lines(tmp_path: Path):
+    icv = tmp_path / "bad-types.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: layout\n"
+        "    type: folder_check\n"
+        "    folder: src\n"
+        "    args:\n"
+        "      min_files: abc\n"
+        "      exists: maybe\n"
+        "  - name: missing-folder\n"
+        "    type: folder_check\n"
+    )
+    with pytest.raises(ParseErrors) as exc_info:
+        parse_validation_file(icv)
+    errors = {e.field: e for e in exc_info.value.errors}
+    assert errors["min_files"].line == 6
+    assert "arg 'min_files' must be an integer, got 'abc'" in errors["min_files"].message
+    assert errors["exists"].line == 7
+    assert "must be true or false" in errors["exists"].message
+    assert errors["folder"].line == 8
+    assert "requires arg 'folder'" in errors["folder"].message
+    assert f"{icv}:6 [min_files]" in str(exc_info.value)
+
+
+def test_parse_validation_file_invalid_args(tmp_path: Path):
+    icv = tmp_path / "bad.icv"
+    icv.write_text(
+        "validations:\n"
+        "  - name: check\n"
+        "    args: main.py\n"
+        "  - just a string\n"
+    )
+    with pytest.raises(ParseErrors) as exc_info:
+        parse_validation_file(icv)
+    message = str(exc_info.value)
+    assert "args must be a mapping or a list of mappings" in message
+    assert "expected a mapping for validation entry" in message
+
+
 # --- write_intent_file ---
 
 def test_write_intent_file(tmp_path: Path):
@@ -229,6 +736,17 @@ def test_round_trip_intent_file(tmp_path: Path):
     assert "./ref.png" in loaded.file_references
 
 
+def test_round_trip_intent_metadata(tmp_path: Path):
+    original = IntentFile(
+        name="round-trip",
+        metadata={"owner": "payments-team", "priority": 2},
+        body="Body",
+    )
+    path = write_intent_file(original, tmp_path / "rt.ic")
+    loaded = parse_intent_file(path)
+    assert loaded.metadata == original.metadata
+
+
 def test_round_trip_project_intent(tmp_path: Path):
     original = ProjectIntent(name="proj", body="Project desc")
     path = write_intent_file(original, tmp_path / "project.ic")
@@ -7,16 +7,19 @@ from pathlib import Path
 import pytest
 
 from intentc.core.models import (
+    DependencyCycleError,
     Implementation,
     IntentFile,
     ParseErrors,
     ProjectIntent,
+    TargetNotFoundError,
     ValidationFile,
 )
 from intentc.core.project import (
     FeatureNode,
     Project,
     blank_project,
+    find_dangling_validations,
     load_project,
     write_project,
 )
@@ -139,6 +142,14 @@ class TestDAGTraversal:
         with pytest.raises(KeyError, match="not found"):
             proj.parents("nope")
 
+    def test_missing_target_error_is_typed(self):
+        proj = _dag_project()
+        with pytest.raises(TargetNotFoundError) as exc_info:
+            proj.split_target("d:nope")
+        assert exc_info.value.target == "d:nope"
+        # Unlike a plain KeyError, the message is not quoted
+        assert str(exc_info.value).startswith("Sub-target 'nope' not found")
+
     def test_parents(self):
         proj = _dag_project()
         assert proj.parents("d") == ["b", "c"]
@@ -179,8 +190,100 @@ class TestDAGTraversal:
                 ),
             },
         )
-        with pytest.raises(ValueError, match="cycle"):
+        with pytest.raises(ValueError, match="cycle") as exc_info:
             proj.topological_order()
+        assert str(exc_info.value) == "Dependency cycle detected: x -> y -> x"
+        assert isinstance(exc_info.value, DependencyCycleError)
+        assert exc_info.value.cycle == ["x", "y", "x"]
+
+    def test_find_cycle_reports_path(self):
+        proj = Project(
+            project_intent=ProjectIntent(name="cyc"),
+            features={
+                "a": FeatureNode(path="a", intents=[IntentFile(name="a")]),
+                "b": FeatureNode(
+                    path="b", intents=[IntentFile(name="b", depends_on=["a", "d"])]
+                ),
+                "c": FeatureNode(
+                    path="c", intents=[IntentFile(name="c", depends_on=["b"])]
+                ),
+                "d": FeatureNode(
+                    path="d", intents=[IntentFile(name="d", depends_on=["c"])]
+                ),
+            },
+        )
+        assert proj.find_cycle() == ["b", "d", "c", "b"]
+
+    def test_split_target(self):
+        proj = Project(
+            project_intent=ProjectIntent(name="gw"),
+            features={
+                "gateway": FeatureNode(
+                    path="gateway",
+                    intents=[IntentFile(name="gateway", targets={"rest-api": "REST"})],
+                ),
+            },
+        )
+        assert proj.split_target("gateway") == ("gateway", None)
+        assert proj.split_target("gateway:rest-api") == ("gateway", "rest-api")
+        with pytest.raises(KeyError, match="Sub-target"):
+            proj.split_target("gateway:soap-api")
+        with pytest.raises(KeyError):
+            proj.split_target("missing:rest-api")
+
+    def test_missing_feature_suggests_close_match(self):
+        proj = Project(
+            project_intent=ProjectIntent(name="p"),
+            features={
+                "core/auth": FeatureNode(path="core/auth", intents=[IntentFile(name="auth")]),
+                "core/api": FeatureNode(path="core/api", intents=[IntentFile(name="api")]),
+            },
+        )
+        assert proj.suggest_features("core/auht")[0] == "core/auth"
+        with pytest.raises(KeyError, match="Did you mean: core/auth"):
+            proj.split_target("core/auht")
+
+    def test_missing_feature_without_close_match(self):
+        with pytest.raises(KeyError) as exc_info:
+            _dag_project().split_target("zzzzzz")
+        assert "Did you mean" not in str(exc_info.value)
+
+    def test_missing_subtarget_suggests_close_match(self):
+        proj = Project(
+            project_intent=ProjectIntent(name="gw"),
+            features={
+                "gateway": FeatureNode(
+                    path="gateway",
+                    intents=[IntentFile(name="gateway", targets={"rest-api": "REST"})],
+                ),
+            },
+        )
+        with pytest.raises(KeyError, match=r"Did you mean: rest-api\?"):
+            proj.split_target("gateway:rest-apj")
+
+    def test_subtarget_intent_keeps_custom_sections(self):
+        node = FeatureNode(
+            path="gateway",
+            intents=[
+                IntentFile(
+                    name="gateway",
+                    targets={"rest-api": "REST", "graphql-api": "GraphQL"},
+                    sections={
+                        "Target: rest-api": "REST",
+                        "Constraints": "No new dependencies.",
+                        "Target: graphql-api": "GraphQL",
+                        "Validations": "- name: builds",
+                    },
+                )
+            ],
+        )
+
+        intent = node.subtarget_intent("rest-api")
+
+        assert intent.body == "REST\n\n## Constraints\n\nNo new dependencies."
+
+    def test_find_cycle_acyclic(self):
+        assert _dag_project().find_cycle() == []
 
 
 # ---------------------------------------------------------------------------
@@ -247,6 +350,45 @@ class TestLoadProject:
         assert len(node.intents) == 1
         assert len(node.validations) == 1
 
+    def test_inline_and_file_validations_combine(self, tmp_path: Path):
+        intent_dir = tmp_path / "intent"
+        _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
+        _write_file(
+            intent_dir / "api" / "api.ic",
+            "---\nname: api\n---\nAPI.\n\n## Validations\n\n- name: inline-check\n",
+        )
+        _write_file(
+            intent_dir / "api" / "api.icv",
+            "validations:\n  - name: file-check\n",
+        )
+        proj = load_project(intent_dir)
+        names = [v.name for vf in proj.features["api"].validations for v in vf.validations]
+        assert sorted(names) == ["file-check", "inline-check"]
+
+        # Writing back keeps the inline section in the .ic and does not
+        # turn it into a separate .icv file
+        dest = tmp_path / "copy"
+        write_project(proj, dest)
+        assert sorted(p.name for p in (dest / "api").iterdir()) == ["api.ic", "api.icv"]
+        reloaded = load_project(dest)
+        assert len(reloaded.features["api"].validations) == 2
+
+    def test_loads_yaml_intents(self, tmp_path: Path):
+        intent_dir = tmp_path / "intent"
+        _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
+        _write_file(intent_dir / "core" / "core.ic", "---\nname: core\n---\nCore.")
+        _write_file(
+            intent_dir / "api" / "api.icy",
+            "name: api\ndepends_on: [core]\ndescription: Serve the API.\n"
+            "validations:\n  - name: responds\n",
+        )
+        proj = load_project(intent_dir)
+
+        assert proj.features["api"].depends_on == ["core"]
+        assert proj.features["api"].intents[0].body.startswith("Serve the API.")
+        assert len(proj.features["api"].validations) == 1
+        assert proj.topological_order() == ["core", "api"]
+
     def test_wildcard_expansion(self, tmp_path: Path):
         intent_dir = tmp_path / "intent"
         _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
@@ -276,6 +418,35 @@ class TestLoadProject:
         with pytest.raises(ParseErrors, match="matched no features"):
             load_project(intent_dir)
 
+    def test_relative_dependencies(self, tmp_path: Path):
+        intent_dir = tmp_path / "intent"
+        _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
+        for path in ("core/db", "payments/ledger", "payments/api/rest"):
+            _write_file(intent_dir / path / "f.ic", f"---\nname: {path}\n---\n")
+        _write_file(
+            intent_dir / "payments" / "api" / "api.ic",
+            "---\nname: api\ndepends_on:\n"
+            "  - ledger\n  - ./rest\n  - ../../core/db\n  - core/db\n---\n",
+        )
+        proj = load_project(intent_dir)
+        assert proj.features["payments/api"].depends_on == [
+            "payments/ledger",
+            "payments/api/rest",
+            "core/db",
+        ]
+
+    def test_exact_path_wins_over_relative(self, tmp_path: Path):
+        intent_dir = tmp_path / "intent"
+        _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
+        _write_file(intent_dir / "auth" / "a.ic", "---\nname: auth\n---\n")
+        _write_file(intent_dir / "web" / "auth" / "a.ic", "---\nname: web-auth\n---\n")
+        _write_file(
+            intent_dir / "web" / "ui" / "ui.ic",
+            "---\nname: ui\ndepends_on:\n  - auth\n---\n",
+        )
+        proj = load_project(intent_dir)
+        assert proj.features["web/ui"].depends_on == ["auth"]
+
     def test_accumulates_errors(self, tmp_path: Path):
         intent_dir = tmp_path / "intent"
         _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
@@ -297,6 +468,43 @@ class TestLoadProject:
         assert "a/b/c" in proj.features
 
 
+class TestFindDanglingValidations:
+    def _project(self, tmp_path: Path) -> Path:
+        intent_dir = tmp_path / "intent"
+        _write_file(intent_dir / "project.ic", "---\nname: p\n---\n")
+        _write_file(intent_dir / "api" / "api.ic", "---\nname: api\n---\n")
+        _write_file(intent_dir / "api" / "ok.icv", "target: api\n")
+        _write_file(intent_dir / "assertions" / "e2e.icv", "target: project\n")
+        return intent_dir
+
+    def test_clean_project_has_none(self, tmp_path: Path):
+        proj = load_project(self._project(tmp_path))
+        assert find_dangling_validations(proj) == []
+
+    def test_icv_without_intent(self, tmp_path: Path):
+        intent_dir = self._project(tmp_path)
+        _write_file(intent_dir / "old" / "checks.icv", "target: old\n")
+
+        problems = find_dangling_validations(load_project(intent_dir))
+
+        assert [p.path for p in problems] == [intent_dir / "old" / "checks.icv"]
+        assert "no intent (.ic) in 'old'" in problems[0].message
+
+    def test_unknown_target_reference(self, tmp_path: Path):
+        intent_dir = self._project(tmp_path)
+        _write_file(intent_dir / "api" / "stale.icv", "target: apii\n")
+        _write_file(intent_dir / "api" / "sub.icv", "target: api:cli\n")
+
+        problems = find_dangling_validations(load_project(intent_dir))
+
+        assert [(p.path.name, p.field) for p in problems] == [
+            ("stale.icv", "target"),
+            ("sub.icv", "target"),
+        ]
+        assert "Did you mean: api?" in problems[0].message
+        assert "Sub-target 'cli' not found" in problems[1].message
+
+
 # ---------------------------------------------------------------------------
 # write_project
 # ---------------------------------------------------------------------------